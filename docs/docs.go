@@ -0,0 +1,104 @@
+// Package docs embeds the settings reference so the TUI can show it without
+// a network connection or a checkout of the repository.
+package docs
+
+import (
+	_ "embed"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+//go:embed settings.md
+var settingsMD string
+
+// Section is one part of settings.md: a category (a ## heading) or a setting
+// (a ### heading below it). Body is the markdown between the heading and the
+// next one; a category's Body stops at its first setting.
+type Section struct {
+	Title string
+	Badge string // the category badge, e.g. "MOD"
+	Key   string // the settings.json key of a setting, from its **JSON key:** line
+	Body  string
+
+	category bool
+}
+
+var (
+	categoryHeading = regexp.MustCompile("^## (.+?)\\s+`([A-Z]+)`$")
+	jsonKeyLine     = regexp.MustCompile("^\\*\\*JSON key:\\*\\* `([^`]+)`")
+)
+
+var sections = sync.OnceValue(func() []Section {
+	return parse(settingsMD)
+})
+
+func parse(md string) []Section {
+	var out []Section
+	var cur *Section
+	var body []string
+	flush := func() {
+		if cur != nil {
+			cur.Body = strings.TrimSpace(strings.Join(body, "\n"))
+			out = append(out, *cur)
+		}
+		cur, body = nil, nil
+	}
+	badge := ""
+	fenced := false
+	for _, line := range strings.Split(md, "\n") {
+		if strings.HasPrefix(line, "```") {
+			fenced = !fenced
+		}
+		switch {
+		case fenced || strings.HasPrefix(line, "```"):
+		case strings.HasPrefix(line, "## "):
+			flush()
+			badge = ""
+			if m := categoryHeading.FindStringSubmatch(line); m != nil {
+				badge = m[2]
+				cur = &Section{Title: m[1], Badge: badge, category: true}
+			}
+			continue
+		case strings.HasPrefix(line, "### "):
+			flush()
+			if badge != "" {
+				cur = &Section{Title: strings.TrimPrefix(line, "### "), Badge: badge}
+			}
+			continue
+		case line == "---":
+			flush()
+			continue
+		}
+		if cur == nil {
+			continue
+		}
+		if m := jsonKeyLine.FindStringSubmatch(line); m != nil && cur.Key == "" {
+			cur.Key = m[1]
+		}
+		body = append(body, line)
+	}
+	flush()
+	return out
+}
+
+// Setting returns the section documenting a settings.json key.
+func Setting(key string) (Section, bool) {
+	for _, s := range sections() {
+		if s.Key == key {
+			return s, true
+		}
+	}
+	return Section{}, false
+}
+
+// Category returns the introduction of the category with that badge. It is
+// empty for categories that go straight to their settings.
+func Category(badge string) (Section, bool) {
+	for _, s := range sections() {
+		if s.category && s.Badge == badge {
+			return s, true
+		}
+	}
+	return Section{}, false
+}
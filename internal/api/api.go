@@ -1,7 +1,9 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -14,122 +16,143 @@ import (
 var client = &http.Client{Timeout: 15 * time.Second}
 
 // ModelInfo is a model returned from a provider's /models endpoint.
+// ContextLength, OwnedBy and Created are zero when the provider omits them.
 type ModelInfo struct {
-	ID   string
-	Name string
+	ID            string
+	Name          string
+	ContextLength int
+	OwnedBy       string
+	Created       time.Time
 }
 
+// Retry policy for transient fetch failures.
+const (
+	fetchAttempts = 3
+	fetchBackoff  = 500 * time.Millisecond
+)
+
+// maxPages caps how many pages of a paginated listing are followed.
+const maxPages = 20
+
 // FetchModels calls the provider's models endpoint and returns available models.
-func FetchModels(baseURL, apiKey, modelsEndpoint, providerType string, noAuth bool) ([]ModelInfo, error) {
+// format selects the response parser and auth headers: a provider type
+// ("generic-chat-completion-api", "openai", "anthropic") or a models format
+// such as "gemini". Paginated listings are followed up to maxPages.
+// Transient failures are retried with exponential backoff. Cancelling ctx aborts
+// the in-flight request and any pending retry; other failures are returned as
+// *FetchError. A listing that stops early — a later page fails, or there are
+// more than maxPages — returns the models read so far with a *PartialError.
+func FetchModels(ctx context.Context, baseURL, apiKey, modelsEndpoint, format string, noAuth bool) ([]ModelInfo, error) {
 	if modelsEndpoint == "" {
 		return nil, nil
 	}
 
-	endpoint := strings.TrimRight(baseURL, "/") + modelsEndpoint
-	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	next, err := url.Parse(strings.TrimRight(baseURL, "/") + modelsEndpoint)
 	if err != nil {
 		return nil, fmt.Errorf("build request: %w", err)
 	}
+	parse := parserFor(format)
+	seen := map[string]bool{}
+	var out []ModelInfo
+	for i := 0; i < maxPages && next != nil; i++ {
+		if seen[next.String()] {
+			break
+		}
+		seen[next.String()] = true
+		page, err := fetchPage(ctx, next, apiKey, format, noAuth, parse)
+		if err != nil {
+			if i > 0 && ctx.Err() == nil {
+				return dedupeModels(out), &PartialError{Pages: i, Err: err}
+			}
+			return nil, err
+		}
+		out = append(out, page.Models...)
+		next = page.Next
+	}
+	if next != nil && !seen[next.String()] {
+		return dedupeModels(out), &PartialError{Pages: maxPages}
+	}
+	return dedupeModels(out), nil
+}
+
+func dedupeModels(models []ModelInfo) []ModelInfo {
+	seen := make(map[string]bool, len(models))
+	out := models[:0]
+	for _, m := range models {
+		if seen[m.ID] {
+			continue
+		}
+		seen[m.ID] = true
+		out = append(out, m)
+	}
+	return out
+}
+
+// fetchPage requests one page, retrying transient failures.
+func fetchPage(ctx context.Context, u *url.URL, apiKey, format string, noAuth bool, parse ResponseParser) (Page, error) {
+	backoff := fetchBackoff
+	for attempt := 1; ; attempt++ {
+		page, err := fetchOnce(ctx, u, apiKey, format, noAuth, parse)
+		if err == nil {
+			return page, nil
+		}
+		if ctx.Err() != nil {
+			return Page{}, ctx.Err()
+		}
+		var fe *FetchError
+		if !errors.As(err, &fe) || !fe.retryable() || attempt == fetchAttempts {
+			return Page{}, err
+		}
+		select {
+		case <-ctx.Done():
+			return Page{}, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+func fetchOnce(ctx context.Context, u *url.URL, apiKey, format string, noAuth bool, parse ResponseParser) (Page, error) {
+	endpoint := u.String()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return Page{}, fmt.Errorf("build request: %w", err)
+	}
 
 	req.Header.Set("Content-Type", "application/json")
 	if !noAuth && apiKey != "" {
-		if providerType == "anthropic" {
+		switch format {
+		case "anthropic":
 			req.Header.Set("x-api-key", apiKey)
+			req.Header.Set("anthropic-version", "2023-06-01")
 			req.Header.Set("anthropic-dangerous-direct-browser-access", "true")
-		} else {
+		case "gemini":
+			req.Header.Set("x-goog-api-key", apiKey)
+		default:
 			req.Header.Set("Authorization", "Bearer "+apiKey)
 		}
 	}
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return Page{}, transportError(err, endpoint)
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("HTTP %d from %s", resp.StatusCode, endpoint)
-	}
-
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, err
+		return Page{}, transportError(err, endpoint)
 	}
 
-	return parseModelsResponse(body)
-}
-
-func parseModelsResponse(body []byte) ([]ModelInfo, error) {
-	// Try { "data": [ { "id": "..." } ] } — OpenAI format
-	var openaiResp struct {
-		Data []struct {
-			ID   string `json:"id"`
-			Name string `json:"name"`
-		} `json:"data"`
-	}
-	if err := json.Unmarshal(body, &openaiResp); err == nil && len(openaiResp.Data) > 0 {
-		out := make([]ModelInfo, 0, len(openaiResp.Data))
-		for _, m := range openaiResp.Data {
-			if m.ID == "" {
-				continue
-			}
-			name := m.Name
-			if name == "" {
-				name = m.ID
-			}
-			out = append(out, ModelInfo{ID: m.ID, Name: name})
-		}
-		return out, nil
-	}
-
-	// Try { "models": [ ... ] }
-	var modelsResp struct {
-		Models []struct {
-			ID   string `json:"id"`
-			Name string `json:"name"`
-		} `json:"models"`
-	}
-	if err := json.Unmarshal(body, &modelsResp); err == nil && len(modelsResp.Models) > 0 {
-		out := make([]ModelInfo, 0, len(modelsResp.Models))
-		for _, m := range modelsResp.Models {
-			if m.ID == "" {
-				continue
-			}
-			name := m.Name
-			if name == "" {
-				name = m.ID
-			}
-			out = append(out, ModelInfo{ID: m.ID, Name: name})
-		}
-		return out, nil
+	if resp.StatusCode >= 400 {
+		return Page{}, statusError(resp.StatusCode, endpoint, body)
 	}
 
-	// Try direct array [ "model-id" ] or [ { "id": "..." } ]
-	var rawArr []json.RawMessage
-	if err := json.Unmarshal(body, &rawArr); err == nil {
-		out := make([]ModelInfo, 0, len(rawArr))
-		for _, raw := range rawArr {
-			var s string
-			if json.Unmarshal(raw, &s) == nil {
-				out = append(out, ModelInfo{ID: s, Name: s})
-				continue
-			}
-			var obj struct {
-				ID   string `json:"id"`
-				Name string `json:"name"`
-			}
-			if json.Unmarshal(raw, &obj) == nil && obj.ID != "" {
-				name := obj.Name
-				if name == "" {
-					name = obj.ID
-				}
-				out = append(out, ModelInfo{ID: obj.ID, Name: name})
-			}
-		}
-		return out, nil
+	page, err := parse(body, u)
+	if err != nil {
+		return Page{}, &FetchError{Kind: ErrBadJSON, Status: resp.StatusCode, URL: endpoint, Snippet: snippet(body), Err: err}
 	}
-
-	return nil, fmt.Errorf("unrecognised models response format")
+	return page, nil
 }
 
 // ───────────────────────────────────────────────
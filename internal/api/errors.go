@@ -0,0 +1,179 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ErrorKind categorises why a models fetch failed.
+type ErrorKind int
+
+const (
+	ErrOther     ErrorKind = iota
+	ErrAuth                // 401 / 403 — bad or missing API key
+	ErrNotFound            // 404 — wrong base URL or no list endpoint
+	ErrRateLimit           // 429
+	ErrServer              // 5xx
+	ErrTLS                 // certificate / handshake problems
+	ErrTimeout             // request or dial timed out
+	ErrNetwork             // connection refused, DNS server unreachable, ...
+	ErrBadJSON             // body was not a models list we understand
+	ErrNoHost              // DNS has no such host — usually a mistyped base URL
+)
+
+// String returns a short label suitable for a badge.
+func (k ErrorKind) String() string {
+	switch k {
+	case ErrAuth:
+		return "auth"
+	case ErrNotFound:
+		return "not found"
+	case ErrRateLimit:
+		return "rate limited"
+	case ErrServer:
+		return "server"
+	case ErrTLS:
+		return "tls"
+	case ErrTimeout:
+		return "timeout"
+	case ErrNetwork:
+		return "network"
+	case ErrBadJSON:
+		return "bad json"
+	case ErrNoHost:
+		return "unknown host"
+	}
+	return "error"
+}
+
+// FetchError describes a failed models fetch in a way the UI can present.
+type FetchError struct {
+	Kind    ErrorKind
+	Status  int    // HTTP status, 0 if the request never completed
+	URL     string // endpoint that was called
+	Snippet string // start of the response body, whitespace collapsed
+	Err     error  // underlying transport or decode error, may be nil
+}
+
+func (e *FetchError) Error() string {
+	var sb strings.Builder
+	sb.WriteString(e.Kind.String())
+	if e.Status != 0 {
+		fmt.Fprintf(&sb, ": HTTP %d", e.Status)
+	}
+	if e.URL != "" {
+		sb.WriteString(" from " + e.URL)
+	}
+	if e.Err != nil {
+		sb.WriteString(": " + e.Err.Error())
+	}
+	return sb.String()
+}
+
+func (e *FetchError) Unwrap() error { return e.Err }
+
+// PartialError reports a paginated listing that stopped before its last page.
+// FetchModels returns it together with the models of the pages it read.
+type PartialError struct {
+	Pages int   // pages read
+	Err   error // why the next page failed; nil when maxPages was reached
+}
+
+func (e *PartialError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("models list cut off after %d pages", e.Pages)
+	}
+	return fmt.Sprintf("models list cut off after %d page(s): %v", e.Pages, e.Err)
+}
+
+func (e *PartialError) Unwrap() error { return e.Err }
+
+// Hint returns a one-line suggestion for fixing the error.
+func (e *FetchError) Hint() string {
+	switch e.Kind {
+	case ErrAuth:
+		return "Check the API key or the ${ENV_VAR} it references"
+	case ErrNotFound:
+		return "Check the base URL — the provider may not expose a models endpoint"
+	case ErrRateLimit:
+		return "The provider is rate limiting requests — wait a moment and retry"
+	case ErrServer:
+		return "The provider returned a server error — retry later"
+	case ErrTLS:
+		return "The server certificate could not be verified"
+	case ErrTimeout:
+		return "The provider did not answer in time"
+	case ErrNetwork:
+		return "Check the host name and your network connection"
+	case ErrBadJSON:
+		return "The response was not a models list wrench understands"
+	case ErrNoHost:
+		return "Check the host name in the base URL"
+	}
+	return ""
+}
+
+// retryable reports whether the error is worth another attempt.
+func (e *FetchError) retryable() bool {
+	switch e.Kind {
+	case ErrTimeout, ErrNetwork, ErrServer, ErrRateLimit:
+		return true
+	}
+	return false
+}
+
+func statusError(status int, endpoint string, body []byte) *FetchError {
+	kind := ErrOther
+	switch {
+	case status == 401 || status == 403:
+		kind = ErrAuth
+	case status == 404:
+		kind = ErrNotFound
+	case status == 429:
+		kind = ErrRateLimit
+	case status >= 500:
+		kind = ErrServer
+	}
+	return &FetchError{Kind: kind, Status: status, URL: endpoint, Snippet: snippet(body)}
+}
+
+func transportError(err error, endpoint string) *FetchError {
+	kind := ErrNetwork
+
+	var netErr net.Error
+	var dnsErr *net.DNSError
+	var unknownCA x509.UnknownAuthorityError
+	var hostErr x509.HostnameError
+	var invalidCert x509.CertificateInvalidError
+	var verifyErr *tls.CertificateVerificationError
+	var recordErr tls.RecordHeaderError
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		kind = ErrTimeout
+	case errors.As(err, &unknownCA), errors.As(err, &hostErr),
+		errors.As(err, &invalidCert), errors.As(err, &verifyErr),
+		errors.As(err, &recordErr):
+		kind = ErrTLS
+	case errors.As(err, &dnsErr) && dnsErr.IsNotFound:
+		kind = ErrNoHost
+	case errors.As(err, &netErr) && netErr.Timeout():
+		kind = ErrTimeout
+	}
+	return &FetchError{Kind: kind, URL: endpoint, Err: err}
+}
+
+const snippetLen = 200
+
+func snippet(body []byte) string {
+	s := strings.Join(strings.Fields(string(body)), " ")
+	if rs := []rune(s); len(rs) > snippetLen {
+		s = string(rs[:snippetLen]) + "…"
+	}
+	return s
+}
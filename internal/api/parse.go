@@ -0,0 +1,319 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Page is one decoded page of a models listing.
+type Page struct {
+	Models []ModelInfo
+	Next   *url.URL // next page to request, nil when the listing is complete
+}
+
+// ResponseParser decodes one models response. reqURL is the URL the body was
+// fetched from, so cursor-style pagination can build the follow-up request.
+type ResponseParser func(body []byte, reqURL *url.URL) (Page, error)
+
+var (
+	parsersMu sync.RWMutex
+	parsers   = map[string]ResponseParser{
+		"generic-chat-completion-api": parseGenericPage,
+		"openai":                      parseGenericPage,
+		"anthropic":                   parseAnthropicPage,
+		"gemini":                      parseGeminiPage,
+	}
+)
+
+// RegisterParser installs the parser used for a provider type or models format.
+func RegisterParser(format string, p ResponseParser) {
+	parsersMu.Lock()
+	defer parsersMu.Unlock()
+	parsers[format] = p
+}
+
+func parserFor(format string) ResponseParser {
+	parsersMu.RLock()
+	defer parsersMu.RUnlock()
+	if p, ok := parsers[format]; ok {
+		return p
+	}
+	return parseGenericPage
+}
+
+// ───────────────────────────────────────────────
+// Generic (OpenAI-style and friends)
+// ───────────────────────────────────────────────
+
+// parseGenericPage understands { "data": [...] }, { "models": [...] }, bare
+// arrays of strings or objects, and `object: list` envelopes. Pagination is
+// followed through `next` links, `has_more` + `last_id` and cursor fields.
+func parseGenericPage(body []byte, reqURL *url.URL) (Page, error) {
+	var root any
+	if err := json.Unmarshal(body, &root); err != nil {
+		return Page{}, err
+	}
+
+	var page Page
+	switch v := root.(type) {
+	case []any:
+		page.Models = modelsFromArray(v)
+		return page, nil
+	case map[string]any:
+		arr, ok := findModelArray(v)
+		if !ok {
+			return Page{}, fmt.Errorf("unrecognised models response format")
+		}
+		page.Models = modelsFromArray(arr)
+		page.Next = genericNext(v, reqURL)
+		return page, nil
+	}
+	return Page{}, fmt.Errorf("unrecognised models response format")
+}
+
+// findModelArray locates the models array inside an envelope, descending one
+// level into objects such as { "data": { "models": [...] } }.
+func findModelArray(obj map[string]any) ([]any, bool) {
+	keys := []string{"data", "models", "items", "results"}
+	for _, k := range keys {
+		if arr, ok := obj[k].([]any); ok {
+			return arr, true
+		}
+	}
+	for _, k := range keys {
+		if inner, ok := obj[k].(map[string]any); ok {
+			if arr, ok := findModelArray(inner); ok {
+				return arr, true
+			}
+		}
+	}
+	return nil, false
+}
+
+func genericNext(obj map[string]any, reqURL *url.URL) *url.URL {
+	for _, k := range []string{"next", "next_page", "next_page_url", "nextPage"} {
+		if s := str(obj[k]); s != "" {
+			return resolveNext(reqURL, s)
+		}
+	}
+	if links, ok := obj["links"].(map[string]any); ok {
+		if s := str(links["next"]); s != "" {
+			return resolveNext(reqURL, s)
+		}
+	}
+	for _, k := range []string{"next_cursor", "nextCursor", "cursor"} {
+		if s := str(obj[k]); s != "" {
+			return withQuery(reqURL, "cursor", s)
+		}
+	}
+	if more, _ := obj["has_more"].(bool); more {
+		if last := str(obj["last_id"]); last != "" {
+			return withQuery(reqURL, "after", last)
+		}
+	}
+	return nil
+}
+
+// ───────────────────────────────────────────────
+// Anthropic
+// ───────────────────────────────────────────────
+
+// parseAnthropicPage decodes GET /v1/models:
+// { "data": [...], "has_more": true, "last_id": "..." }.
+func parseAnthropicPage(body []byte, reqURL *url.URL) (Page, error) {
+	var resp struct {
+		Data    []map[string]any `json:"data"`
+		HasMore bool             `json:"has_more"`
+		LastID  string           `json:"last_id"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return Page{}, err
+	}
+	if resp.Data == nil {
+		return parseGenericPage(body, reqURL)
+	}
+	var page Page
+	for _, obj := range resp.Data {
+		if m, ok := modelFromObject(obj); ok {
+			page.Models = append(page.Models, m)
+		}
+	}
+	if resp.HasMore && resp.LastID != "" {
+		page.Next = withQuery(reqURL, "after_id", resp.LastID)
+	}
+	return page, nil
+}
+
+// ───────────────────────────────────────────────
+// Gemini (native generativelanguage API)
+// ───────────────────────────────────────────────
+
+// parseGeminiPage decodes { "models": [{ "name": "models/gemini-..." }], "nextPageToken": "..." }.
+// The "models/" prefix is stripped and models that cannot generate content
+// (embeddings, AQA) are skipped.
+func parseGeminiPage(body []byte, reqURL *url.URL) (Page, error) {
+	var resp struct {
+		Models        []map[string]any `json:"models"`
+		NextPageToken string           `json:"nextPageToken"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return Page{}, err
+	}
+	if resp.Models == nil {
+		return parseGenericPage(body, reqURL)
+	}
+	var page Page
+	for _, obj := range resp.Models {
+		if methods, ok := obj["supportedGenerationMethods"].([]any); ok && !containsStr(methods, "generateContent") {
+			continue
+		}
+		if m, ok := modelFromObject(obj); ok {
+			page.Models = append(page.Models, m)
+		}
+	}
+	if resp.NextPageToken != "" {
+		page.Next = withQuery(reqURL, "pageToken", resp.NextPageToken)
+	}
+	return page, nil
+}
+
+// ───────────────────────────────────────────────
+// Field extraction
+// ───────────────────────────────────────────────
+
+func modelsFromArray(arr []any) []ModelInfo {
+	out := make([]ModelInfo, 0, len(arr))
+	for _, item := range arr {
+		switch v := item.(type) {
+		case string:
+			if v != "" {
+				out = append(out, ModelInfo{ID: v, Name: v})
+			}
+		case map[string]any:
+			if m, ok := modelFromObject(v); ok {
+				out = append(out, m)
+			}
+		}
+	}
+	return out
+}
+
+// modelFromObject reads the ID, display name, context length, owner and
+// creation date from one model object, accepting the field names used by the
+// common providers.
+func modelFromObject(obj map[string]any) (ModelInfo, bool) {
+	id := firstStr(obj, "id", "model", "name")
+	id = strings.TrimPrefix(id, "models/")
+	if id == "" {
+		return ModelInfo{}, false
+	}
+	name := firstStr(obj, "display_name", "displayName", "name")
+	if name == "" || strings.HasPrefix(name, "models/") {
+		name = id
+	}
+	m := ModelInfo{
+		ID:      id,
+		Name:    name,
+		OwnedBy: firstStr(obj, "owned_by", "organization", "owner"),
+	}
+	m.ContextLength = firstInt(obj, "context_length", "context_window", "max_context_length", "inputTokenLimit", "max_model_len")
+	if m.ContextLength == 0 {
+		if tp, ok := obj["top_provider"].(map[string]any); ok {
+			m.ContextLength = firstInt(tp, "context_length")
+		}
+	}
+	for _, k := range []string{"created", "created_at", "createdAt"} {
+		if t, ok := parseTime(obj[k]); ok {
+			m.Created = t
+			break
+		}
+	}
+	return m, true
+}
+
+func str(v any) string {
+	s, _ := v.(string)
+	return s
+}
+
+func firstStr(obj map[string]any, keys ...string) string {
+	for _, k := range keys {
+		if s := str(obj[k]); s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+func firstInt(obj map[string]any, keys ...string) int {
+	for _, k := range keys {
+		switch v := obj[k].(type) {
+		case float64:
+			if v > 0 {
+				return int(v)
+			}
+		case string:
+			if n, err := strconv.Atoi(v); err == nil && n > 0 {
+				return n
+			}
+		}
+	}
+	return 0
+}
+
+// parseTime accepts unix seconds or an RFC 3339 timestamp.
+func parseTime(v any) (time.Time, bool) {
+	switch t := v.(type) {
+	case float64:
+		if t > 0 {
+			return time.Unix(int64(t), 0).UTC(), true
+		}
+	case string:
+		if ts, err := time.Parse(time.RFC3339, t); err == nil {
+			return ts, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func containsStr(arr []any, want string) bool {
+	for _, v := range arr {
+		if str(v) == want {
+			return true
+		}
+	}
+	return false
+}
+
+// ───────────────────────────────────────────────
+// Pagination helpers
+// ───────────────────────────────────────────────
+
+func withQuery(base *url.URL, key, val string) *url.URL {
+	if base == nil {
+		return nil
+	}
+	u := *base
+	q := u.Query()
+	q.Set(key, val)
+	u.RawQuery = q.Encode()
+	return &u
+}
+
+// resolveNext resolves a possibly relative next link. Links to another host
+// are ignored so the API key is never sent anywhere else.
+func resolveNext(base *url.URL, ref string) *url.URL {
+	if base == nil {
+		return nil
+	}
+	u, err := base.Parse(ref)
+	if err != nil || u.Host != base.Host || u.Scheme != base.Scheme {
+		return nil
+	}
+	return u
+}
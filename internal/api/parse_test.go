@@ -0,0 +1,216 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// The files in testdata are responses captured from the providers' models
+// endpoints, trimmed to a few entries.
+
+func TestParsePage(t *testing.T) {
+	reqURL, _ := url.Parse("https://api.example.com/v1/models")
+	tests := []struct {
+		file   string
+		format string
+		want   []ModelInfo
+		next   string // expected follow-up request, "" for none
+	}{
+		{
+			file:   "openrouter.json",
+			format: "generic-chat-completion-api",
+			want: []ModelInfo{
+				{ID: "anthropic/claude-sonnet-4.5", Name: "Anthropic: Claude Sonnet 4.5", ContextLength: 1000000, Created: time.Unix(1759161676, 0).UTC()},
+				{ID: "qwen/qwen3-coder:free", Name: "Qwen: Qwen3 Coder 480B A35B (free)", ContextLength: 262144, Created: time.Unix(1753230546, 0).UTC()},
+			},
+		},
+		{
+			file:   "openai.json",
+			format: "openai",
+			want: []ModelInfo{
+				{ID: "gpt-4o-2024-08-06", Name: "gpt-4o-2024-08-06", OwnedBy: "system", Created: time.Unix(1722814719, 0).UTC()},
+				{ID: "o1", Name: "o1", OwnedBy: "system", Created: time.Unix(1734375816, 0).UTC()},
+				{ID: "ft:gpt-4o-mini-2024-07-18:acme::A1b2C3d4", Name: "ft:gpt-4o-mini-2024-07-18:acme::A1b2C3d4", OwnedBy: "acme-org", Created: time.Unix(1727214000, 0).UTC()},
+			},
+		},
+		{
+			file:   "groq.json",
+			format: "generic-chat-completion-api",
+			want: []ModelInfo{
+				{ID: "llama-3.3-70b-versatile", Name: "llama-3.3-70b-versatile", ContextLength: 131072, OwnedBy: "Meta", Created: time.Unix(1733447754, 0).UTC()},
+				{ID: "whisper-large-v3", Name: "whisper-large-v3", ContextLength: 448, OwnedBy: "OpenAI", Created: time.Unix(1693721698, 0).UTC()},
+			},
+		},
+		{
+			file:   "anthropic.json",
+			format: "anthropic",
+			want: []ModelInfo{
+				{ID: "claude-opus-4-5-20251101", Name: "Claude Opus 4.5", Created: time.Date(2025, 11, 24, 0, 0, 0, 0, time.UTC)},
+				{ID: "claude-haiku-4-5-20251001", Name: "Claude Haiku 4.5", Created: time.Date(2025, 10, 15, 0, 0, 0, 0, time.UTC)},
+			},
+			next: "https://api.example.com/v1/models?after_id=claude-haiku-4-5-20251001",
+		},
+		{
+			file:   "gemini.json",
+			format: "gemini",
+			want: []ModelInfo{
+				{ID: "gemini-2.5-pro", Name: "Gemini 2.5 Pro", ContextLength: 1048576},
+			},
+			next: "https://api.example.com/v1/models?pageToken=Chltb2RlbHMvZ2VtaW5pLTIuNS1mbGFzaA%3D%3D",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			body, err := os.ReadFile(filepath.Join("testdata", tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			page, err := parserFor(tt.format)(body, reqURL)
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			if !slices.Equal(page.Models, tt.want) {
+				t.Errorf("models:\n got %+v\nwant %+v", page.Models, tt.want)
+			}
+			next := ""
+			if page.Next != nil {
+				next = page.Next.String()
+			}
+			if next != tt.next {
+				t.Errorf("next = %q, want %q", next, tt.next)
+			}
+		})
+	}
+}
+
+func TestFetchModelsPagination(t *testing.T) {
+	tests := []struct {
+		name      string
+		format    string
+		pages     func(r *http.Request) (int, string) // status and body for a request
+		wantIDs   []string
+		wantCalls int
+		partial   bool // a *PartialError is expected
+		pageErr   bool // and it wraps the failure of a page
+	}{
+		{
+			name:   "anthropic follow-up page",
+			format: "anthropic",
+			pages: func(r *http.Request) (int, string) {
+				if r.URL.Query().Get("after_id") == "b" {
+					return 200, `{"data":[{"id":"c"}],"has_more":false,"last_id":"c"}`
+				}
+				return 200, `{"data":[{"id":"a"},{"id":"b"}],"has_more":true,"last_id":"b"}`
+			},
+			wantIDs:   []string{"a", "b", "c"},
+			wantCalls: 2,
+		},
+		{
+			name:   "gemini follow-up page",
+			format: "gemini",
+			pages: func(r *http.Request) (int, string) {
+				if r.URL.Query().Get("pageToken") == "p2" {
+					return 200, `{"models":[{"name":"models/gemini-2.5-flash"}]}`
+				}
+				return 200, `{"models":[{"name":"models/gemini-2.5-pro"}],"nextPageToken":"p2"}`
+			},
+			wantIDs:   []string{"gemini-2.5-pro", "gemini-2.5-flash"},
+			wantCalls: 2,
+		},
+		{
+			name:   "repeated cursor",
+			format: "generic-chat-completion-api",
+			pages: func(r *http.Request) (int, string) {
+				return 200, `{"object":"list","data":[{"id":"m1"}],"next_cursor":"same"}`
+			},
+			wantIDs:   []string{"m1"},
+			wantCalls: 2,
+		},
+		{
+			name:   "maxPages cap",
+			format: "generic-chat-completion-api",
+			pages: func(r *http.Request) (int, string) {
+				n := len(r.URL.Query().Get("cursor"))
+				return 200, fmt.Sprintf(`{"data":[{"id":"m%d"}],"next_cursor":"%s"}`, n, r.URL.Query().Get("cursor")+"x")
+			},
+			wantCalls: maxPages,
+			partial:   true,
+		},
+		{
+			name:   "later page fails",
+			format: "anthropic",
+			pages: func(r *http.Request) (int, string) {
+				if r.URL.Query().Get("after_id") != "" {
+					return 404, `{"type":"error","error":{"type":"not_found_error","message":"Not found"}}`
+				}
+				return 200, `{"data":[{"id":"a"}],"has_more":true,"last_id":"a"}`
+			},
+			wantIDs:   []string{"a"},
+			wantCalls: 2,
+			partial:   true,
+			pageErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls.Add(1)
+				status, body := tt.pages(r)
+				w.WriteHeader(status)
+				fmt.Fprint(w, body)
+			}))
+			defer srv.Close()
+
+			models, err := FetchModels(context.Background(), srv.URL, "key", "/v1/models", tt.format, false)
+			var pe *PartialError
+			if got := errors.As(err, &pe); got != tt.partial {
+				t.Fatalf("err = %v, want partial %v", err, tt.partial)
+			}
+			if !tt.partial && err != nil {
+				t.Fatalf("err = %v", err)
+			}
+			if pe != nil && (pe.Err != nil) != tt.pageErr {
+				t.Errorf("PartialError.Err = %v, want page error %v", pe.Err, tt.pageErr)
+			}
+			if got := int(calls.Load()); got != tt.wantCalls {
+				t.Errorf("requests = %d, want %d", got, tt.wantCalls)
+			}
+			if tt.wantIDs == nil {
+				if len(models) != maxPages {
+					t.Errorf("got %d models, want %d", len(models), maxPages)
+				}
+				return
+			}
+			var ids []string
+			for _, m := range models {
+				ids = append(ids, m.ID)
+			}
+			if !slices.Equal(ids, tt.wantIDs) {
+				t.Errorf("ids = %v, want %v", ids, tt.wantIDs)
+			}
+		})
+	}
+}
+
+func TestUnknownHostIsNotRetried(t *testing.T) {
+	err := transportError(&url.Error{Op: "Get", URL: "https://api.exmaple.com/v1/models", Err: &net.DNSError{Err: "no such host", Name: "api.exmaple.com", IsNotFound: true}}, "https://api.exmaple.com/v1/models")
+	if err.Kind != ErrNoHost || err.retryable() {
+		t.Errorf("kind = %v, retryable = %v; want unknown host, not retried", err.Kind, err.retryable())
+	}
+	err = transportError(&net.DNSError{Err: "server misbehaving", Name: "api.example.com", IsTemporary: true}, "")
+	if err.Kind != ErrNetwork || !err.retryable() {
+		t.Errorf("kind = %v, retryable = %v; want network, retried", err.Kind, err.retryable())
+	}
+}
@@ -0,0 +1,107 @@
+// Package cli implements wrench's non-interactive subcommands.
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// command is one subcommand. args excludes the command path itself.
+type command struct {
+	path  []string
+	usage string // synopsis, without the program name
+	help  string // description; lines after the first continue it
+	run   func(args []string, out io.Writer) error
+}
+
+var commands = []command{
+	{[]string{"set"}, "set <key> <value>", "set a setting, checked against the settings schema", runSet},
+	{[]string{"unset"}, "unset <key>...", "remove keys from settings.json so Droid uses its defaults", runUnset},
+	{[]string{"schema"}, "schema [--embedded]", "print the settings schema, or the built-in one to start an override file", runSchema},
+	{[]string{"keys"}, "keys [--json]", "list the TUI's key bindings and check keys.json for conflicts\n(exit status 1 when it has any)", runKeys},
+	{[]string{"models", "list"}, "models list", "list custom models in model-selector order", runModelsList},
+	{[]string{"models", "move"}, "models move [--group] <id|prefix> up|down|top|bottom|<pos>", "reorder a model within its group, or a whole group", runModelsMove},
+	{[]string{"models", "variants"}, "models variants [--dry-run] <id> <file|->", "create variants of a model from a JSON list of parameter sets or a matrix", runModelsVariants},
+	{[]string{"models", "dedupe"}, "models dedupe [--dry-run]", "merge customModels entries with the same model and base URL", runModelsDedupe},
+	{[]string{"policy", "check"}, "policy check [--json] \"<command>\"", "show whether Droid's command policy allows, denies or asks about a command\n(exit status 0 allowed, 2 ask, 3 denied)", runPolicyCheck},
+	{[]string{"policy", "suggest"}, "policy suggest [--limit n]", "rank allowlist candidates from local bash, zsh and fish history", runPolicySuggest},
+	{[]string{"policy", "lint"}, "policy lint [--json]", "report contradictory, duplicate, shadowed and risky policy entries\n(exit status 1 when anything is found)", runPolicyLint},
+}
+
+// ExitError asks main to exit with Code without printing anything further;
+// the command has already reported its outcome.
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string { return fmt.Sprintf("exit status %d", e.Code) }
+
+// Run executes the subcommand named by args (without the program name).
+func Run(args []string) error {
+	return run(args, os.Stdout)
+}
+
+func run(args []string, out io.Writer) error {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		printUsage(out)
+		return nil
+	}
+	for _, c := range commands {
+		if hasPrefix(args, c.path) {
+			return c.run(args[len(c.path):], out)
+		}
+	}
+	printUsage(out)
+	return fmt.Errorf("unknown command %q", args[0])
+}
+
+func hasPrefix(args, path []string) bool {
+	if len(args) < len(path) {
+		return false
+	}
+	for i, p := range path {
+		if args[i] != p {
+			return false
+		}
+	}
+	return true
+}
+
+// usageWidth is the width of the synopsis column of the usage text. Longer
+// synopses put their description on the next line.
+const usageWidth = 29
+
+func printUsage(out io.Writer) {
+	fmt.Fprintln(out, "usage: wrench [command]")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "Without a command, wrench starts the interactive TUI:")
+	printEntry(out, "[--theme <name>] [--color auto|always|never] [--no-mouse] [--compact]",
+		"--theme: auto, dark, light, high-contrast, monochrome or a file\n"+
+			"in ~/.config/wrench/themes (default $WRENCH_THEME, else auto)\n"+
+			"--color=never and NO_COLOR drop all colors\n"+
+			"--no-mouse leaves clicks to the terminal, for selecting text\n"+
+			"--compact drops badges, list details and the detail pane")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "commands:")
+	for _, c := range commands {
+		printEntry(out, c.usage, c.help)
+	}
+}
+
+// printEntry prints a synopsis and its description, every line of which
+// starts in the same column.
+func printEntry(out io.Writer, usage, help string) {
+	lines := strings.Split(help, "\n")
+	if len(usage) < usageWidth {
+		fmt.Fprintf(out, "  wrench %-*s%s\n", usageWidth, usage, lines[0])
+		lines = lines[1:]
+	} else {
+		fmt.Fprintln(out, "  wrench "+usage)
+	}
+	indent := strings.Repeat(" ", len("  wrench ")+usageWidth)
+	for _, l := range lines {
+		fmt.Fprintln(out, indent+l)
+	}
+}
@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/kaan-escober/wrench/internal/keymap"
+)
+
+func runKeys(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("keys", flag.ContinueOnError)
+	fs.SetOutput(out)
+	asJSON := fs.Bool("json", false, "print the bindings as a keys.json, to start a remapping file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	m, loadErr := keymap.Load()
+	if *asJSON {
+		f := keymap.File{Bindings: map[string][]string{}}
+		for _, a := range keymap.Actions() {
+			f.Bindings[string(a)] = m.Keys(a)
+		}
+		enc := json.NewEncoder(out)
+		enc.SetEscapeHTML(false)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(f); err != nil {
+			return err
+		}
+		return loadErr
+	}
+
+	for _, a := range keymap.Actions() {
+		fmt.Fprintf(out, "%-14s %s\n", a, keymap.Display(m.Keys(a)))
+	}
+	if loadErr == nil {
+		return nil
+	}
+	var conflicts *keymap.ConflictError
+	if !errors.As(loadErr, &conflicts) {
+		return loadErr
+	}
+	fmt.Fprintf(out, "\n%s: %d conflict(s), the default keys are used:\n", keymap.Path(), len(conflicts.Conflicts))
+	for _, c := range conflicts.Conflicts {
+		fmt.Fprintln(out, "  "+c.String())
+	}
+	return &ExitError{Code: 1}
+}
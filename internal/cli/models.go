@@ -0,0 +1,231 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+
+	"github.com/kaan-escober/wrench/internal/config"
+	"github.com/kaan-escober/wrench/internal/droids"
+)
+
+func runModelsDedupe(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("models dedupe", flag.ContinueOnError)
+	fs.SetOutput(out)
+	dryRun := fs.Bool("dry-run", false, "list duplicates without changing settings.json")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	models, err := config.ReadCustomModels()
+	if err != nil {
+		return err
+	}
+	sets := config.FindDuplicates(models)
+	if len(sets) == 0 {
+		fmt.Fprintln(out, "no duplicate models")
+		return nil
+	}
+	for _, s := range sets {
+		fmt.Fprintf(out, "%s  %s\n", s.Keep.Model, s.Keep.BaseURL)
+		fmt.Fprintf(out, "  keep    %s  %s\n", s.Keep.ID, s.Keep.DisplayName)
+		for _, d := range s.Drop {
+			fmt.Fprintf(out, "  remove  %s  %s\n", d.ID, d.DisplayName)
+		}
+	}
+	if *dryRun {
+		return nil
+	}
+	n, err := config.MergeDuplicates()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "merged %d duplicate model(s)\n", n)
+	return nil
+}
+
+func runModelsList(args []string, out io.Writer) error {
+	groups, err := config.ReadProviderGroups()
+	if err != nil {
+		return err
+	}
+	if len(groups) == 0 {
+		fmt.Fprintln(out, "no custom models configured")
+		return nil
+	}
+	for _, g := range groups {
+		fmt.Fprintln(out, g.Prefix)
+		for _, m := range g.Models {
+			fmt.Fprintf(out, "  %-16s %-40s %s\n", m.ID, m.DisplayName, m.Model)
+		}
+	}
+	return nil
+}
+
+func runModelsMove(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("models move", flag.ContinueOnError)
+	fs.SetOutput(out)
+	group := fs.Bool("group", false, "move a whole provider group instead of one model")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return fmt.Errorf("usage: wrench models move [--group] <id|prefix> up|down|top|bottom|<pos>")
+	}
+	target, where := fs.Arg(0), fs.Arg(1)
+
+	groups, err := config.ReadProviderGroups()
+	if err != nil {
+		return err
+	}
+
+	if *group {
+		for i, g := range groups {
+			if g.Prefix == target {
+				pos, err := resolvePosition(where, i, len(groups))
+				if err != nil {
+					return err
+				}
+				if err := config.MoveGroup(target, pos); err != nil {
+					return err
+				}
+				fmt.Fprintf(out, "moved group %s to position %d\n", target, pos)
+				return nil
+			}
+		}
+		return fmt.Errorf("group %q not found", target)
+	}
+
+	for _, g := range groups {
+		for i, m := range g.Models {
+			if m.ID != target {
+				continue
+			}
+			pos, err := resolvePosition(where, i, len(g.Models))
+			if err != nil {
+				return err
+			}
+			newID, renames, err := config.MoveModel(target, pos)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "moved %s to position %d (now %s)\n", target, pos, newID)
+			if len(renames) > 0 {
+				home, _ := os.UserHomeDir()
+				cwd, _ := os.Getwd()
+				stale, _ := droids.UsingModels(home, cwd, slices.Collect(maps.Keys(renames)))
+				for _, d := range stale {
+					fmt.Fprintf(out, "warning: droid %s (%s) uses %s, which is now %s\n", d.Name, d.Path, d.Model, renames[d.Model])
+				}
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("model %q not found", target)
+}
+
+// resolvePosition turns up/down/top/bottom or a 0-based position into an index.
+func resolvePosition(where string, cur, n int) (int, error) {
+	switch where {
+	case "up":
+		return max(cur-1, 0), nil
+	case "down":
+		return min(cur+1, n-1), nil
+	case "top":
+		return 0, nil
+	case "bottom":
+		return n - 1, nil
+	}
+	pos, err := strconv.Atoi(where)
+	if err != nil || pos < 0 || pos >= n {
+		return 0, fmt.Errorf("position must be up, down, top, bottom or 0–%d", n-1)
+	}
+	return pos, nil
+}
+
+// runModelsVariants reads either a JSON array of config.VariantSpec or a
+// config.VariantMatrix ({"axes": [[...], [...]]}) and adds one variant per
+// parameter set.
+func runModelsVariants(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("models variants", flag.ContinueOnError)
+	fs.SetOutput(out)
+	dryRun := fs.Bool("dry-run", false, "print the variants without saving them")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 2 {
+		return fmt.Errorf("usage: wrench models variants [--dry-run] <id> <file|->")
+	}
+	id, file := fs.Arg(0), fs.Arg(1)
+
+	var data []byte
+	var err error
+	if file == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return err
+	}
+	specs, err := parseVariantSpecs(data)
+	if err != nil {
+		return err
+	}
+	if len(specs) == 0 {
+		return fmt.Errorf("no parameter sets in %s", file)
+	}
+
+	models, err := config.ReadCustomModels()
+	if err != nil {
+		return err
+	}
+	var base *config.ModelConfig
+	for i := range models {
+		if models[i].ID == id {
+			base = &models[i]
+		}
+	}
+	if base == nil {
+		return fmt.Errorf("model %q not found", id)
+	}
+
+	if *dryRun {
+		for _, s := range specs {
+			v := config.NewVariant(*base, s)
+			b, _ := json.Marshal(v.ExtraArgs)
+			fmt.Fprintf(out, "%-40s max=%d extraArgs=%s\n", v.DisplayName, v.MaxOutputTokens, b)
+		}
+		return nil
+	}
+	created, err := config.AddVariants(*base, specs)
+	if err != nil {
+		return err
+	}
+	for _, v := range created {
+		fmt.Fprintf(out, "added %-16s %s\n", v.ID, v.DisplayName)
+	}
+	return nil
+}
+
+func parseVariantSpecs(data []byte) ([]config.VariantSpec, error) {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '[' {
+		var specs []config.VariantSpec
+		if err := json.Unmarshal(data, &specs); err != nil {
+			return nil, fmt.Errorf("parse parameter sets: %w", err)
+		}
+		return specs, nil
+	}
+	var matrix config.VariantMatrix
+	if err := json.Unmarshal(data, &matrix); err != nil {
+		return nil, fmt.Errorf("parse variant matrix: %w", err)
+	}
+	return matrix.Expand(), nil
+}
@@ -0,0 +1,215 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/kaan-escober/wrench/internal/config"
+	"github.com/kaan-escober/wrench/internal/policy"
+)
+
+// Exit statuses of `policy check`, so scripts can branch on the verdict.
+const (
+	exitAllowed = 0
+	exitAsk     = 2
+	exitDenied  = 3
+)
+
+func runPolicyCheck(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("policy check", flag.ContinueOnError)
+	fs.SetOutput(out)
+	asJSON := fs.Bool("json", false, "print the result as JSON")
+	var extraAllow, extraDeny listFlag
+	fs.Var(&extraAllow, "allow", "also treat `entry` as allowlisted (repeatable)")
+	fs.Var(&extraDeny, "deny", "also treat `entry` as denylisted (repeatable)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		return fmt.Errorf("usage: wrench policy check [--json] [--allow entry] [--deny entry] \"<command>\"")
+	}
+	cmd := strings.Join(fs.Args(), " ")
+
+	s, _, err := config.ReadSettings()
+	if err != nil {
+		return err
+	}
+	allow := append(append([]string{}, s.GetList("commandAllowlist")...), extraAllow...)
+	deny := append(append([]string{}, s.GetList("commandDenylist")...), extraDeny...)
+	res := policy.Check(cmd, allow, deny)
+
+	if *asJSON {
+		if err := writeCheckJSON(out, res); err != nil {
+			return err
+		}
+	} else {
+		printCheck(out, res)
+	}
+
+	switch res.Verdict {
+	case policy.Deny:
+		return &ExitError{Code: exitDenied}
+	case policy.Ask:
+		return &ExitError{Code: exitAsk}
+	}
+	return nil
+}
+
+func printCheck(out io.Writer, res policy.Result) {
+	if res.Entry != "" {
+		fmt.Fprintf(out, "%s  (%s)\n", res.Verdict, res.Entry)
+	} else {
+		fmt.Fprintln(out, res.Verdict)
+	}
+	if len(res.Segments) > 1 || (len(res.Segments) == 1 && res.Segments[0].Text != strings.TrimSpace(res.Command)) {
+		for _, sr := range res.Segments {
+			line := fmt.Sprintf("  %-7s %s", sr.Verdict, sr.Text)
+			if sr.Entry != "" {
+				line += "  (" + sr.Entry + ")"
+			}
+			fmt.Fprintln(out, line)
+		}
+	}
+	for _, w := range res.Warnings {
+		fmt.Fprintln(out, "warning: "+w)
+	}
+}
+
+func writeCheckJSON(out io.Writer, res policy.Result) error {
+	type segment struct {
+		Command string `json:"command"`
+		Op      string `json:"op,omitempty"`
+		Nested  bool   `json:"nested,omitempty"`
+		Verdict string `json:"verdict"`
+		Entry   string `json:"entry,omitempty"`
+	}
+	v := struct {
+		Command  string    `json:"command"`
+		Verdict  string    `json:"verdict"`
+		Entry    string    `json:"entry,omitempty"`
+		Hidden   bool      `json:"hidden"`
+		Segments []segment `json:"segments"`
+		Warnings []string  `json:"warnings,omitempty"`
+	}{Command: res.Command, Verdict: res.Verdict.String(), Entry: res.Entry, Hidden: res.Hidden, Warnings: res.Warnings}
+	for _, sr := range res.Segments {
+		v.Segments = append(v.Segments, segment{sr.Text, sr.Op, sr.Nested, sr.Verdict.String(), sr.Entry})
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// listFlag collects a repeatable string flag.
+type listFlag []string
+
+func (l *listFlag) String() string { return strings.Join(*l, ", ") }
+
+func (l *listFlag) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+func runPolicySuggest(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("policy suggest", flag.ContinueOnError)
+	fs.SetOutput(out)
+	limit := fs.Int("limit", 30, "show at most `n` suggestions (0 for all)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	s, _, err := config.ReadSettings()
+	if err != nil {
+		return err
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return err
+	}
+	suggestions, files, err := policy.SuggestFromHistory(home, s.GetList("commandAllowlist"), s.GetList("commandDenylist"), *limit)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		fmt.Fprintln(out, "no bash, zsh or fish history file found")
+		return nil
+	}
+	if len(suggestions) == 0 {
+		fmt.Fprintln(out, "nothing to suggest")
+		return nil
+	}
+	for _, sg := range suggestions {
+		fmt.Fprintf(out, "%6d  %s\n", sg.Count, sg.Entry)
+	}
+	return nil
+}
+
+func runPolicyLint(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("policy lint", flag.ContinueOnError)
+	fs.SetOutput(out)
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	s, _, err := config.ReadSettings()
+	if err != nil {
+		return err
+	}
+	report := policy.Lint(s.GetList("commandAllowlist"), s.GetList("commandDenylist"))
+
+	if *asJSON {
+		type finding struct {
+			Kind    string `json:"kind"`
+			Column  string `json:"column"`
+			Entry   string `json:"entry"`
+			Message string `json:"message"`
+		}
+		type risk struct {
+			Entry   string   `json:"entry"`
+			Score   int      `json:"score"`
+			Reasons []string `json:"reasons,omitempty"`
+		}
+		v := struct {
+			Findings []finding `json:"findings"`
+			Risks    []risk    `json:"risks"`
+		}{Findings: []finding{}, Risks: []risk{}}
+		for _, f := range report.Findings {
+			v.Findings = append(v.Findings, finding{strings.ToLower(f.Kind.Label()), f.Column.String(), f.Entry, f.Message})
+		}
+		for _, r := range report.Risks {
+			v.Risks = append(v.Risks, risk{r.Entry, r.Score, r.Reasons})
+		}
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+	} else {
+		if len(report.Findings) == 0 {
+			fmt.Fprintln(out, "no problems found")
+		}
+		for _, f := range report.Findings {
+			fmt.Fprintf(out, "%-8s %-5s %-24s %s\n", strings.ToLower(f.Kind.Label()), f.Column, f.Entry, f.Message)
+		}
+		if len(report.Risks) > 0 {
+			fmt.Fprintln(out)
+			fmt.Fprintln(out, "allowlist risk:")
+			for _, r := range report.Risks {
+				line := fmt.Sprintf("  %2d/10  %s", r.Score, r.Entry)
+				if len(r.Reasons) > 0 {
+					line += "  — " + strings.Join(r.Reasons, "; ")
+				}
+				fmt.Fprintln(out, line)
+			}
+		}
+	}
+
+	if len(report.Findings) > 0 {
+		return &ExitError{Code: 1}
+	}
+	return nil
+}
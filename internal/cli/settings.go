@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/kaan-escober/wrench/internal/config"
+)
+
+func runSet(args []string, out io.Writer) error {
+	if len(args) != 2 {
+		return fmt.Errorf("usage: wrench set <key> <value>")
+	}
+	if err := config.SetKey(args[0], args[1]); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "set %s\n", args[0])
+	return nil
+}
+
+func runUnset(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: wrench unset <key>...")
+	}
+	removed, err := config.UnsetKeys(args...)
+	if err != nil {
+		return err
+	}
+	gone := map[string]bool{}
+	for _, k := range removed {
+		gone[k] = true
+		fmt.Fprintf(out, "removed %s\n", k)
+	}
+	for _, k := range args {
+		if !gone[k] {
+			fmt.Fprintf(out, "%s is not set\n", k)
+		}
+	}
+	return nil
+}
+
+func runSchema(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("schema", flag.ContinueOnError)
+	fs.SetOutput(out)
+	embedded := fs.Bool("embedded", false, "print the schema built into wrench, without "+config.SchemaPath())
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *embedded {
+		_, err := out.Write(config.EmbeddedSchema())
+		return err
+	}
+	sc, loadErr := config.CurrentSchema()
+	enc := json.NewEncoder(out)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(sc); err != nil {
+		return err
+	}
+	return loadErr
+}
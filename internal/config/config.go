@@ -1,11 +1,14 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 )
@@ -35,6 +38,16 @@ func SettingsPath() string {
 	return settingsPath()
 }
 
+// WrenchDir returns wrench's own configuration directory (presets and other
+// files Droid does not read), e.g. ~/.config/wrench on Linux.
+func WrenchDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return filepath.Join(home(), ".config", "wrench")
+	}
+	return filepath.Join(dir, "wrench")
+}
+
 // ───────────────────────────────────────────────
 // Custom model config (stored in settings.json → customModels)
 // ───────────────────────────────────────────────
@@ -72,6 +85,12 @@ func ReadCustomModels() ([]ModelConfig, error) {
 	if err := json.Unmarshal(data, &raw); err != nil {
 		return nil, err
 	}
+	return ParseCustomModels(raw)
+}
+
+// ParseCustomModels decodes the customModels array of an already loaded
+// settings map.
+func ParseCustomModels(raw map[string]any) ([]ModelConfig, error) {
 	arr, ok := raw["customModels"]
 	if !ok {
 		return nil, nil
@@ -109,6 +128,17 @@ func ReadProviderGroups() ([]ProviderGroup, error) {
 	return out, nil
 }
 
+// FindCustomModel returns the custom model a "model" setting refers to.
+// Droid references custom models by their customModels id.
+func FindCustomModel(models []ModelConfig, ref string) (ModelConfig, bool) {
+	for _, m := range models {
+		if m.ID == ref {
+			return m, true
+		}
+	}
+	return ModelConfig{}, false
+}
+
 // GetNextModelIndex returns the next available index for a given prefix.
 func GetNextModelIndex(prefix string) (int, error) {
 	models, err := ReadCustomModels()
@@ -185,8 +215,11 @@ func AddModelToSettings(cfg ModelConfig) error {
 	return writeJSON(path, raw)
 }
 
-// DeleteModelFromSettings removes a model by ID from settings.json.
-func DeleteModelFromSettings(id string) error {
+// DeleteModelFromSettings removes a model by ID from settings.json. If the
+// model was the default model, the "model" key is removed as well so Droid
+// falls back to its own default instead of a dangling reference; the result
+// reports whether that happened.
+func DeleteModelFromSettings(id string) (bool, error) {
 	mu.Lock()
 	defer mu.Unlock()
 
@@ -194,19 +227,19 @@ func DeleteModelFromSettings(id string) error {
 	raw := map[string]any{}
 	data, err := os.ReadFile(path)
 	if err != nil {
-		return err
+		return false, err
 	}
 	if err := json.Unmarshal(data, &raw); err != nil {
-		return err
+		return false, err
 	}
 
 	arr, ok := raw["customModels"]
 	if !ok {
-		return nil
+		return false, nil
 	}
 	models, ok := arr.([]any)
 	if !ok {
-		return nil
+		return false, nil
 	}
 
 	filtered := make([]any, 0, len(models))
@@ -220,7 +253,514 @@ func DeleteModelFromSettings(id string) error {
 	}
 
 	raw["customModels"] = filtered
-	return writeJSON(path, raw)
+	cleared := false
+	if ref, ok := raw["model"].(string); ok && ref == id {
+		delete(raw, "model")
+		cleared = true
+	}
+	return cleared, writeJSON(path, raw)
+}
+
+// ───────────────────────────────────────────────
+// Variants
+// ───────────────────────────────────────────────
+
+// VariantSpec is one set of overrides that turns a model into a variant.
+// Zero fields keep the base model's value; ExtraArgs and ExtraHeaders are
+// merged over the base's maps.
+type VariantSpec struct {
+	Suffix          string         `json:"suffix"`
+	DisplayName     string         `json:"displayName,omitempty"`
+	MaxOutputTokens int            `json:"maxOutputTokens,omitempty"`
+	SupportsImages  *bool          `json:"supportsImages,omitempty"`
+	ExtraArgs       map[string]any `json:"extraArgs,omitempty"`
+	ExtraHeaders    map[string]any `json:"extraHeaders,omitempty"`
+}
+
+// VariantMatrix combines one spec from each axis into a variant, e.g. axes
+// [low, high] × [fast, full] yield low-fast, low-full, high-fast, high-full.
+type VariantMatrix struct {
+	Axes [][]VariantSpec `json:"axes"`
+}
+
+// Expand returns every combination of the matrix axes. Suffixes are joined
+// with "-"; later axes override earlier ones.
+func (vm VariantMatrix) Expand() []VariantSpec {
+	out := []VariantSpec{{}}
+	for _, axis := range vm.Axes {
+		if len(axis) == 0 {
+			continue
+		}
+		next := make([]VariantSpec, 0, len(out)*len(axis))
+		for _, acc := range out {
+			for _, s := range axis {
+				next = append(next, combineSpecs(acc, s))
+			}
+		}
+		out = next
+	}
+	if len(out) == 1 && out[0].Suffix == "" {
+		return nil
+	}
+	return out
+}
+
+func combineSpecs(a, b VariantSpec) VariantSpec {
+	c := a
+	switch {
+	case a.Suffix == "":
+		c.Suffix = b.Suffix
+	case b.Suffix != "":
+		c.Suffix = a.Suffix + "-" + b.Suffix
+	}
+	if b.DisplayName != "" {
+		c.DisplayName = b.DisplayName
+	}
+	if b.MaxOutputTokens != 0 {
+		c.MaxOutputTokens = b.MaxOutputTokens
+	}
+	if b.SupportsImages != nil {
+		c.SupportsImages = b.SupportsImages
+	}
+	c.ExtraArgs = mergeMaps(a.ExtraArgs, b.ExtraArgs)
+	c.ExtraHeaders = mergeMaps(a.ExtraHeaders, b.ExtraHeaders)
+	return c
+}
+
+// NewVariant returns a copy of base with spec applied. ID and Index are
+// cleared; AddVariants assigns them.
+func NewVariant(base ModelConfig, spec VariantSpec) ModelConfig {
+	v := base
+	v.ID = ""
+	v.Index = 0
+	switch {
+	case spec.DisplayName != "":
+		v.DisplayName = spec.DisplayName
+	case spec.Suffix != "":
+		v.DisplayName = base.DisplayName + " (" + spec.Suffix + ")"
+	}
+	if spec.MaxOutputTokens > 0 {
+		v.MaxOutputTokens = spec.MaxOutputTokens
+	}
+	if spec.SupportsImages != nil {
+		v.SupportsImages = *spec.SupportsImages
+	}
+	v.ExtraArgs = mergeMaps(base.ExtraArgs, spec.ExtraArgs)
+	v.ExtraHeaders = mergeMaps(base.ExtraHeaders, spec.ExtraHeaders)
+	return v
+}
+
+// AddVariants creates one variant of base per spec, assigns each the next
+// "prefix:index" ID in base's group, and inserts them after the group's last
+// entry in settings.json. It returns the saved configs.
+func AddVariants(base ModelConfig, specs []VariantSpec) ([]ModelConfig, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	path := settingsPath()
+	raw := map[string]any{}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	models, _ := raw["customModels"].([]any)
+
+	prefix := IDPrefix(base.ID)
+	next := nextIndexFromRaw(models, prefix)
+	insertAt := len(models)
+	for i, m := range models {
+		if e, ok := m.(map[string]any); ok {
+			if id, _ := e["id"].(string); IDPrefix(id) == prefix {
+				insertAt = i + 1
+			}
+		}
+	}
+
+	created := make([]ModelConfig, 0, len(specs))
+	entries := make([]any, 0, len(specs))
+	for i, spec := range specs {
+		v := NewVariant(base, spec)
+		v.Index = next + i
+		v.ID = GenerateModelID(prefix, v.Index)
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		var entry map[string]any
+		if err := json.Unmarshal(b, &entry); err != nil {
+			return nil, err
+		}
+		created = append(created, v)
+		entries = append(entries, entry)
+	}
+
+	out := make([]any, 0, len(models)+len(entries))
+	out = append(out, models[:insertAt]...)
+	out = append(out, entries...)
+	out = append(out, models[insertAt:]...)
+	raw["customModels"] = out
+	return created, writeJSON(path, raw)
+}
+
+// mergeMaps returns a deep copy of base with over's keys applied on top.
+// Nested objects are merged recursively; nil is returned when both are empty.
+func mergeMaps(base, over map[string]any) map[string]any {
+	if len(base) == 0 && len(over) == 0 {
+		return nil
+	}
+	out := make(map[string]any, len(base)+len(over))
+	for k, v := range base {
+		out[k] = deepCopy(v)
+	}
+	for k, v := range over {
+		if bm, ok := out[k].(map[string]any); ok {
+			if om, ok := v.(map[string]any); ok {
+				out[k] = mergeMaps(bm, om)
+				continue
+			}
+		}
+		out[k] = deepCopy(v)
+	}
+	return out
+}
+
+func deepCopy(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(t))
+		for k, e := range t {
+			out[k] = deepCopy(e)
+		}
+		return out
+	case []any:
+		out := make([]any, len(t))
+		for i, e := range t {
+			out[i] = deepCopy(e)
+		}
+		return out
+	}
+	return v
+}
+
+// ───────────────────────────────────────────────
+// Ordering
+// ───────────────────────────────────────────────
+
+// rawGroup is a provider group of raw customModels entries.
+type rawGroup struct {
+	prefix  string
+	entries []map[string]any
+}
+
+// MoveModel moves the model with the given ID to position pos (clamped)
+// within its provider group and returns its new ID and the old → new IDs of
+// every model in the group that was renumbered. Only the "model" key follows
+// them; other references, such as droid files, are left to the caller.
+func MoveModel(id string, pos int) (string, map[string]string, error) {
+	renames, err := reorderModels(func(groups []rawGroup) (string, error) {
+		for gi := range groups {
+			for i, e := range groups[gi].entries {
+				if e["id"] == id {
+					moveItem(groups[gi].entries, i, pos)
+					return groups[gi].prefix, nil
+				}
+			}
+		}
+		return "", fmt.Errorf("model %q not found", id)
+	})
+	if err != nil {
+		return id, nil, err
+	}
+	if r, ok := renames[id]; ok {
+		return r, renames, nil
+	}
+	return id, renames, nil
+}
+
+// MoveGroup moves every model with the given ID prefix to group position pos
+// (clamped). No ID changes.
+func MoveGroup(prefix string, pos int) error {
+	_, err := reorderModels(func(groups []rawGroup) (string, error) {
+		for i, g := range groups {
+			if g.prefix == prefix {
+				moveItem(groups, i, pos)
+				return "", nil
+			}
+		}
+		return "", fmt.Errorf("group %q not found", prefix)
+	})
+	return err
+}
+
+// reorderModels regroups customModels, lets mutate reorder the groups and their
+// entries, then writes them back contiguously. mutate returns the prefix of
+// the group whose entries it reordered, if any; only that group is
+// renumbered. Only "id" and "index" change; a default model that referenced a
+// renumbered ID follows it. It returns the old → new ID renames.
+func reorderModels(mutate func([]rawGroup) (string, error)) (map[string]string, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	path := settingsPath()
+	raw := map[string]any{}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	models, _ := raw["customModels"].([]any)
+
+	var groups []rawGroup
+	var other []any
+	pos := map[string]int{}
+	for _, m := range models {
+		entry, ok := m.(map[string]any)
+		if !ok {
+			other = append(other, m)
+			continue
+		}
+		id, _ := entry["id"].(string)
+		prefix := IDPrefix(id)
+		i, seen := pos[prefix]
+		if !seen {
+			i = len(groups)
+			pos[prefix] = i
+			groups = append(groups, rawGroup{prefix: prefix})
+		}
+		groups[i].entries = append(groups[i].entries, entry)
+	}
+
+	moved, err := mutate(groups)
+	if err != nil {
+		return nil, err
+	}
+
+	renames := map[string]string{}
+	out := make([]any, 0, len(models))
+	for _, g := range groups {
+		if g.prefix == moved {
+			g.renumber(renames)
+		}
+		for _, e := range g.entries {
+			out = append(out, e)
+		}
+	}
+	out = append(out, other...)
+
+	if ref, ok := raw["model"].(string); ok {
+		if r, ok := renames[ref]; ok {
+			raw["model"] = r
+		}
+	}
+	raw["customModels"] = out
+	return renames, writeJSON(path, raw)
+}
+
+// renumber hands the group's "prefix:N" numbers out again in the new entry
+// order, so the IDs follow the order while models that did not move keep
+// theirs. Entries without such an ID (none at all, or a name after the
+// prefix) are left as they are. Changed IDs are added to renames.
+func (g rawGroup) renumber(renames map[string]string) {
+	var nums []int
+	var numbered []map[string]any
+	for _, e := range g.entries {
+		id, _ := e["id"].(string)
+		if n, ok := modelIndex(id, g.prefix); ok {
+			nums = append(nums, n)
+			numbered = append(numbered, e)
+		}
+	}
+	slices.Sort(nums)
+	for i, e := range numbered {
+		oldID := e["id"].(string)
+		newID := GenerateModelID(g.prefix, nums[i])
+		if oldID == newID {
+			continue
+		}
+		renames[oldID] = newID
+		e["id"] = newID
+		e["index"] = nums[i]
+	}
+}
+
+// modelIndex returns N for an ID of the form "prefix:N".
+func modelIndex(id, prefix string) (int, bool) {
+	s, ok := strings.CutPrefix(id, prefix+":")
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil || n < 0 || strconv.Itoa(n) != s {
+		return 0, false
+	}
+	return n, true
+}
+
+// moveItem moves s[from] to index to (clamped), shifting the items between.
+func moveItem[T any](s []T, from, to int) {
+	if to < 0 {
+		to = 0
+	}
+	if to > len(s)-1 {
+		to = len(s) - 1
+	}
+	item := s[from]
+	if from < to {
+		copy(s[from:to], s[from+1:to+1])
+	} else {
+		copy(s[to+1:from+1], s[to:from])
+	}
+	s[to] = item
+}
+
+// ───────────────────────────────────────────────
+// Duplicates
+// ───────────────────────────────────────────────
+
+// SameModel reports whether two configs point at the same model on the same endpoint.
+func SameModel(a, b ModelConfig) bool {
+	return dupKey(a.Model, a.BaseURL) == dupKey(b.Model, b.BaseURL)
+}
+
+// FindConfigured returns the IDs from modelIDs that are already configured
+// for baseURL in models.
+func FindConfigured(models []ModelConfig, baseURL string, modelIDs []string) map[string]bool {
+	existing := map[string]bool{}
+	for _, m := range models {
+		existing[dupKey(m.Model, m.BaseURL)] = true
+	}
+	out := map[string]bool{}
+	for _, id := range modelIDs {
+		if existing[dupKey(id, baseURL)] {
+			out[id] = true
+		}
+	}
+	return out
+}
+
+// DuplicateSet is one model configured more than once. Keep is the first
+// entry in customModels order; Drop are the later copies. Entries that share
+// a model and base URL but differ in maxOutputTokens, extraArgs or
+// extraHeaders are variants, not duplicates.
+type DuplicateSet struct {
+	Keep ModelConfig
+	Drop []ModelConfig
+}
+
+// FindDuplicates groups entries sharing the same model and base URL, across all groups.
+func FindDuplicates(models []ModelConfig) []DuplicateSet {
+	index := map[string]int{}
+	var sets []DuplicateSet
+	for _, m := range models {
+		k := dupKey(m.Model, m.BaseURL) + paramsKey(m.MaxOutputTokens, m.ExtraArgs, m.ExtraHeaders)
+		if i, ok := index[k]; ok {
+			sets[i].Drop = append(sets[i].Drop, m)
+			continue
+		}
+		index[k] = len(sets)
+		sets = append(sets, DuplicateSet{Keep: m})
+	}
+	out := sets[:0]
+	for _, s := range sets {
+		if len(s.Drop) > 0 {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// MergeDuplicates removes duplicate customModels entries from settings.json,
+// keeping the first occurrence of each model. Fields missing from the kept
+// entry are filled in from the removed copies, and a default model that
+// referenced a removed copy is pointed at the kept one. It returns the number
+// of entries removed.
+func MergeDuplicates() (int, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	path := settingsPath()
+	raw := map[string]any{}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return 0, err
+	}
+	models, _ := raw["customModels"].([]any)
+
+	keepers := map[string]map[string]any{}
+	kept := make([]any, 0, len(models))
+	removed := 0
+	for _, m := range models {
+		entry, ok := m.(map[string]any)
+		if !ok {
+			kept = append(kept, m)
+			continue
+		}
+		model, _ := entry["model"].(string)
+		baseURL, _ := entry["baseUrl"].(string)
+		k := dupKey(model, baseURL) + paramsKey(entry["maxOutputTokens"], entry["extraArgs"], entry["extraHeaders"])
+		keeper, seen := keepers[k]
+		if !seen {
+			keepers[k] = entry
+			kept = append(kept, entry)
+			continue
+		}
+		for field, v := range entry {
+			if _, has := keeper[field]; !has {
+				keeper[field] = v
+			}
+		}
+		if ref, ok := raw["model"].(string); ok && ref != "" && ref == entry["id"] {
+			raw["model"] = keeper["id"]
+		}
+		removed++
+	}
+	if removed == 0 {
+		return 0, nil
+	}
+	raw["customModels"] = kept
+	return removed, writeJSON(path, raw)
+}
+
+func dupKey(model, baseURL string) string {
+	return strings.TrimSpace(model) + "\x00" + strings.ToLower(strings.TrimRight(strings.TrimSpace(baseURL), "/"))
+}
+
+// paramsKey canonicalises the fields that distinguish variants of one model.
+// Typed and raw (decoded JSON) values produce the same key: an absent field,
+// null, 0 and an empty object are all the same, as ModelConfig cannot tell
+// them apart.
+func paramsKey(maxTokens, extraArgs, extraHeaders any) string {
+	norm := func(v any) any {
+		switch t := v.(type) {
+		case map[string]any:
+			if len(t) == 0 {
+				return nil
+			}
+		case int:
+			if t == 0 {
+				return nil
+			}
+			return float64(t)
+		case float64:
+			if t == 0 {
+				return nil
+			}
+		}
+		return v
+	}
+	b, _ := json.Marshal([]any{norm(maxTokens), norm(extraArgs), norm(extraHeaders)})
+	return "\x00" + string(b)
 }
 
 // ───────────────────────────────────────────────
@@ -268,11 +808,18 @@ func ensureDir(dir string) error {
 	return os.MkdirAll(dir, 0o700)
 }
 
+// writeJSON atomically replaces path with v as indented JSON. HTML
+// characters are not escaped, so shell commands such as hooks keep their
+// && and > readable.
 func writeJSON(path string, v any) error {
-	data, err := json.MarshalIndent(v, "", "  ")
-	if err != nil {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
 		return err
 	}
+	data := bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
 
 	dir := filepath.Dir(path)
 	tmp, err := os.CreateTemp(dir, ".wrench-tmp-*")
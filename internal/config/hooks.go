@@ -0,0 +1,91 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+// ───────────────────────────────────────────────
+// Hooks (stored in settings.json → hooks)
+// ───────────────────────────────────────────────
+
+// DisabledHooksPath returns the file holding hooks switched off in wrench.
+// Droid runs every hook in settings.json, so a disabled hook is moved here
+// until it is enabled again.
+func DisabledHooksPath() string {
+	return filepath.Join(WrenchDir(), "disabled-hooks.json")
+}
+
+// ReadHooks returns the hooks section of settings.json and the contents of
+// the disabled hooks file as decoded JSON, nil when absent.
+func ReadHooks() (section, disabled any, err error) {
+	raw, err := readRaw(settingsPath())
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := os.ReadFile(DisabledHooksPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return raw["hooks"], nil, nil
+		}
+		return nil, nil, err
+	}
+	if err := json.Unmarshal(data, &disabled); err != nil {
+		return nil, nil, err
+	}
+	return raw["hooks"], disabled, nil
+}
+
+// SaveHooks writes the hooks section of settings.json and the disabled
+// hooks file. An empty section removes the key; no disabled hooks removes
+// the file.
+func SaveHooks(section map[string]any, disabled []any) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	path := settingsPath()
+	raw, err := readRaw(path)
+	if err != nil {
+		return err
+	}
+	if len(section) == 0 {
+		delete(raw, "hooks")
+	} else {
+		raw["hooks"] = section
+	}
+	if err := ensureDir(filepath.Dir(path)); err != nil {
+		return err
+	}
+	if err := writeJSON(path, raw); err != nil {
+		return err
+	}
+
+	if len(disabled) == 0 {
+		if err := os.Remove(DisabledHooksPath()); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	if err := ensureDir(WrenchDir()); err != nil {
+		return err
+	}
+	return writeJSON(DisabledHooksPath(), disabled)
+}
+
+// readRaw decodes a JSON object file, returning an empty map when it does
+// not exist.
+func readRaw(path string) (map[string]any, error) {
+	raw := map[string]any{}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return raw, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
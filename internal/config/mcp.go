@@ -0,0 +1,41 @@
+package config
+
+import "path/filepath"
+
+// ───────────────────────────────────────────────
+// MCP servers (stored in ~/.factory/mcp.json → mcpServers)
+// ───────────────────────────────────────────────
+
+// MCPPath returns the path to Factory's MCP server configuration, which
+// sits next to settings.json.
+func MCPPath() string {
+	return filepath.Join(settingsDir(), "mcp.json")
+}
+
+// ReadMCP returns the mcpServers object of mcp.json as decoded JSON, nil
+// when the file or the key is absent.
+func ReadMCP() (any, error) {
+	raw, err := readRaw(MCPPath())
+	if err != nil {
+		return nil, err
+	}
+	return raw["mcpServers"], nil
+}
+
+// SaveMCP writes the mcpServers object of mcp.json, preserving any other
+// top-level key of the file.
+func SaveMCP(servers map[string]any) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	path := MCPPath()
+	raw, err := readRaw(path)
+	if err != nil {
+		return err
+	}
+	raw["mcpServers"] = servers
+	if err := ensureDir(filepath.Dir(path)); err != nil {
+		return err
+	}
+	return writeJSON(path, raw)
+}
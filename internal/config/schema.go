@@ -0,0 +1,401 @@
+package config
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ───────────────────────────────────────────────
+// Settings schema
+// ───────────────────────────────────────────────
+
+// SettingType is the value type of a setting in settings.json.
+type SettingType string
+
+const (
+	TypeEnum    SettingType = "enum"    // string from Options, or any string when Custom is set
+	TypeBool    SettingType = "bool"    // true / false, absent = inherit
+	TypeString  SettingType = "string"  // free text
+	TypeInteger SettingType = "integer" // whole number
+	TypePath    SettingType = "path"    // file or directory path
+	TypeList    SettingType = "list"    // array of strings
+	TypeObject  SettingType = "object"  // JSON object, shown but not edited in place
+)
+
+func (t SettingType) valid() bool {
+	switch t {
+	case TypeEnum, TypeBool, TypeString, TypeInteger, TypePath, TypeList, TypeObject:
+		return true
+	}
+	return false
+}
+
+// Option is one selectable value of an enum setting.
+type Option struct {
+	Value string `json:"value"`
+	Label string `json:"label,omitempty"` // defaults to Value
+	Desc  string `json:"desc,omitempty"`
+}
+
+// Title returns the label shown for the option.
+func (o Option) Title() string {
+	if o.Label != "" {
+		return o.Label
+	}
+	return o.Value
+}
+
+// CustomOption lets an enum setting take a value outside its Options, typed
+// in by the user (a sound file path, a model name).
+type CustomOption struct {
+	Label       string `json:"label"`
+	Desc        string `json:"desc,omitempty"`
+	Placeholder string `json:"placeholder,omitempty"`
+}
+
+// SettingSpec describes one top-level key of settings.json.
+type SettingSpec struct {
+	Key         string        `json:"key"`
+	Label       string        `json:"label"`
+	Type        SettingType   `json:"type"`
+	Category    string        `json:"category"`
+	Default     any           `json:"default,omitempty"` // Droid's value when the key is absent
+	Options     []Option      `json:"options,omitempty"`
+	Custom      *CustomOption `json:"custom,omitempty"`
+	Description string        `json:"description,omitempty"`
+
+	// Summary puts the setting in its category's main menu row: bools as a
+	// dot followed by this word, other types by their value. Inverted marks
+	// bools that turn the named feature off.
+	Summary  string `json:"summary,omitempty"`
+	Inverted bool   `json:"inverted,omitempty"`
+	Hidden   bool   `json:"hidden,omitempty"`
+}
+
+// DefaultText formats Default for display: bools as on/off, lists joined
+// with commas.
+func (s SettingSpec) DefaultText() string {
+	return formatValue(s.Default)
+}
+
+// DefaultOn reports whether a bool setting defaults to true.
+func (s SettingSpec) DefaultOn() bool {
+	b, _ := s.Default.(bool)
+	return b
+}
+
+// Option returns the option with the given value.
+func (s SettingSpec) Option(value string) (Option, bool) {
+	for _, o := range s.Options {
+		if o.Value == value {
+			return o, true
+		}
+	}
+	return Option{}, false
+}
+
+// Validate checks that v, a decoded JSON value, fits the setting's type.
+func (s SettingSpec) Validate(v any) error {
+	switch s.Type {
+	case TypeEnum:
+		str, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("%s: expected a string", s.Key)
+		}
+		if _, known := s.Option(str); !known && s.Custom == nil {
+			return fmt.Errorf("%s: %q is not one of the allowed values", s.Key, str)
+		}
+	case TypeBool:
+		if _, ok := v.(bool); !ok {
+			return fmt.Errorf("%s: expected true or false", s.Key)
+		}
+	case TypeString, TypePath:
+		if _, ok := v.(string); !ok {
+			return fmt.Errorf("%s: expected a string", s.Key)
+		}
+	case TypeInteger:
+		f, ok := v.(float64)
+		if !ok || f != float64(int64(f)) {
+			return fmt.Errorf("%s: expected a whole number", s.Key)
+		}
+	case TypeList:
+		arr, ok := v.([]any)
+		if !ok {
+			return fmt.Errorf("%s: expected a list", s.Key)
+		}
+		for _, e := range arr {
+			if _, ok := e.(string); !ok {
+				return fmt.Errorf("%s: list entries must be strings", s.Key)
+			}
+		}
+	case TypeObject:
+		if _, ok := v.(map[string]any); !ok {
+			return fmt.Errorf("%s: expected an object", s.Key)
+		}
+	}
+	return nil
+}
+
+// Parse converts text typed by the user into the setting's JSON value.
+// Lists are comma-separated. Objects cannot be parsed from text.
+func (s SettingSpec) Parse(text string) (any, error) {
+	var v any
+	switch s.Type {
+	case TypeBool:
+		b, err := strconv.ParseBool(text)
+		if err != nil {
+			switch strings.ToLower(text) {
+			case "on", "yes":
+				b = true
+			case "off", "no":
+				b = false
+			default:
+				return nil, fmt.Errorf("%s: expected on or off", s.Key)
+			}
+		}
+		v = b
+	case TypeInteger:
+		n, err := strconv.ParseInt(text, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %q is not a whole number", s.Key, text)
+		}
+		v = float64(n)
+	case TypeList:
+		arr := []any{}
+		for _, e := range strings.Split(text, ",") {
+			if e = strings.TrimSpace(e); e != "" {
+				arr = append(arr, e)
+			}
+		}
+		v = arr
+	case TypeObject:
+		return nil, fmt.Errorf("%s: objects are edited in settings.json", s.Key)
+	default:
+		v = text
+	}
+	return v, s.Validate(v)
+}
+
+// CategorySpec is one settings category of the main menu.
+type CategorySpec struct {
+	ID          string `json:"id"`
+	Badge       string `json:"badge"`
+	Label       string `json:"label"`
+	Description string `json:"description,omitempty"`
+}
+
+// Schema is the registry of settings wrench knows how to edit.
+type Schema struct {
+	Categories []CategorySpec `json:"categories"`
+	Settings   []SettingSpec  `json:"settings"`
+}
+
+// Lookup returns the spec for a settings.json key.
+func (sc *Schema) Lookup(key string) (SettingSpec, bool) {
+	for _, s := range sc.Settings {
+		if s.Key == key {
+			return s, true
+		}
+	}
+	return SettingSpec{}, false
+}
+
+// InCategory returns the visible settings of a category in schema order.
+func (sc *Schema) InCategory(id string) []SettingSpec {
+	var out []SettingSpec
+	for _, s := range sc.Settings {
+		if s.Category == id && !s.Hidden {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// Category returns the category with the given id.
+func (sc *Schema) Category(id string) (CategorySpec, bool) {
+	for _, c := range sc.Categories {
+		if c.ID == id {
+			return c, true
+		}
+	}
+	return CategorySpec{}, false
+}
+
+//go:embed schema.json
+var embeddedSchema []byte
+
+// SchemaPath returns the user file whose entries override the embedded
+// schema.
+func SchemaPath() string {
+	return filepath.Join(WrenchDir(), "settings-schema.json")
+}
+
+var loadSchemaOnce = sync.OnceValues(func() (*Schema, error) {
+	return loadSchema(SchemaPath())
+})
+
+// CurrentSchema returns the embedded schema with the user's overrides
+// applied. A broken override file is reported as an error alongside the
+// schema, which then contains whatever could be applied.
+func CurrentSchema() (*Schema, error) {
+	return loadSchemaOnce()
+}
+
+func schema() *Schema {
+	sc, _ := CurrentSchema()
+	return sc
+}
+
+// EmbeddedSchema returns the schema shipped with wrench, for use as a
+// starting point for an override file.
+func EmbeddedSchema() []byte {
+	return embeddedSchema
+}
+
+func loadSchema(userPath string) (*Schema, error) {
+	var sc Schema
+	if err := json.Unmarshal(embeddedSchema, &sc); err != nil {
+		panic("config: embedded schema.json: " + err.Error())
+	}
+	data, err := os.ReadFile(userPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &sc, nil
+		}
+		return &sc, err
+	}
+	if err := sc.overlay(data); err != nil {
+		return &sc, fmt.Errorf("%s: %w", userPath, err)
+	}
+	return &sc, nil
+}
+
+// overlay applies a user schema file. Entries whose key or id already
+// exists are merged field by field (lists such as options are replaced
+// whole); new entries are appended.
+func (sc *Schema) overlay(data []byte) error {
+	var file struct {
+		Categories []json.RawMessage `json:"categories"`
+		Settings   []json.RawMessage `json:"settings"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, raw := range file.Categories {
+		var c CategorySpec
+		if err := json.Unmarshal(raw, &c); err != nil || c.ID == "" {
+			errs = append(errs, fmt.Errorf("category without an id: %s", raw))
+			continue
+		}
+		i := sc.categoryIndex(c.ID)
+		if i < 0 {
+			sc.Categories = append(sc.Categories, c)
+			continue
+		}
+		_ = json.Unmarshal(raw, &sc.Categories[i])
+	}
+	for _, raw := range file.Settings {
+		var key struct {
+			Key     string          `json:"key"`
+			Options json.RawMessage `json:"options"`
+			Custom  json.RawMessage `json:"custom"`
+		}
+		if err := json.Unmarshal(raw, &key); err != nil || key.Key == "" {
+			errs = append(errs, fmt.Errorf("setting without a key: %s", raw))
+			continue
+		}
+		spec, _ := sc.Lookup(key.Key)
+		// Decoding into the existing slice and pointer would merge into
+		// their elements; replace them instead.
+		if key.Options != nil {
+			spec.Options = nil
+		}
+		if key.Custom != nil {
+			spec.Custom = nil
+		}
+		if err := json.Unmarshal(raw, &spec); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key.Key, err))
+			continue
+		}
+		if err := sc.check(spec); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if i := sc.settingIndex(key.Key); i >= 0 {
+			sc.Settings[i] = spec
+		} else {
+			sc.Settings = append(sc.Settings, spec)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// check rejects specs the views could not render.
+func (sc *Schema) check(s SettingSpec) error {
+	if !s.Type.valid() {
+		return fmt.Errorf("%s: unknown type %q", s.Key, s.Type)
+	}
+	if s.Type == TypeEnum && len(s.Options) == 0 && s.Custom == nil {
+		return fmt.Errorf("%s: enum without options", s.Key)
+	}
+	if _, ok := sc.Category(s.Category); !ok {
+		return fmt.Errorf("%s: unknown category %q", s.Key, s.Category)
+	}
+	if s.Label == "" {
+		return fmt.Errorf("%s: missing label", s.Key)
+	}
+	return nil
+}
+
+func (sc *Schema) categoryIndex(id string) int {
+	for i, c := range sc.Categories {
+		if c.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (sc *Schema) settingIndex(key string) int {
+	for i, s := range sc.Settings {
+		if s.Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// formatValue renders a decoded JSON value for display.
+func formatValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		if v {
+			return "on"
+		}
+		return "off"
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []any:
+		parts := make([]string, len(v))
+		for i, e := range v {
+			parts[i] = formatValue(e)
+		}
+		return strings.Join(parts, ", ")
+	case map[string]any:
+		return fmt.Sprintf("{%d keys}", len(v))
+	}
+	return fmt.Sprint(v)
+}
@@ -2,101 +2,162 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
+	"maps"
 	"os"
 )
 
-// Settings mirrors the typed fields in ~/.factory/settings.json.
+// Settings holds the values of the keys described by the settings schema,
+// as decoded JSON. A key that is absent is unset and Droid's default applies.
 // Unknown fields are preserved via the raw map in ReadSettings/WriteSettings.
 type Settings struct {
-	Model                    string   `json:"model,omitempty"`
-	ReasoningEffort          string   `json:"reasoningEffort,omitempty"`
-	AutonomyLevel            string   `json:"autonomyLevel,omitempty"`
-	CloudSessionSync         *bool    `json:"cloudSessionSync,omitempty"`
-	DiffMode                 string   `json:"diffMode,omitempty"`
-	CompletionSound          string   `json:"completionSound,omitempty"`
-	AwaitingInputSound       string   `json:"awaitingInputSound,omitempty"`
-	SoundFocusMode           string   `json:"soundFocusMode,omitempty"`
-	CommandAllowlist         []string `json:"commandAllowlist,omitempty"`
-	CommandDenylist          []string `json:"commandDenylist,omitempty"`
-	IncludeCoAuthoredByDroid *bool    `json:"includeCoAuthoredByDroid,omitempty"`
-	EnableDroidShield        *bool    `json:"enableDroidShield,omitempty"`
-	HooksDisabled            *bool    `json:"hooksDisabled,omitempty"`
-	IdeAutoConnect           *bool    `json:"ideAutoConnect,omitempty"`
-	TodoDisplayMode          string   `json:"todoDisplayMode,omitempty"`
-	SpecSaveEnabled          *bool    `json:"specSaveEnabled,omitempty"`
-	SpecSaveDir              string   `json:"specSaveDir,omitempty"`
-	EnableCustomDroids       *bool    `json:"enableCustomDroids,omitempty"`
-	ShowThinkingInMainView   *bool    `json:"showThinkingInMainView,omitempty"`
-	AllowBackgroundProcesses *bool    `json:"allowBackgroundProcesses,omitempty"`
-	EnableReadinessReport    *bool    `json:"enableReadinessReport,omitempty"`
-}
-
-// GetField returns the value of a string/enum setting by its JSON key.
+	values map[string]any
+}
+
+// Value returns the stored value of a setting.
+func (s *Settings) Value(key string) (any, bool) {
+	v, ok := s.values[key]
+	return v, ok
+}
+
+// GetField returns a setting by its JSON key formatted as text, or "" when
+// it is unset.
 func (s *Settings) GetField(key string) string {
-	switch key {
-	case "model":              return s.Model
-	case "reasoningEffort":    return s.ReasoningEffort
-	case "autonomyLevel":      return s.AutonomyLevel
-	case "diffMode":           return s.DiffMode
-	case "completionSound":    return s.CompletionSound
-	case "awaitingInputSound": return s.AwaitingInputSound
-	case "soundFocusMode":     return s.SoundFocusMode
-	case "todoDisplayMode":    return s.TodoDisplayMode
-	case "specSaveDir":        return s.SpecSaveDir
-	}
-	return ""
-}
-
-// SetField sets a string/enum setting by its JSON key.
-func (s *Settings) SetField(key, val string) {
-	switch key {
-	case "model":              s.Model = val
-	case "reasoningEffort":    s.ReasoningEffort = val
-	case "autonomyLevel":      s.AutonomyLevel = val
-	case "diffMode":           s.DiffMode = val
-	case "completionSound":    s.CompletionSound = val
-	case "awaitingInputSound": s.AwaitingInputSound = val
-	case "soundFocusMode":     s.SoundFocusMode = val
-	case "todoDisplayMode":    s.TodoDisplayMode = val
-	case "specSaveDir":        s.SpecSaveDir = val
-	}
-}
-
-// GetBool returns a *bool setting by its JSON key.
-func (s *Settings) GetBool(key string) *bool {
-	switch key {
-	case "cloudSessionSync":         return s.CloudSessionSync
-	case "includeCoAuthoredByDroid": return s.IncludeCoAuthoredByDroid
-	case "enableDroidShield":        return s.EnableDroidShield
-	case "hooksDisabled":            return s.HooksDisabled
-	case "ideAutoConnect":           return s.IdeAutoConnect
-	case "specSaveEnabled":          return s.SpecSaveEnabled
-	case "enableCustomDroids":       return s.EnableCustomDroids
-	case "showThinkingInMainView":   return s.ShowThinkingInMainView
-	case "allowBackgroundProcesses": return s.AllowBackgroundProcesses
-	case "enableReadinessReport":    return s.EnableReadinessReport
+	return formatValue(s.values[key])
+}
+
+// SetField parses text according to the setting's schema type and stores
+// it. Empty text unsets the key.
+func (s *Settings) SetField(key, text string) error {
+	if text == "" {
+		s.Unset(key)
+		return nil
+	}
+	spec, ok := schema().Lookup(key)
+	if !ok {
+		return fmt.Errorf("unknown setting %q", key)
 	}
+	v, err := spec.Parse(text)
+	if err != nil {
+		return err
+	}
+	s.set(key, v)
+	return nil
+}
+
+// Set stores a decoded JSON value after checking it against the schema.
+func (s *Settings) Set(key string, v any) error {
+	spec, ok := schema().Lookup(key)
+	if !ok {
+		return fmt.Errorf("unknown setting %q", key)
+	}
+	if err := spec.Validate(v); err != nil {
+		return err
+	}
+	s.set(key, v)
 	return nil
 }
 
-// SetBool sets a *bool setting by its JSON key.
-func (s *Settings) SetBool(key string, val bool) {
-	switch key {
-	case "cloudSessionSync":         s.CloudSessionSync = bptr(val)
-	case "includeCoAuthoredByDroid": s.IncludeCoAuthoredByDroid = bptr(val)
-	case "enableDroidShield":        s.EnableDroidShield = bptr(val)
-	case "hooksDisabled":            s.HooksDisabled = bptr(val)
-	case "ideAutoConnect":           s.IdeAutoConnect = bptr(val)
-	case "specSaveEnabled":          s.SpecSaveEnabled = bptr(val)
-	case "enableCustomDroids":       s.EnableCustomDroids = bptr(val)
-	case "showThinkingInMainView":   s.ShowThinkingInMainView = bptr(val)
-	case "allowBackgroundProcesses": s.AllowBackgroundProcesses = bptr(val)
-	case "enableReadinessReport":    s.EnableReadinessReport = bptr(val)
+// GetList returns a list setting; nil when unset.
+func (s *Settings) GetList(key string) []string {
+	arr, ok := s.values[key].([]any)
+	if !ok {
+		return nil
+	}
+	out := make([]string, 0, len(arr))
+	for _, e := range arr {
+		if str, ok := e.(string); ok {
+			out = append(out, str)
+		}
+	}
+	return out
+}
+
+// SetList stores a list setting. A nil list unsets the key; an empty one is
+// written as [].
+func (s *Settings) SetList(key string, list []string) {
+	if list == nil {
+		s.Unset(key)
+		return
+	}
+	arr := make([]any, len(list))
+	for i, e := range list {
+		arr[i] = e
 	}
+	s.set(key, arr)
+}
+
+// set copies the map before writing so that copies of a Settings value,
+// which the UI keeps in every model snapshot, stay independent.
+func (s *Settings) set(key string, v any) {
+	values := make(map[string]any, len(s.values)+1)
+	maps.Copy(values, s.values)
+	values[key] = v
+	s.values = values
 }
 
 func bptr(b bool) *bool { return &b }
 
+// TriState is a boolean setting that may also be left unset, in which case
+// Droid's own default applies. In settings.json Inherit is an absent key.
+type TriState int
+
+const (
+	Inherit TriState = iota // key absent — Droid's default applies
+	On                      // explicitly true
+	Off                     // explicitly false
+)
+
+func (t TriState) String() string {
+	switch t {
+	case On:
+		return "on"
+	case Off:
+		return "off"
+	}
+	return "inherit"
+}
+
+// TriOf converts stored *bool to a TriState.
+func TriOf(b *bool) TriState {
+	switch {
+	case b == nil:
+		return Inherit
+	case *b:
+		return On
+	}
+	return Off
+}
+
+// Ptr converts a TriState back to its stored form.
+func (t TriState) Ptr() *bool {
+	switch t {
+	case On:
+		return bptr(true)
+	case Off:
+		return bptr(false)
+	}
+	return nil
+}
+
+// GetTri returns a boolean setting by its JSON key as a TriState.
+func (s *Settings) GetTri(key string) TriState {
+	b, ok := s.values[key].(bool)
+	if !ok {
+		return Inherit
+	}
+	return TriOf(&b)
+}
+
+// SetTri sets a boolean setting by its JSON key; Inherit unsets it.
+func (s *Settings) SetTri(key string, t TriState) {
+	if t == Inherit {
+		s.Unset(key)
+		return
+	}
+	s.set(key, t == On)
+}
+
 // ReadSettings loads Settings and the raw map from settings.json.
 // The raw map preserves ALL fields (customModels, hooks, etc.) so they are
 // never lost when we write back.
@@ -112,30 +173,97 @@ func ReadSettings() (Settings, map[string]any, error) {
 	if err := json.Unmarshal(data, &raw); err != nil {
 		return Settings{}, raw, err
 	}
-	var s Settings
-	if err := json.Unmarshal(data, &s); err != nil {
-		return Settings{}, raw, err
+	s := Settings{values: map[string]any{}}
+	for _, k := range SettingKeys() {
+		if v, ok := raw[k]; ok {
+			s.values[k] = v
+		}
 	}
 	return s, raw, nil
 }
 
 // WriteSettings merges s into raw (preserving unknown fields) and atomically writes to disk.
+// Schema keys that s leaves unset are removed so that going back to the
+// default sticks. raw itself is not modified: the UI saves from a goroutine
+// while its views keep reading the map.
 func WriteSettings(s Settings, raw map[string]any) error {
 	if err := ensureDir(settingsDirPath()); err != nil {
 		return err
 	}
-	data, err := json.Marshal(s)
+	raw = maps.Clone(raw)
+	for _, k := range SettingKeys() {
+		delete(raw, k)
+	}
+	maps.Copy(raw, s.values)
+	return writeJSON(settingsPath(), raw)
+}
+
+// SettingKeys returns the JSON keys described by the settings schema.
+func SettingKeys() []string {
+	specs := schema().Settings
+	keys := make([]string, len(specs))
+	for i, spec := range specs {
+		keys[i] = spec.Key
+	}
+	return keys
+}
+
+// Unset clears a setting by its JSON key so WriteSettings removes it. It
+// reports whether the key is described by the schema.
+func (s *Settings) Unset(key string) bool {
+	if _, ok := schema().Lookup(key); !ok {
+		return false
+	}
+	if _, ok := s.values[key]; ok {
+		values := maps.Clone(s.values)
+		delete(values, key)
+		s.values = values
+	}
+	return true
+}
+
+// SetKey parses text as the schema type of key and writes it to
+// settings.json.
+func SetKey(key, text string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	s, raw, err := ReadSettings()
 	if err != nil {
 		return err
 	}
-	var patch map[string]any
-	if err := json.Unmarshal(data, &patch); err != nil {
+	if _, ok := schema().Lookup(key); !ok {
+		return fmt.Errorf("unknown setting %q (see wrench schema)", key)
+	}
+	if err := s.SetField(key, text); err != nil {
 		return err
 	}
-	for k, v := range patch {
-		raw[k] = v
+	return WriteSettings(s, raw)
+}
+
+// UnsetKeys removes top-level keys from settings.json, whether or not
+// Settings models them, and returns the ones that were present.
+func UnsetKeys(keys ...string) ([]string, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	s, raw, err := ReadSettings()
+	if err != nil {
+		return nil, err
+	}
+	var removed []string
+	for _, k := range keys {
+		if _, ok := raw[k]; ok {
+			removed = append(removed, k)
+		}
+		if !s.Unset(k) {
+			delete(raw, k)
+		}
 	}
-	return writeJSON(settingsPath(), raw)
+	if len(removed) == 0 {
+		return nil, nil
+	}
+	return removed, WriteSettings(s, raw)
 }
 
 func settingsDirPath() string {
@@ -0,0 +1,407 @@
+// Package droids reads, checks and writes Droid's custom droids: markdown
+// files whose YAML frontmatter names the droid, its model and its tools,
+// followed by the droid's system prompt.
+package droids
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kaan-escober/wrench/internal/scope"
+)
+
+// Scope says where a droid file lives.
+type Scope = scope.Scope
+
+const (
+	Personal = scope.Personal // ~/.factory/droids, available in every project
+	Project  = scope.Project  // <project>/.factory/droids, shared through the repository
+)
+
+// Inherit is the model value that uses the model of the parent session.
+const Inherit = "inherit"
+
+// Tool is a tool a droid can be given.
+type Tool struct {
+	ID       string
+	Category string
+}
+
+// Tools are Droid's tools in picker order.
+var Tools = []Tool{
+	{"Read", "read-only"},
+	{"LS", "read-only"},
+	{"Grep", "read-only"},
+	{"Glob", "read-only"},
+	{"Create", "edit"},
+	{"Edit", "edit"},
+	{"ApplyPatch", "edit"},
+	{"Execute", "execute"},
+	{"WebSearch", "web"},
+	{"FetchUrl", "web"},
+	{"TodoWrite", "planning"},
+}
+
+// Categories are the tool groups a droid's tools field may name instead of
+// a list.
+var Categories = []string{"read-only", "edit", "execute", "web", "mcp"}
+
+// CategoryTools returns the tools of a category.
+func CategoryTools(category string) []string {
+	var out []string
+	for _, t := range Tools {
+		if t.Category == category {
+			out = append(out, t.ID)
+		}
+	}
+	return out
+}
+
+// Droid is one droid file.
+type Droid struct {
+	Name        string
+	Description string
+	Model       string   // "" or Inherit uses the session's model
+	Tools       []string // nil gives the droid every tool
+	ToolsField  string   // the tools value as written when it is a category, e.g. "read-only"
+	Prompt      string
+
+	Path     string
+	Scope    Scope
+	Problems []string // frontmatter issues found by Load and Check
+}
+
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// ValidName reports why name cannot be used as a droid name, or nil.
+func ValidName(name string) error {
+	if name == "" {
+		return errors.New("the name is empty")
+	}
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("%q: use lowercase letters, digits, - and _", name)
+	}
+	return nil
+}
+
+// Check fills d.Problems. models are the model values Droid accepts besides
+// inherit; nil skips the model check.
+func (d *Droid) Check(models map[string]bool) {
+	var p []string
+	if err := ValidName(d.Name); err != nil {
+		p = append(p, "name: "+err.Error())
+	} else if base := strings.TrimSuffix(filepath.Base(d.Path), ".md"); d.Path != "" && base != d.Name {
+		p = append(p, fmt.Sprintf("name %q does not match the file name %q", d.Name, base))
+	}
+	if strings.TrimSpace(d.Description) == "" {
+		p = append(p, "description is missing · Droid uses it to decide when to delegate")
+	}
+	if models != nil && d.Model != "" && d.Model != Inherit && !models[d.Model] {
+		p = append(p, fmt.Sprintf("model %q is neither a built-in nor a custom model", d.Model))
+	}
+	if d.ToolsField != "" && !slices.Contains(Categories, d.ToolsField) {
+		p = append(p, fmt.Sprintf("tools: unknown category %q", d.ToolsField))
+	}
+	for _, t := range d.Tools {
+		if !knownTool(t) {
+			p = append(p, fmt.Sprintf("tools: unknown tool %q", t))
+		}
+	}
+	if strings.TrimSpace(d.Prompt) == "" {
+		p = append(p, "the system prompt below the frontmatter is empty")
+	}
+	d.Problems = append(d.Problems, p...)
+}
+
+func knownTool(id string) bool {
+	for _, t := range Tools {
+		if t.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse reads a droid file. Frontmatter it cannot read is reported as an
+// error; the fields are left empty.
+func Parse(data []byte) (Droid, error) {
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	rest, ok := strings.CutPrefix(text, "---\n")
+	if !ok {
+		return Droid{Prompt: text}, errors.New("no frontmatter: the file must start with ---")
+	}
+	front, body, ok := strings.Cut(rest, "\n---")
+	if !ok {
+		return Droid{Prompt: text}, errors.New("the frontmatter is not closed with ---")
+	}
+	_, body, _ = strings.Cut(body, "\n")
+
+	d := Droid{Prompt: strings.TrimLeft(body, "\n")}
+	fields, err := parseFrontmatter(front)
+	if err != nil {
+		return d, err
+	}
+	for key, v := range fields {
+		switch key {
+		case "name":
+			d.Name, err = scalar(key, v)
+		case "description":
+			d.Description, err = scalar(key, v)
+		case "model":
+			d.Model, err = scalar(key, v)
+		case "tools":
+			switch v := v.(type) {
+			case string:
+				d.ToolsField = v
+				d.Tools = CategoryTools(v)
+			case []string:
+				d.Tools = v
+			}
+		}
+		if err != nil {
+			return d, err
+		}
+	}
+	return d, nil
+}
+
+func scalar(key string, v any) (string, error) {
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("%s: expected a single value, not a list", key)
+	}
+	return s, nil
+}
+
+// parseFrontmatter reads the subset of YAML droid files use: "key: value"
+// lines, inline lists ["a", "b"] and block lists of "- item" lines.
+func parseFrontmatter(front string) (map[string]any, error) {
+	fields := map[string]any{}
+	var listKey string
+	for i, line := range strings.Split(front, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if item, ok := strings.CutPrefix(trimmed, "- "); ok && listKey != "" {
+			list, _ := fields[listKey].([]string)
+			fields[listKey] = append(list, unquote(item))
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || strings.HasPrefix(line, " ") {
+			return nil, fmt.Errorf("frontmatter line %d: expected key: value", i+2)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		listKey = ""
+		switch {
+		case value == "":
+			listKey = key
+			fields[key] = []string(nil)
+		case strings.HasPrefix(value, "["):
+			inner, ok := strings.CutSuffix(value, "]")
+			if !ok {
+				return nil, fmt.Errorf("frontmatter line %d: list is not closed with ]", i+2)
+			}
+			list := []string{}
+			for _, item := range splitList(inner[1:]) {
+				list = append(list, unquote(item))
+			}
+			fields[key] = list
+		default:
+			fields[key] = unquote(value)
+		}
+	}
+	return fields, nil
+}
+
+// splitList splits an inline list at commas outside quotes.
+func splitList(s string) []string {
+	var out []string
+	var cur strings.Builder
+	var quote rune
+	for _, c := range s {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+			cur.WriteRune(c)
+		case c == '"' || c == '\'':
+			quote = c
+			cur.WriteRune(c)
+		case c == ',':
+			out = append(out, strings.TrimSpace(cur.String()))
+			cur.Reset()
+		default:
+			cur.WriteRune(c)
+		}
+	}
+	if last := strings.TrimSpace(cur.String()); last != "" {
+		out = append(out, last)
+	}
+	return out
+}
+
+func unquote(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		if u, err := strconv.Unquote(s); err == nil {
+			return u
+		}
+	}
+	if len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'' {
+		return strings.ReplaceAll(s[1:len(s)-1], "''", "'")
+	}
+	if i := strings.Index(s, " #"); i >= 0 {
+		s = strings.TrimSpace(s[:i])
+	}
+	return s
+}
+
+// Render returns the file contents for d.
+func Render(d Droid) []byte {
+	var sb strings.Builder
+	sb.WriteString("---\n")
+	sb.WriteString("name: " + d.Name + "\n")
+	sb.WriteString("description: " + yamlString(d.Description) + "\n")
+	model := d.Model
+	if model == "" {
+		model = Inherit
+	}
+	sb.WriteString("model: " + yamlString(model) + "\n")
+	switch {
+	case d.ToolsField != "":
+		sb.WriteString("tools: " + d.ToolsField + "\n")
+	case d.Tools != nil:
+		quoted := make([]string, len(d.Tools))
+		for i, t := range d.Tools {
+			quoted[i] = strconv.Quote(t)
+		}
+		sb.WriteString("tools: [" + strings.Join(quoted, ", ") + "]\n")
+	}
+	sb.WriteString("---\n\n")
+	sb.WriteString(strings.TrimSpace(d.Prompt) + "\n")
+	return []byte(sb.String())
+}
+
+// yamlString quotes s when a plain YAML scalar would change its meaning. The
+// double-quoted form escapes line breaks, so a pasted multi-line value stays
+// on its frontmatter line.
+func yamlString(s string) string {
+	if s == "" || strings.ContainsAny(s, ":#\"'{}[],&*!|>%@`\n\r\t") || strings.TrimSpace(s) != s {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// ─── Locations ────────────────────────────────────────────────────────────────
+
+// PersonalDir returns the droids directory in the user's Factory folder.
+func PersonalDir(home string) string {
+	return scope.Dir(Personal, home, "", "droids")
+}
+
+// ProjectDir returns the droids directory of the project containing dir
+// (see scope.ProjectRoot).
+func ProjectDir(dir, home string) string {
+	return scope.Dir(Project, home, dir, "droids")
+}
+
+// Dir returns the directory of s.
+func Dir(s Scope, home, cwd string) string {
+	return scope.Dir(s, home, cwd, "droids")
+}
+
+// Load reads the droids of both scopes, personal first, each sorted by
+// name, and checks them against models (see Check). Files that fail to
+// parse are listed with their problem. A project droid with the same name
+// as a personal one overrides it, which is noted on the personal one.
+func Load(home, cwd string, models map[string]bool) ([]Droid, error) {
+	var out []Droid
+	var errs []error
+	for _, s := range []Scope{Personal, Project} {
+		dir := Dir(s, home, cwd)
+		if s == Project && dir == PersonalDir(home) {
+			continue // run from the home directory: same folder
+		}
+		paths, err := filepath.Glob(filepath.Join(dir, "*.md"))
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		sort.Strings(paths)
+		for _, path := range paths {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			d, err := Parse(data)
+			d.Path, d.Scope = path, s
+			if err != nil {
+				d.Problems = append(d.Problems, err.Error())
+			} else {
+				d.Check(models)
+			}
+			out = append(out, d)
+		}
+	}
+
+	project := map[string]bool{}
+	for _, d := range out {
+		if d.Scope == Project {
+			project[d.Name] = true
+		}
+	}
+	for i, d := range out {
+		if d.Scope == Personal && project[d.Name] {
+			out[i].Problems = append(out[i].Problems, "overridden by the project droid of the same name")
+		}
+	}
+	return out, errors.Join(errs...)
+}
+
+// UsingModels loads the droids under home and cwd and returns those whose
+// model is one of ids, e.g. custom model IDs that were renumbered.
+func UsingModels(home, cwd string, ids []string) ([]Droid, error) {
+	list, err := Load(home, cwd, nil)
+	var out []Droid
+	for _, d := range list {
+		if slices.Contains(ids, d.Model) {
+			out = append(out, d)
+		}
+	}
+	return out, err
+}
+
+// Create writes d as a new file in dir and returns its path. It refuses to
+// replace an existing droid.
+func Create(dir string, d Droid) (string, error) {
+	if err := ValidName(d.Name); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, d.Name+".md")
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		if errors.Is(err, os.ErrExist) {
+			return "", fmt.Errorf("%s already exists", path)
+		}
+		return "", err
+	}
+	if _, err := f.Write(Render(d)); err != nil {
+		f.Close()
+		return "", err
+	}
+	return path, f.Close()
+}
@@ -0,0 +1,268 @@
+// Package hooks reads and edits the hooks section of Droid's settings.json:
+// shell commands Droid runs on events such as a tool call or the end of a
+// response.
+package hooks
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Event is a point in a Droid session where hooks run.
+type Event struct {
+	Name        string
+	Desc        string
+	MatcherHint string // what the matcher selects; "" when the event has no matcher
+}
+
+// Events are the hook events Droid supports, in the order they are listed.
+var Events = []Event{
+	{Name: "PreToolUse", Desc: "Before a tool runs · exit 2 blocks the call", MatcherHint: "tool name pattern, e.g. Edit|Create"},
+	{Name: "PostToolUse", Desc: "After a tool completes", MatcherHint: "tool name pattern, e.g. Execute"},
+	{Name: "UserPromptSubmit", Desc: "When you submit a prompt · exit 2 rejects it"},
+	{Name: "Notification", Desc: "When Droid notifies you, e.g. while waiting for input"},
+	{Name: "Stop", Desc: "When Droid finishes responding · exit 2 keeps it going"},
+	{Name: "SubagentStop", Desc: "When a subagent finishes"},
+	{Name: "PreCompact", Desc: "Before the conversation is compacted", MatcherHint: "manual or auto"},
+	{Name: "SessionStart", Desc: "When a session starts or resumes", MatcherHint: "startup, resume or clear"},
+	{Name: "SessionEnd", Desc: "When a session ends"},
+}
+
+// LookupEvent returns the event with the given name.
+func LookupEvent(name string) (Event, bool) {
+	for _, e := range Events {
+		if e.Name == name {
+			return e, true
+		}
+	}
+	return Event{}, false
+}
+
+// Hook is one command of the hooks section, flattened out of its event and
+// matcher group.
+type Hook struct {
+	Event   string
+	Matcher string // tool name regexp; "" matches everything
+	Command string
+	Timeout int // seconds; 0 uses Droid's default
+	Enabled bool
+
+	entry map[string]any // the decoded command object, so unknown fields survive a save
+}
+
+// Validate reports problems that would stop Droid from running the hook.
+func (h Hook) Validate() error {
+	if strings.TrimSpace(h.Command) == "" {
+		return fmt.Errorf("the command is empty")
+	}
+	if h.Timeout < 0 {
+		return fmt.Errorf("the timeout cannot be negative")
+	}
+	if _, err := regexp.Compile(h.Matcher); err != nil {
+		return fmt.Errorf("matcher %q is not a valid pattern", h.Matcher)
+	}
+	return nil
+}
+
+// Parse flattens the hooks section of settings.json and the disabled hooks
+// file into one list, grouped by event in Events order. Disabled hooks go
+// back to the position they were saved at. An error means the section is
+// not in the expected shape and must not be rewritten.
+func Parse(section, disabled any) ([]Hook, error) {
+	byEvent := map[string][]Hook{}
+	if section != nil {
+		events, ok := section.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("hooks: expected an object of events")
+		}
+		for event, v := range events {
+			groups, ok := v.([]any)
+			if !ok {
+				return nil, fmt.Errorf("hooks.%s: expected a list of matcher groups", event)
+			}
+			for i, g := range groups {
+				group, ok := g.(map[string]any)
+				if !ok {
+					return nil, fmt.Errorf("hooks.%s[%d]: expected an object", event, i)
+				}
+				matcher, _ := group["matcher"].(string)
+				cmds, ok := group["hooks"].([]any)
+				if !ok {
+					return nil, fmt.Errorf("hooks.%s[%d].hooks: expected a list", event, i)
+				}
+				for j, c := range cmds {
+					h, err := decodeHook(event, matcher, c)
+					if err != nil {
+						return nil, fmt.Errorf("hooks.%s[%d].hooks[%d]: %w", event, i, j, err)
+					}
+					h.Enabled = true
+					byEvent[event] = append(byEvent[event], h)
+				}
+			}
+		}
+	}
+
+	if disabled != nil {
+		records, ok := disabled.([]any)
+		if !ok {
+			return nil, fmt.Errorf("disabled hooks: expected a list")
+		}
+		for i, r := range records {
+			rec, ok := r.(map[string]any)
+			if !ok {
+				return nil, fmt.Errorf("disabled hooks[%d]: expected an object", i)
+			}
+			event, _ := rec["event"].(string)
+			matcher, _ := rec["matcher"].(string)
+			h, err := decodeHook(event, matcher, rec["hook"])
+			if err != nil || event == "" {
+				return nil, fmt.Errorf("disabled hooks[%d]: not a hook", i)
+			}
+			pos, _ := rec["position"].(float64)
+			list := byEvent[event]
+			at := min(max(int(pos), 0), len(list))
+			byEvent[event] = slices.Insert(list, at, h)
+		}
+	}
+
+	var out []Hook
+	for _, name := range eventOrder(byEvent) {
+		out = append(out, byEvent[name]...)
+	}
+	return out, nil
+}
+
+func decodeHook(event, matcher string, v any) (Hook, error) {
+	entry, ok := v.(map[string]any)
+	if !ok {
+		return Hook{}, fmt.Errorf("expected an object")
+	}
+	cmd, ok := entry["command"].(string)
+	if !ok {
+		return Hook{}, fmt.Errorf("missing command")
+	}
+	timeout, _ := entry["timeout"].(float64)
+	return Hook{
+		Event:   event,
+		Matcher: matcher,
+		Command: cmd,
+		Timeout: int(timeout),
+		entry:   entry,
+	}, nil
+}
+
+// eventOrder lists the events of byEvent that have hooks: known events in
+// Events order, then unknown ones alphabetically.
+func eventOrder(byEvent map[string][]Hook) []string {
+	var out []string
+	for _, e := range Events {
+		if len(byEvent[e.Name]) > 0 {
+			out = append(out, e.Name)
+		}
+	}
+	var unknown []string
+	for name, list := range byEvent {
+		if _, ok := LookupEvent(name); !ok && len(list) > 0 {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	return append(out, unknown...)
+}
+
+// Build turns the list back into a hooks section and the records of the
+// disabled hooks file. Consecutive enabled hooks of an event that share a
+// matcher are written as one matcher group.
+func Build(list []Hook) (map[string]any, []any) {
+	section := map[string]any{}
+	var disabled []any
+	pos := map[string]int{}
+	for _, h := range list {
+		entry := h.encode()
+		if !h.Enabled {
+			rec := map[string]any{"event": h.Event, "position": pos[h.Event], "hook": entry}
+			if h.Matcher != "" {
+				rec["matcher"] = h.Matcher
+			}
+			disabled = append(disabled, rec)
+			pos[h.Event]++
+			continue
+		}
+		pos[h.Event]++
+
+		groups, _ := section[h.Event].([]any)
+		if n := len(groups); n > 0 {
+			last := groups[n-1].(map[string]any)
+			if m, _ := last["matcher"].(string); m == h.Matcher {
+				last["hooks"] = append(last["hooks"].([]any), entry)
+				continue
+			}
+		}
+		group := map[string]any{"hooks": []any{entry}}
+		if h.Matcher != "" {
+			group["matcher"] = h.Matcher
+		}
+		section[h.Event] = append(groups, group)
+	}
+	return section, disabled
+}
+
+func (h Hook) encode() map[string]any {
+	entry := maps.Clone(h.entry)
+	if entry == nil {
+		entry = map[string]any{}
+	}
+	if _, ok := entry["type"]; !ok {
+		entry["type"] = "command"
+	}
+	entry["command"] = h.Command
+	if h.Timeout > 0 {
+		entry["timeout"] = h.Timeout
+	} else {
+		delete(entry, "timeout")
+	}
+	return entry
+}
+
+// Add inserts h after the last hook of its event, keeping the list grouped
+// by event, and returns the new list and h's index.
+func Add(list []Hook, h Hook) ([]Hook, int) {
+	at := -1
+	for i, e := range list {
+		if e.Event == h.Event {
+			at = i + 1
+		}
+	}
+	if at < 0 {
+		// First hook of this event: place it by Events order.
+		byEvent := map[string][]Hook{}
+		for _, e := range list {
+			byEvent[e.Event] = append(byEvent[e.Event], e)
+		}
+		byEvent[h.Event] = []Hook{h}
+		out := make([]Hook, 0, len(list)+1)
+		for _, name := range eventOrder(byEvent) {
+			if name == h.Event {
+				at = len(out)
+			}
+			out = append(out, byEvent[name]...)
+		}
+		return out, at
+	}
+	return slices.Insert(slices.Clone(list), at, h), at
+}
+
+// Move swaps the hook at i with its neighbour in direction delta (-1 or +1)
+// within the same event, and returns the hook's new index.
+func Move(list []Hook, i, delta int) int {
+	j := i + delta
+	if j < 0 || j >= len(list) || list[j].Event != list[i].Event {
+		return i
+	}
+	list[i], list[j] = list[j], list[i]
+	return j
+}
@@ -0,0 +1,186 @@
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/kaan-escober/wrench/internal/policy"
+)
+
+// DefaultTimeout is how long Droid lets a hook run when it sets no timeout.
+const DefaultTimeout = 60 * time.Second
+
+// builtins are shell words that need no executable on PATH.
+var builtins = map[string]bool{
+	".": true, ":": true, "[": true, "[[": true, "alias": true, "builtin": true,
+	"case": true, "cd": true, "command": true, "echo": true, "eval": true,
+	"exec": true, "exit": true, "export": true, "false": true, "for": true,
+	"function": true, "local": true, "printf": true, "read": true,
+	"return": true, "select": true, "set": true, "shift": true,
+	"source": true, "test": true, "time": true, "trap": true, "true": true,
+	"type": true, "unset": true, "wait": true,
+}
+
+// Missing returns the programs command starts that cannot be found: names
+// not on PATH, and paths that do not exist or are not executable. Programs
+// named through a variable such as $FACTORY_PROJECT_DIR are not checked.
+func Missing(command string) []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, seg := range policy.Split(command) {
+		for _, prog := range policy.Programs(seg.Text) {
+			if seen[prog] || builtins[prog] || strings.ContainsAny(prog, "$`") {
+				continue
+			}
+			seen[prog] = true
+			if !found(prog) {
+				out = append(out, prog)
+			}
+		}
+	}
+	return out
+}
+
+func found(prog string) bool {
+	if !strings.Contains(prog, "/") {
+		_, err := exec.LookPath(prog)
+		return err == nil
+	}
+	if rest, ok := strings.CutPrefix(prog, "~/"); ok {
+		home, _ := os.UserHomeDir()
+		prog = filepath.Join(home, rest)
+	}
+	info, err := os.Stat(prog)
+	return err == nil && !info.IsDir() && info.Mode()&0o111 != 0
+}
+
+var plainName = regexp.MustCompile(`^[A-Za-z_]+$`)
+
+// toolName picks the tool a sample event reports: the first alternative of
+// a plain matcher such as "Edit|Create", or Edit.
+func toolName(matcher string) string {
+	first, _, _ := strings.Cut(matcher, "|")
+	if plainName.MatchString(first) {
+		return first
+	}
+	return "Edit"
+}
+
+// SampleInput returns the JSON Droid would send on stdin for h's event,
+// with made-up values rooted at dir.
+func SampleInput(h Hook, dir string) []byte {
+	in := map[string]any{
+		"session_id":      "wrench-sample",
+		"transcript_path": filepath.Join(dir, ".factory", "sample-transcript.jsonl"),
+		"cwd":             dir,
+		"hook_event_name": h.Event,
+	}
+	switch h.Event {
+	case "PreToolUse", "PostToolUse":
+		tool := toolName(h.Matcher)
+		file := filepath.Join(dir, "example.txt")
+		var input map[string]any
+		switch tool {
+		case "Execute":
+			input = map[string]any{"command": "echo hello"}
+		case "Read", "LS":
+			input = map[string]any{"file_path": file}
+		case "Create":
+			input = map[string]any{"file_path": file, "content": "hello\n"}
+		default:
+			input = map[string]any{"file_path": file, "old_str": "hello", "new_str": "hello, world"}
+		}
+		in["tool_name"] = tool
+		in["tool_input"] = input
+		if h.Event == "PostToolUse" {
+			in["tool_response"] = map[string]any{"success": true}
+		}
+	case "UserPromptSubmit":
+		in["prompt"] = "Write a test for the parser"
+	case "Notification":
+		in["message"] = "Droid is waiting for your input"
+	case "Stop", "SubagentStop":
+		in["stop_hook_active"] = false
+	case "PreCompact":
+		in["trigger"] = "manual"
+		in["custom_instructions"] = ""
+	case "SessionStart":
+		in["source"] = "startup"
+	case "SessionEnd":
+		in["reason"] = "exit"
+	}
+	data, _ := json.MarshalIndent(in, "", "  ")
+	return data
+}
+
+// Result is the outcome of running a hook once.
+type Result struct {
+	Stdout   string
+	Stderr   string
+	ExitCode int
+	Duration time.Duration
+	TimedOut bool
+	Err      error // the command could not be started
+}
+
+// Meaning explains the exit status the way Droid treats it.
+func (r Result) Meaning() string {
+	switch {
+	case r.Err != nil:
+		return "could not start: " + r.Err.Error()
+	case r.TimedOut:
+		return "timed out · Droid stops waiting and carries on"
+	case r.ExitCode == 0:
+		return "success · stdout is shown in the transcript"
+	case r.ExitCode == 2:
+		return "blocking error · stderr is fed back to Droid"
+	}
+	return "non-blocking error · stderr is shown to you and Droid carries on"
+}
+
+// Run executes h once with sh -c in dir, passing input on stdin like Droid
+// does, and waits for it up to its timeout.
+func Run(ctx context.Context, h Hook, input []byte, dir string) Result {
+	timeout := DefaultTimeout
+	if h.Timeout > 0 {
+		timeout = time.Duration(h.Timeout) * time.Second
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", h.Command)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "FACTORY_PROJECT_DIR="+dir)
+	cmd.Stdin = bytes.NewReader(input)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	cmd.WaitDelay = time.Second
+
+	start := time.Now()
+	err := cmd.Run()
+	res := Result{
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+		Duration: time.Since(start),
+	}
+	var exit *exec.ExitError
+	switch {
+	case ctx.Err() == context.DeadlineExceeded:
+		res.TimedOut = true
+		res.ExitCode = -1
+	case errors.As(err, &exit):
+		res.ExitCode = exit.ExitCode()
+	case err != nil:
+		res.Err = err
+		res.ExitCode = -1
+	}
+	return res
+}
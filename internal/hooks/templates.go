@@ -0,0 +1,58 @@
+package hooks
+
+import "runtime"
+
+// Template is a ready-made hook for a common task.
+type Template struct {
+	Name        string
+	Description string
+	Hook        Hook
+}
+
+// editTools matches Droid's file-writing tools.
+const editTools = "Create|Edit|MultiEdit"
+
+// Templates returns the built-in templates. The notification command
+// depends on the operating system.
+func Templates() []Template {
+	notify := `notify-send Droid "$(jq -r '.message // "Droid needs your attention"')"`
+	switch runtime.GOOS {
+	case "darwin":
+		notify = `osascript -e "display notification \"$(jq -r '.message // "Droid needs your attention"')\" with title \"Droid\""`
+	case "android":
+		notify = `termux-notification --title Droid --content "$(jq -r '.message // "Droid needs your attention"')"`
+	}
+
+	return []Template{
+		{
+			Name:        "Format on edit",
+			Description: "Run Prettier on every file Droid creates or edits",
+			Hook: Hook{
+				Event:   "PostToolUse",
+				Matcher: editTools,
+				Command: `jq -r '.tool_input.file_path // empty' | xargs -r prettier --write --ignore-unknown`,
+				Timeout: 30,
+				Enabled: true,
+			},
+		},
+		{
+			Name:        "Desktop notification",
+			Description: "Show a system notification when Droid needs your input",
+			Hook: Hook{
+				Event:   "Notification",
+				Command: notify,
+				Enabled: true,
+			},
+		},
+		{
+			Name:        "Block edits to protected paths",
+			Description: "Stop edits to .env files, .git/ and lock files · exit 2 tells Droid why",
+			Hook: Hook{
+				Event:   "PreToolUse",
+				Matcher: editTools,
+				Command: `jq -r '.tool_input.file_path // empty' | grep -qE '(^|/)(\.env(\..*)?|\.git/.*|[^/]*\.lock|package-lock\.json)$' && { echo "Blocked: this path is protected by a hook" >&2; exit 2; } || exit 0`,
+				Enabled: true,
+			},
+		},
+	}
+}
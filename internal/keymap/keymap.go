@@ -0,0 +1,394 @@
+// Package keymap holds wrench's key bindings: the defaults, the presets and
+// the user's keys.json, and the check that no screen ends up with one key
+// doing two things.
+package keymap
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+
+	"github.com/kaan-escober/wrench/internal/config"
+)
+
+// Action names what a binding does. The names are the keys of the
+// "bindings" object in keys.json.
+type Action string
+
+const (
+	Up          Action = "up"
+	Down        Action = "down"
+	Left        Action = "left"
+	Right       Action = "right"
+	PageUp      Action = "page-up"
+	PageDown    Action = "page-down"
+	Top         Action = "top"
+	Bottom      Action = "bottom"
+	MoveUp      Action = "move-up"   // reorder the entry under the cursor
+	MoveDown    Action = "move-down" // reorder the entry under the cursor
+	Select      Action = "select"
+	Back        Action = "back"
+	Toggle      Action = "toggle"
+	Save        Action = "save" // multi-line editors, where enter adds a line
+	Yes         Action = "yes"
+	No          Action = "no"
+	Quit        Action = "quit"
+	Help        Action = "help"
+	Search      Action = "search"
+	PrevResult  Action = "prev-result" // the search results, while typing
+	NextResult  Action = "next-result"
+	Add         Action = "add"
+	Edit        Action = "edit"
+	Delete      Action = "delete"
+	Reset       Action = "reset"
+	Run         Action = "run"
+	Reload      Action = "reload"
+	Test        Action = "test"
+	Template    Action = "template"
+	Rename      Action = "rename"
+	Chmod       Action = "chmod"
+	Duplicate   Action = "duplicate"
+	Column      Action = "column" // the other column of the command editor
+	Paste       Action = "paste"
+	Filter      Action = "filter"
+	Sort        Action = "sort"
+	Dedupe      Action = "dedupe"
+	UnsetColumn Action = "unset-column"
+	PresetList  Action = "presets"
+	Suggest     Action = "suggest"
+	Transfer    Action = "transfer" // move entries to the other column
+	SelectAll   Action = "select-all"
+	Clear       Action = "clear"
+	Retry       Action = "retry"
+)
+
+// Map is a complete set of bindings.
+type Map struct {
+	Up, Down, Left, Right               key.Binding
+	PageUp, PageDown, Top, Bottom       key.Binding
+	MoveUp, MoveDown                    key.Binding
+	Select, Back, Toggle, Save, Yes, No key.Binding
+	Quit, Help, Search                  key.Binding
+	PrevResult, NextResult              key.Binding
+	Add, Edit, Delete, Reset            key.Binding
+	Run, Reload, Test, Template         key.Binding
+	Rename, Chmod, Duplicate            key.Binding
+	Column, Paste, Filter, Sort, Dedupe key.Binding
+	UnsetColumn, Presets, Suggest       key.Binding
+	Transfer, SelectAll, Clear, Retry   key.Binding
+}
+
+// table returns every binding by action, in the order they are listed.
+func (m *Map) table() []struct {
+	action  Action
+	binding *key.Binding
+} {
+	type entry = struct {
+		action  Action
+		binding *key.Binding
+	}
+	return []entry{
+		{Up, &m.Up}, {Down, &m.Down}, {Left, &m.Left}, {Right, &m.Right},
+		{PageUp, &m.PageUp}, {PageDown, &m.PageDown}, {Top, &m.Top}, {Bottom, &m.Bottom},
+		{MoveUp, &m.MoveUp}, {MoveDown, &m.MoveDown},
+		{Select, &m.Select}, {Back, &m.Back}, {Toggle, &m.Toggle}, {Save, &m.Save},
+		{Yes, &m.Yes}, {No, &m.No}, {Quit, &m.Quit}, {Help, &m.Help}, {Search, &m.Search},
+		{PrevResult, &m.PrevResult}, {NextResult, &m.NextResult},
+		{Add, &m.Add}, {Edit, &m.Edit}, {Delete, &m.Delete}, {Reset, &m.Reset},
+		{Run, &m.Run}, {Reload, &m.Reload}, {Test, &m.Test}, {Template, &m.Template},
+		{Rename, &m.Rename}, {Chmod, &m.Chmod}, {Duplicate, &m.Duplicate},
+		{Column, &m.Column}, {Paste, &m.Paste}, {Filter, &m.Filter}, {Sort, &m.Sort},
+		{Dedupe, &m.Dedupe}, {UnsetColumn, &m.UnsetColumn}, {PresetList, &m.Presets},
+		{Suggest, &m.Suggest}, {Transfer, &m.Transfer}, {SelectAll, &m.SelectAll},
+		{Clear, &m.Clear}, {Retry, &m.Retry},
+	}
+}
+
+// Binding returns the binding of an action.
+func (m Map) Binding(a Action) key.Binding {
+	for _, e := range m.table() {
+		if e.action == a {
+			return *e.binding
+		}
+	}
+	return key.Binding{}
+}
+
+// Keys returns the keys of an action.
+func (m Map) Keys(a Action) []string {
+	return m.Binding(a).Keys()
+}
+
+// Actions lists every action in the order keys.json documents them.
+func Actions() []Action {
+	var m Map
+	var out []Action
+	for _, e := range m.table() {
+		out = append(out, e.action)
+	}
+	return out
+}
+
+var defaults = map[Action][]string{
+	Up: {"up", "k"}, Down: {"down", "j"}, Left: {"left", "h"}, Right: {"right", "l"},
+	PageUp: {"pgup"}, PageDown: {"pgdown"}, Top: {"home", "g"}, Bottom: {"end", "G"},
+	MoveUp: {"shift+up", "K"}, MoveDown: {"shift+down", "J"},
+	Select: {"enter"}, Back: {"esc"}, Toggle: {" "}, Save: {"ctrl+s"},
+	Yes: {"y", "Y"}, No: {"n", "N"}, Quit: {"ctrl+c"}, Help: {"?"}, Search: {"/", "ctrl+k"},
+	PrevResult: {"up", "ctrl+p"}, NextResult: {"down", "ctrl+n"},
+	Add: {"a"}, Edit: {"e"}, Delete: {"d", "delete", "backspace"}, Reset: {"r", "delete"},
+	Run: {"r"}, Reload: {"r"}, Test: {"t"}, Template: {"t"},
+	Rename: {"R"}, Chmod: {"x"}, Duplicate: {"c"},
+	Column: {"tab"}, Paste: {"A"}, Filter: {"/"}, Sort: {"S"}, Dedupe: {"u"},
+	UnsetColumn: {"R"}, PresetList: {"p"}, Suggest: {"s"},
+	Transfer: {"m"}, SelectAll: {"a"}, Clear: {"ctrl+u"}, Retry: {"ctrl+r"},
+}
+
+// Presets are named changes to the defaults a keys.json can start from.
+var Presets = map[string]map[Action][]string{
+	"default": {},
+	// Emacs-style movement next to the defaults.
+	"emacs": {
+		Up: {"up", "ctrl+p"}, Down: {"down", "ctrl+n"},
+		Left: {"left", "ctrl+b"}, Right: {"right", "ctrl+f"},
+		PageUp: {"pgup", "alt+v"}, PageDown: {"pgdown", "ctrl+v"},
+		Top: {"home", "alt+<"}, Bottom: {"end", "alt+>"},
+		Back: {"esc", "ctrl+g"},
+	},
+	// Only arrows and named keys move; letters never do.
+	"arrows": {
+		Up: {"up"}, Down: {"down"}, Left: {"left"}, Right: {"right"},
+		Top: {"home"}, Bottom: {"end"},
+		MoveUp: {"shift+up"}, MoveDown: {"shift+down"},
+	},
+}
+
+// Default returns the built-in bindings.
+func Default() Map {
+	m, _ := build(nil, "default")
+	return m
+}
+
+func build(overrides map[Action][]string, preset string) (Map, error) {
+	changes, ok := Presets[preset]
+	if !ok {
+		return Default(), fmt.Errorf("unknown preset %q (one of %s)", preset, strings.Join(presetNames(), ", "))
+	}
+	var m Map
+	for _, e := range m.table() {
+		keys := defaults[e.action]
+		if k, ok := changes[e.action]; ok {
+			keys = k
+		}
+		if k, ok := overrides[e.action]; ok {
+			keys = k
+		}
+		*e.binding = key.NewBinding(key.WithKeys(keys...), key.WithHelp(Display(keys), string(e.action)))
+	}
+	return m, nil
+}
+
+func presetNames() []string {
+	var names []string
+	for n := range Presets {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ─── keys.json ────────────────────────────────────────────────────────────────
+
+// Path returns the user's remapping file.
+func Path() string {
+	return filepath.Join(config.WrenchDir(), "keys.json")
+}
+
+// File is the format of keys.json.
+type File struct {
+	Preset   string              `json:"preset,omitempty"`
+	Bindings map[string][]string `json:"bindings,omitempty"`
+}
+
+// Load returns the bindings of keys.json, or the defaults when there is no
+// such file. A file that cannot be read, names unknown actions or leaves a
+// screen with conflicting keys is reported, and the defaults are used.
+func Load() (Map, error) {
+	return load(Path())
+}
+
+func load(path string) (Map, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Default(), nil
+		}
+		return Default(), err
+	}
+	m, err := Parse(data)
+	if err != nil {
+		return Default(), fmt.Errorf("%s: %w · using the default keys", path, err)
+	}
+	return m, nil
+}
+
+// Parse reads a keys.json and checks the result for conflicts.
+func Parse(data []byte) (Map, error) {
+	var f File
+	if err := json.Unmarshal(data, &f); err != nil {
+		return Default(), err
+	}
+	overrides := map[Action][]string{}
+	for name, keys := range f.Bindings {
+		a := Action(name)
+		if _, ok := defaults[a]; !ok {
+			return Default(), fmt.Errorf("unknown action %q", name)
+		}
+		if len(keys) == 0 {
+			return Default(), fmt.Errorf("%s: needs at least one key", name)
+		}
+		overrides[a] = keys
+	}
+	m, err := build(overrides, orDefault(f.Preset))
+	if err != nil {
+		return m, err
+	}
+	if conflicts := m.Conflicts(); len(conflicts) > 0 {
+		return Default(), &ConflictError{Conflicts: conflicts}
+	}
+	return m, nil
+}
+
+func orDefault(preset string) string {
+	if preset == "" {
+		return "default"
+	}
+	return preset
+}
+
+// ─── Conflicts ────────────────────────────────────────────────────────────────
+
+// Conflict is a key that two actions of one screen share, or a key that
+// would be typed into a screen's input field instead of acting.
+type Conflict struct {
+	Screen  string
+	Key     string
+	Actions []Action // one action when the key is taken by typing
+}
+
+// ConflictError is returned for a keys.json whose bindings conflict.
+type ConflictError struct {
+	Conflicts []Conflict
+}
+
+func (e *ConflictError) Error() string {
+	msg := e.Conflicts[0].String()
+	if n := len(e.Conflicts) - 1; n > 0 {
+		msg += fmt.Sprintf(" (and %d more · wrench keys lists them)", n)
+	}
+	return msg
+}
+
+func (c Conflict) String() string {
+	if len(c.Actions) == 1 {
+		return fmt.Sprintf("%s: %s is typed into the input, so it cannot be bound to %s", c.Screen, Display([]string{c.Key}), c.Actions[0])
+	}
+	names := make([]string, len(c.Actions))
+	for i, a := range c.Actions {
+		names[i] = string(a)
+	}
+	return fmt.Sprintf("%s: %s is bound to both %s", c.Screen, Display([]string{c.Key}), strings.Join(names, " and "))
+}
+
+// Conflicts checks every screen: within a screen no key may serve two
+// actions, and screens with an input field cannot use keys that type text.
+func (m Map) Conflicts() []Conflict {
+	var out []Conflict
+	for _, s := range Screens {
+		owner := map[string]Action{}
+		for _, a := range s.actions() {
+			for _, k := range m.Keys(a) {
+				if s.Typing && typesText(k) {
+					out = append(out, Conflict{Screen: s.Title, Key: k, Actions: []Action{a}})
+					continue
+				}
+				if prev, ok := owner[k]; ok && prev != a {
+					out = append(out, Conflict{Screen: s.Title, Key: k, Actions: []Action{prev, a}})
+					continue
+				}
+				owner[k] = a
+			}
+		}
+	}
+	return out
+}
+
+// typesText reports whether a key inserts a character into an input field.
+func typesText(k string) bool {
+	return len([]rune(k)) == 1
+}
+
+// ─── Display ──────────────────────────────────────────────────────────────────
+
+var keyNames = map[string]string{
+	"up": "↑", "down": "↓", "left": "←", "right": "→",
+	"shift+up": "shift+↑", "shift+down": "shift+↓",
+	" ": "space", "delete": "del",
+}
+
+// Display formats keys for help text: "↑, k", "space".
+func Display(keys []string) string {
+	out := make([]string, len(keys))
+	for i, k := range keys {
+		out[i] = orName(k)
+	}
+	return strings.Join(out, ", ")
+}
+
+func orName(k string) string {
+	if name, ok := keyNames[k]; ok {
+		return name
+	}
+	return k
+}
+
+// First formats the first key of each action for a footer: arrows with
+// the same modifiers run together ("↑↓", "shift+↑↓"), others take a slash
+// ("K/J").
+func (m Map) First(actions ...Action) string {
+	var names []string
+	for _, a := range actions {
+		if keys := m.Keys(a); len(keys) > 0 {
+			names = append(names, orName(keys[0]))
+		}
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	mods, _ := cutArrow(names[0])
+	arrows := ""
+	for _, n := range names {
+		prefix, arrow := cutArrow(n)
+		if arrow == "" || prefix != mods {
+			return strings.Join(names, "/")
+		}
+		arrows += arrow
+	}
+	return mods + arrows
+}
+
+// cutArrow splits "shift+↑" into "shift+" and "↑". The arrow is empty when
+// the key is not an arrow.
+func cutArrow(name string) (mods, arrow string) {
+	for _, a := range []string{"↑", "↓", "←", "→"} {
+		if prefix, ok := strings.CutSuffix(name, a); ok {
+			return prefix, a
+		}
+	}
+	return name, ""
+}
@@ -0,0 +1,160 @@
+package keymap
+
+import "strings"
+
+// Hint is one entry of a screen's help: the actions, and what they do there.
+type Hint struct {
+	Actions []Action
+	Desc    string
+	More    bool // only in the ? overlay; the footer has no room for it
+}
+
+// Screen lists the actions one screen responds to, in the order its help
+// shows them. Typing screens have an input field that takes every key that
+// types text.
+type Screen struct {
+	Name   string
+	Title  string
+	Typing bool
+	Hints  []Hint
+}
+
+func h(desc string, actions ...Action) Hint {
+	return Hint{Actions: actions, Desc: desc}
+}
+
+// more is a hint for the ? overlay only.
+func more(desc string, actions ...Action) Hint {
+	return Hint{Actions: actions, Desc: desc, More: true}
+}
+
+var nav = h("navigate", Up, Down)
+
+// Screens are all screens of the TUI.
+var Screens = []Screen{
+	{Name: "menu", Title: "Main menu", Hints: []Hint{nav, h("open", Select), h("search", Search), h("keys", Help), h("quit", Quit)}},
+	{Name: "palette", Title: "Search", Typing: true, Hints: []Hint{h("navigate", PrevResult, NextResult), h("open", Select), h("back", Back)}},
+	{Name: "category", Title: "Settings list", Hints: []Hint{nav, h("edit", Select), h("reset to default", Reset), h("search", Search), h("back", Back)}},
+	{Name: "pick", Title: "Picker", Hints: []Hint{nav, h("select", Select), h("back", Back)}},
+	{Name: "setting-input", Title: "Setting value", Typing: true, Hints: []Hint{h("confirm (empty resets to default)", Select), h("back", Back)}},
+	{Name: "input", Title: "Text field", Typing: true, Hints: []Hint{h("confirm", Select), h("cancel", Back)}},
+
+	{Name: "commands", Title: "Command editor", Hints: []Hint{
+		nav, h("switch", Column), h("add", Add), h("edit", Edit), h("move", Transfer), h("mark", Toggle),
+		h("delete", Delete), h("paste", Paste), h("filter", Filter), h("sort", Sort), h("dedupe", Dedupe),
+		h("unset column", UnsetColumn), h("test", Test), h("presets", PresetList), h("suggest", Suggest),
+		h("save & back", Back),
+		more("edit", Select), more("page up / down", PageUp, PageDown), more("first / last", Top, Bottom),
+	}},
+	{Name: "command-paste", Title: "Paste entries", Typing: true, Hints: []Hint{h("add entries", Save), h("cancel", Back)}},
+	{Name: "command-filter", Title: "Filter", Typing: true, Hints: []Hint{h("keep filter", Select), h("clear", Back)}},
+	{Name: "command-test", Title: "Policy tester", Typing: true, Hints: []Hint{h("clear", Clear), h("back to lists", Back), more("back to lists", Select)}},
+	{Name: "presets", Title: "Presets", Hints: []Hint{nav, h("preview merge", Select), h("back", Back)}},
+	{Name: "preset-merge", Title: "Preset merge", Hints: []Hint{h("scroll", Up, Down), h("apply", Select), h("back to presets", Back), more("apply", Yes), more("back to presets", No)}},
+	{Name: "suggest", Title: "Suggestions", Hints: []Hint{nav, h("toggle", Toggle), h("select all", SelectAll), h("add to allowlist", Select), h("back", Back)}},
+
+	{Name: "hooks", Title: "Hooks", Hints: []Hint{
+		nav, h("edit", Select), h("add", Add), h("template", Template), h("on/off", Toggle),
+		h("move", MoveUp, MoveDown), h("run", Run), h("delete", Delete), h("back", Back), more("edit", Edit),
+	}},
+	{Name: "hook-form", Title: "Hook", Hints: []Hint{nav, h("edit / select", Select), h("cancel", Back)}},
+	{Name: "hook-run", Title: "Hook test run", Hints: []Hint{h("run", Select), h("back", Back), more("run", Run)}},
+	{Name: "confirm", Title: "Delete", Hints: []Hint{h("delete", Yes), h("keep", No), more("keep", Back)}},
+	{Name: "readonly", Title: "Unreadable section", Hints: []Hint{h("back", Back)}},
+
+	{Name: "slash", Title: "Slash commands", Hints: []Hint{
+		nav, h("edit", Select), h("new", Add), h("rename", Rename), h("chmod +x", Chmod), h("delete", Delete),
+		h("back", Back), more("edit", Edit), more("reload", Reload),
+	}},
+	{Name: "mcp", Title: "MCP servers", Hints: []Hint{
+		nav, h("edit", Select), h("add", Add), h("on/off", Toggle), h("test", Test), h("delete", Delete),
+		h("back", Back), more("edit", Edit),
+	}},
+	{Name: "mcp-form", Title: "MCP server", Hints: []Hint{nav, h("edit / toggle", Select), h("cancel", Back)}},
+	{Name: "mcp-lines", Title: "Lines", Typing: true, Hints: []Hint{h("done", Save), h("cancel", Back)}},
+	{Name: "mcp-test", Title: "MCP test launch", Hints: []Hint{h("run again", Run), h("back", Back), more("back", Select)}},
+
+	{Name: "droids", Title: "Droids", Hints: []Hint{nav, h("new", Add), h("duplicate", Duplicate), h("reload", Reload), h("back", Back)}},
+	{Name: "droid-pick", Title: "Droid wizard", Hints: []Hint{nav, h("next", Select), h("back", Back)}},
+	{Name: "droid-tools", Title: "Droid tools", Hints: []Hint{nav, h("toggle", Toggle), h("next", Select), h("back", Back)}},
+	{Name: "droid-input", Title: "Droid name and description", Typing: true, Hints: []Hint{h("next", Select), h("back", Back)}},
+	{Name: "droid-prompt", Title: "Droid prompt", Typing: true, Hints: []Hint{h("next", Save), h("back", Back)}},
+	{Name: "droid-confirm", Title: "Droid summary", Hints: []Hint{h("create", Select), h("back", Back)}},
+
+	{Name: "other", Title: "Advanced", Hints: []Hint{nav, h("edit / open / toggle", Select), h("delete", Delete), h("back", Back), more("edit", Edit, Toggle)}},
+	{Name: "tree", Title: "JSON tree", Hints: []Hint{
+		nav, h("collapse / expand", Left, Right), h("edit / toggle", Select), h("add", Add), h("delete", Delete),
+		h("save & back", Back), more("edit", Edit, Toggle),
+	}},
+
+	{Name: "byok-providers", Title: "Providers", Hints: []Hint{nav, h("reorder", MoveUp, MoveDown), h("select", Select), h("back", Back)}},
+	{Name: "byok-group", Title: "Provider group", Hints: []Hint{nav, h("reorder", MoveUp, MoveDown), h("select", Select), h("delete model", Delete), h("back", Back)}},
+	{Name: "byok-model", Title: "Model", Hints: []Hint{nav, h("edit", Select), h("delete model", Delete), h("back", Back)}},
+	{Name: "byok-models", Title: "Fetched models", Hints: []Hint{h("toggle", Toggle), nav, h("confirm", Select), h("back", Back), more("fetch a cut-off list again", Retry)}},
+	{Name: "byok-manual", Title: "Model ID", Typing: true, Hints: []Hint{h("confirm", Select), h("retry", Retry), h("back", Back)}},
+	{Name: "byok-fetching", Title: "Fetching models", Hints: []Hint{h("cancel", Back)}},
+}
+
+// ScreenNamed returns the screen with the given name.
+func ScreenNamed(name string) (Screen, bool) {
+	for _, s := range Screens {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return Screen{}, false
+}
+
+// actions lists every action the screen responds to: its hints, quit, and
+// help where ? is not typed.
+func (s Screen) actions() []Action {
+	var out []Action
+	seen := map[Action]bool{}
+	add := func(a Action) {
+		if !seen[a] {
+			seen[a] = true
+			out = append(out, a)
+		}
+	}
+	for _, hint := range s.Hints {
+		for _, a := range hint.Actions {
+			add(a)
+		}
+	}
+	add(Quit)
+	if !s.Typing {
+		add(Help)
+	}
+	return out
+}
+
+// Footer renders the screen's hints for the footer line, e.g.
+// "↑↓ navigate  enter · open".
+func (m Map) Footer(s Screen) string {
+	var parts []string
+	for _, hint := range s.Hints {
+		if hint.More {
+			continue
+		}
+		keys := m.First(hint.Actions...)
+		if hint.Desc == "navigate" {
+			parts = append(parts, keys+" "+hint.Desc)
+			continue
+		}
+		parts = append(parts, keys+" · "+hint.Desc)
+	}
+	return strings.Join(parts, "  ")
+}
+
+// HelpRows returns every key of every hint of the screen, for the ? overlay.
+func (m Map) HelpRows(s Screen) [][2]string {
+	var rows [][2]string
+	for _, hint := range s.Hints {
+		var keys []string
+		for _, a := range hint.Actions {
+			keys = append(keys, Display(m.Keys(a)))
+		}
+		rows = append(rows, [2]string{strings.Join(keys, "  "), hint.Desc})
+	}
+	return rows
+}
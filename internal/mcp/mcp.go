@@ -0,0 +1,249 @@
+// Package mcp reads and edits the MCP servers Droid connects to, and can
+// launch a stdio server once to check that it answers.
+package mcp
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"os"
+	"os/exec"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Transports a server can use.
+const (
+	Stdio = "stdio" // a local process spoken to over stdin and stdout
+	HTTP  = "http"  // a remote server reached by URL
+)
+
+// Remote reports whether s is reached by URL: HTTP or another remote
+// transport such as sse, which is kept as written.
+func (s Server) Remote() bool {
+	return s.Type != Stdio
+}
+
+// Server is one entry of the mcpServers object.
+type Server struct {
+	Name     string
+	Type     string // Stdio, HTTP or another remote transport
+	Command  string
+	Args     []string
+	Env      map[string]string
+	URL      string
+	Headers  map[string]string
+	Disabled bool
+
+	entry map[string]any // the decoded object, so unknown fields survive a save
+}
+
+// Parse reads the mcpServers object into a list sorted by name. An error
+// means the object is not in the expected shape and must not be rewritten.
+func Parse(section any) ([]Server, error) {
+	if section == nil {
+		return nil, nil
+	}
+	servers, ok := section.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("mcpServers: expected an object of servers")
+	}
+	var out []Server
+	for name, v := range servers {
+		entry, ok := v.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("mcpServers.%s: expected an object", name)
+		}
+		s := Server{Name: name, entry: entry}
+		s.Command, _ = entry["command"].(string)
+		s.URL, _ = entry["url"].(string)
+		s.Disabled, _ = entry["disabled"].(bool)
+		s.Type, _ = entry["type"].(string)
+		if s.Type == "" {
+			s.Type = Stdio
+			if s.URL != "" {
+				s.Type = HTTP
+			}
+		}
+		var err error
+		if s.Args, err = stringList(entry["args"]); err != nil {
+			return nil, fmt.Errorf("mcpServers.%s.args: %w", name, err)
+		}
+		if s.Env, err = stringMap(entry["env"]); err != nil {
+			return nil, fmt.Errorf("mcpServers.%s.env: %w", name, err)
+		}
+		if s.Headers, err = stringMap(entry["headers"]); err != nil {
+			return nil, fmt.Errorf("mcpServers.%s.headers: %w", name, err)
+		}
+		out = append(out, s)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+func stringList(v any) ([]string, error) {
+	if v == nil {
+		return nil, nil
+	}
+	items, ok := v.([]any)
+	if !ok {
+		return nil, fmt.Errorf("expected a list of strings")
+	}
+	out := make([]string, len(items))
+	for i, item := range items {
+		if out[i], ok = item.(string); !ok {
+			return nil, fmt.Errorf("expected a list of strings")
+		}
+	}
+	return out, nil
+}
+
+func stringMap(v any) (map[string]string, error) {
+	if v == nil {
+		return nil, nil
+	}
+	obj, ok := v.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("expected an object of strings")
+	}
+	out := make(map[string]string, len(obj))
+	for k, item := range obj {
+		if out[k], ok = item.(string); !ok {
+			return nil, fmt.Errorf("%s: expected a string", k)
+		}
+	}
+	return out, nil
+}
+
+// Build turns the list back into an mcpServers object. Fields of the other
+// transport are dropped; fields wrench does not know are kept.
+func Build(list []Server) map[string]any {
+	out := make(map[string]any, len(list))
+	for _, s := range list {
+		out[s.Name] = s.encode()
+	}
+	return out
+}
+
+func (s Server) encode() map[string]any {
+	entry := maps.Clone(s.entry)
+	if entry == nil {
+		entry = map[string]any{}
+	}
+	for _, k := range []string{"command", "args", "env", "url", "headers"} {
+		delete(entry, k)
+	}
+	entry["type"] = s.Type
+	if s.Remote() {
+		entry["url"] = s.URL
+		if len(s.Headers) > 0 {
+			entry["headers"] = s.Headers
+		}
+	} else {
+		entry["command"] = s.Command
+		if len(s.Args) > 0 {
+			entry["args"] = s.Args
+		}
+		if len(s.Env) > 0 {
+			entry["env"] = s.Env
+		}
+	}
+	if s.Disabled {
+		entry["disabled"] = true
+	} else {
+		delete(entry, "disabled")
+	}
+	return entry
+}
+
+// Validate reports problems that would stop Droid from starting the server.
+func (s Server) Validate() error {
+	if strings.TrimSpace(s.Name) == "" {
+		return fmt.Errorf("the name is empty")
+	}
+	if s.Remote() {
+		return ValidURL(s.URL)
+	}
+	if strings.TrimSpace(s.Command) == "" {
+		return fmt.Errorf("the command is empty")
+	}
+	return nil
+}
+
+// ValidURL reports why raw cannot be used as the URL of an HTTP server, or
+// nil. ${VAR} references are expanded first; a URL built from unset
+// variables is not checked.
+func ValidURL(raw string) error {
+	missing := map[string]bool{}
+	u, err := url.Parse(Expand(raw, missing))
+	if len(missing) > 0 {
+		return nil
+	}
+	if raw == "" || err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%q is not an http:// or https:// URL", raw)
+	}
+	return nil
+}
+
+// Clone returns a copy of s whose lists and maps can be changed freely.
+func (s Server) Clone() Server {
+	s.Args = slices.Clone(s.Args)
+	s.Env = maps.Clone(s.Env)
+	s.Headers = maps.Clone(s.Headers)
+	return s
+}
+
+// ─── ${ENV} references ────────────────────────────────────────────────────────
+
+var envRef = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// Expand replaces ${NAME} references with environment variables, like
+// Droid does when it starts the server. Names of unset variables are added
+// to missing when it is not nil.
+func Expand(s string, missing map[string]bool) string {
+	return envRef.ReplaceAllStringFunc(s, func(ref string) string {
+		name := envRef.FindStringSubmatch(ref)[1]
+		v, ok := os.LookupEnv(name)
+		if !ok && missing != nil {
+			missing[name] = true
+		}
+		return v
+	})
+}
+
+// Problems lists what would go wrong when Droid starts s: ${NAME}
+// references to unset variables and, for a stdio server, a command that
+// cannot be found.
+func (s Server) Problems() []string {
+	missing := map[string]bool{}
+	var fields []string
+	if s.Remote() {
+		fields = append(fields, s.URL)
+		for _, v := range s.Headers {
+			fields = append(fields, v)
+		}
+	} else {
+		fields = append(fields, s.Command)
+		fields = append(fields, s.Args...)
+		for _, v := range s.Env {
+			fields = append(fields, v)
+		}
+	}
+	for _, f := range fields {
+		Expand(f, missing)
+	}
+
+	var out []string
+	if len(missing) > 0 {
+		names := slices.Sorted(maps.Keys(missing))
+		out = append(out, "unset: ${"+strings.Join(names, "}, ${")+"}")
+	}
+	if !s.Remote() && s.Command != "" {
+		if _, err := exec.LookPath(Expand(s.Command, nil)); err != nil {
+			out = append(out, "not found: "+s.Command)
+		}
+	}
+	return out
+}
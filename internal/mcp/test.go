@@ -0,0 +1,246 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TestTimeout bounds a launch test: starting the server, the handshake and
+// listing its tools.
+const TestTimeout = 20 * time.Second
+
+// ProtocolVersion is the MCP revision wrench asks for in the handshake.
+const ProtocolVersion = "2025-06-18"
+
+// Tool is a tool a server exposes.
+type Tool struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// Report is the outcome of a launch test.
+type Report struct {
+	ServerName    string // serverInfo of the initialize result
+	ServerVersion string
+	Protocol      string // protocol version the server agreed to
+	Tools         []Tool
+	Stderr        string // what the server logged, last lines only
+	Duration      time.Duration
+	Err           error // the step that failed; nil when the test passed
+}
+
+// stderrLimit caps how much of the server's stderr a report keeps.
+const stderrLimit = 4096
+
+// Test starts a stdio server the way Droid does, performs the initialize
+// handshake, lists its tools and stops it again.
+func Test(ctx context.Context, s Server, dir string) Report {
+	start := time.Now()
+	var rep Report
+	if s.Remote() {
+		rep.Err = errors.New("the launch test runs stdio servers; " + s.Name + " is reached over " + s.Type)
+		return rep
+	}
+	ctx, cancel := context.WithTimeout(ctx, TestTimeout)
+	defer cancel()
+
+	args := make([]string, len(s.Args))
+	for i, a := range s.Args {
+		args[i] = Expand(a, nil)
+	}
+	cmd := exec.CommandContext(ctx, Expand(s.Command, nil), args...)
+	cmd.Dir = dir
+	cmd.Env = os.Environ()
+	for k, v := range s.Env {
+		cmd.Env = append(cmd.Env, k+"="+Expand(v, nil))
+	}
+	stderr := &tailBuffer{limit: stderrLimit}
+	cmd.Stderr = stderr
+	cmd.WaitDelay = time.Second
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		rep.Err = err
+		return rep
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		rep.Err = err
+		return rep
+	}
+	if err := cmd.Start(); err != nil {
+		rep.Err = fmt.Errorf("could not start: %w", err)
+		return rep
+	}
+
+	lines := readLines(stdout)
+	c := &client{in: stdin, lines: lines}
+	rep.Err = c.run(ctx, &rep)
+	go func() {
+		for range lines {
+		}
+	}()
+	stdin.Close()
+	cancel()
+	cmd.Wait()
+	rep.Stderr = stderr.String()
+	rep.Duration = time.Since(start)
+	return rep
+}
+
+// client speaks JSON-RPC to the server: one message per line.
+type client struct {
+	in     io.Writer
+	lines  <-chan []byte
+	nextID int
+}
+
+var errExited = errors.New("the server exited")
+
+func (c *client) run(ctx context.Context, rep *Report) error {
+	var init struct {
+		ProtocolVersion string `json:"protocolVersion"`
+		ServerInfo      struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"serverInfo"`
+	}
+	err := c.call(ctx, "initialize", map[string]any{
+		"protocolVersion": ProtocolVersion,
+		"capabilities":    map[string]any{},
+		"clientInfo":      map[string]any{"name": "wrench", "version": "1"},
+	}, &init)
+	if err != nil {
+		return fmt.Errorf("initialize: %w", err)
+	}
+	rep.Protocol = init.ProtocolVersion
+	rep.ServerName = init.ServerInfo.Name
+	rep.ServerVersion = init.ServerInfo.Version
+	if err := c.send(map[string]any{"jsonrpc": "2.0", "method": "notifications/initialized"}); err != nil {
+		return fmt.Errorf("initialized: %w", err)
+	}
+
+	cursor := ""
+	for {
+		params := map[string]any{}
+		if cursor != "" {
+			params["cursor"] = cursor
+		}
+		var page struct {
+			Tools      []Tool `json:"tools"`
+			NextCursor string `json:"nextCursor"`
+		}
+		if err := c.call(ctx, "tools/list", params, &page); err != nil {
+			return fmt.Errorf("tools/list: %w", err)
+		}
+		rep.Tools = append(rep.Tools, page.Tools...)
+		if page.NextCursor == "" || page.NextCursor == cursor {
+			return nil
+		}
+		cursor = page.NextCursor
+	}
+}
+
+func (c *client) send(msg map[string]any) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = c.in.Write(append(data, '\n'))
+	return err
+}
+
+// call sends a request and waits for its response, skipping notifications
+// and requests from the server.
+func (c *client) call(ctx context.Context, method string, params any, result any) error {
+	c.nextID++
+	id := c.nextID
+	if err := c.send(map[string]any{"jsonrpc": "2.0", "id": id, "method": method, "params": params}); err != nil {
+		return err
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("no answer within %s", TestTimeout)
+		case line, ok := <-c.lines:
+			if !ok {
+				return errExited
+			}
+			var resp struct {
+				ID     json.RawMessage `json:"id"` // a number or a string in requests from the server
+				Method string          `json:"method"`
+				Result json.RawMessage `json:"result"`
+				Error  *struct {
+					Code    int    `json:"code"`
+					Message string `json:"message"`
+				} `json:"error"`
+			}
+			if err := json.Unmarshal(line, &resp); err != nil {
+				return fmt.Errorf("not JSON-RPC on stdout: %q", truncate(string(line), 80))
+			}
+			if resp.Method != "" || string(resp.ID) != strconv.Itoa(id) {
+				continue
+			}
+			if resp.Error != nil {
+				return fmt.Errorf("error %d: %s", resp.Error.Code, resp.Error.Message)
+			}
+			return json.Unmarshal(resp.Result, result)
+		}
+	}
+}
+
+// readLines delivers the non-empty lines of r until it is closed.
+func readLines(r io.Reader) <-chan []byte {
+	ch := make(chan []byte)
+	go func() {
+		defer close(ch)
+		sc := bufio.NewScanner(r)
+		sc.Buffer(make([]byte, 64*1024), 16*1024*1024)
+		for sc.Scan() {
+			if line := strings.TrimSpace(sc.Text()); line != "" {
+				ch <- []byte(line)
+			}
+		}
+	}()
+	return ch
+}
+
+func truncate(s string, n int) string {
+	rs := []rune(s)
+	if len(rs) <= n {
+		return s
+	}
+	return string(rs[:n]) + "…"
+}
+
+// tailBuffer keeps the last limit bytes written to it.
+type tailBuffer struct {
+	mu    sync.Mutex
+	limit int
+	buf   []byte
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.buf = append(t.buf, p...)
+	if over := len(t.buf) - t.limit; over > 0 {
+		t.buf = t.buf[over:]
+	}
+	return len(p), nil
+}
+
+func (t *tailBuffer) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return string(t.buf)
+}
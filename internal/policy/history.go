@@ -0,0 +1,223 @@
+package policy
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Suggestion is a candidate allowlist entry derived from shell history.
+type Suggestion struct {
+	Entry string // normalised prefix such as "go test" or "npm run lint"
+	Count int    // number of history commands it covers
+}
+
+// HistoryFiles returns the bash, zsh and fish history files that exist for
+// the user, honouring $HISTFILE.
+func HistoryFiles(home string) []string {
+	candidates := []string{
+		os.Getenv("HISTFILE"),
+		filepath.Join(home, ".bash_history"),
+		filepath.Join(home, ".zsh_history"),
+		filepath.Join(home, ".zhistory"),
+		filepath.Join(home, ".local", "share", "fish", "fish_history"),
+	}
+	if xdg := os.Getenv("XDG_DATA_HOME"); xdg != "" {
+		candidates = append(candidates, filepath.Join(xdg, "fish", "fish_history"))
+	}
+	var out []string
+	seen := map[string]bool{}
+	for _, p := range candidates {
+		if p == "" || seen[p] {
+			continue
+		}
+		seen[p] = true
+		if fi, err := os.Stat(p); err == nil && fi.Mode().IsRegular() {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// ReadHistory returns the commands recorded in a bash, zsh (plain or
+// extended) or fish history file. Files are only read locally.
+func ReadHistory(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	fish := strings.HasSuffix(path, "fish_history")
+	var cmds []string
+	var cont strings.Builder // zsh/bash continuation lines ending in "\"
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := sc.Text()
+		if fish {
+			if rest, ok := strings.CutPrefix(line, "- cmd: "); ok {
+				cmds = append(cmds, strings.ReplaceAll(strings.ReplaceAll(rest, `\n`, "\n"), `\\`, `\`))
+			}
+			continue
+		}
+		if cont.Len() == 0 {
+			if strings.HasPrefix(line, "#") {
+				continue // bash HISTTIMEFORMAT timestamp
+			}
+			// zsh extended history: ": 1700000000:0;command"
+			if strings.HasPrefix(line, ": ") {
+				if i := strings.IndexByte(line, ';'); i > 0 {
+					line = line[i+1:]
+				}
+			}
+		}
+		if strings.HasSuffix(line, `\`) {
+			cont.WriteString(strings.TrimSuffix(line, `\`) + "\n")
+			continue
+		}
+		cont.WriteString(line)
+		cmds = append(cmds, cont.String())
+		cont.Reset()
+	}
+	return cmds, sc.Err()
+}
+
+// subcommandTools take a subcommand as their second word; for everything else
+// the program name alone is suggested.
+var subcommandTools = map[string]bool{
+	"git": true, "go": true, "npm": true, "pnpm": true, "yarn": true, "bun": true,
+	"deno": true, "npx": true, "cargo": true, "rustup": true, "docker": true,
+	"podman": true, "kubectl": true, "helm": true, "pip": true, "pip3": true,
+	"uv": true, "poetry": true, "pipenv": true, "conda": true, "brew": true,
+	"gh": true, "terraform": true, "make": true, "just": true, "mvn": true,
+	"gradle": true, "dotnet": true, "swift": true, "zig": true, "mix": true,
+	"bundle": true, "rails": true, "rake": true, "composer": true, "flutter": true,
+	"dart": true, "nix": true, "tox": true, "ruff": true, "hatch": true,
+}
+
+// runnerCommands take the name of a script or tool as a third word worth
+// keeping: "npm run lint", "uv run pytest".
+var runnerCommands = map[string]bool{
+	"npm run": true, "pnpm run": true, "yarn run": true, "bun run": true,
+	"deno task": true, "uv run": true, "poetry run": true, "pipenv run": true,
+	"go tool": true, "cargo make": true, "python -m": true, "python3 -m": true,
+}
+
+// dangerousPrograms are never suggested, whatever their arguments.
+var dangerousPrograms = map[string]bool{
+	"rm": true, "sudo": true, "doas": true, "su": true, "dd": true, "mkfs": true,
+	"shred": true, "chmod": true, "chown": true, "kill": true, "killall": true,
+	"pkill": true, "shutdown": true, "reboot": true, "halt": true, "curl": true,
+	"wget": true, "ssh": true, "scp": true, "rsync": true, "eval": true,
+	"exec": true, "sh": true, "bash": true, "zsh": true, "fish": true,
+	"source": true, ".": true, "xargs": true, "env": true, "mv": true,
+}
+
+// Dangerous are deny patterns that suggestions are always checked against, in
+// addition to the user's denylist.
+func Dangerous() []string {
+	for _, p := range Builtin {
+		if p.Name == "Destructive operations" {
+			return p.Deny
+		}
+	}
+	return nil
+}
+
+// Normalize reduces a simple command to the prefix worth allowlisting, or ""
+// when it should not be suggested.
+func Normalize(text string) string {
+	words := stripPrefixWords(Fields(text))
+	if len(words) == 0 || strings.ContainsAny(words[0], "/$=`") {
+		return ""
+	}
+	prog := words[0]
+	if dangerousPrograms[prog] {
+		return ""
+	}
+	if len(words) > 2 && runnerCommands[prog+" "+words[1]] && isSubcommand(words[2]) {
+		return strings.Join(words[:3], " ")
+	}
+	if subcommandTools[prog] && len(words) > 1 && isSubcommand(words[1]) {
+		return prog + " " + words[1]
+	}
+	return prog
+}
+
+// isSubcommand reports whether w looks like a subcommand rather than a flag,
+// path or value.
+func isSubcommand(w string) bool {
+	if w == "" || w[0] == '-' {
+		return false
+	}
+	for _, c := range w {
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == ':') {
+			return false
+		}
+	}
+	return true
+}
+
+// SuggestFromHistory reads every history file under home and ranks the
+// commands found in them.
+func SuggestFromHistory(home string, allow, deny []string, limit int) ([]Suggestion, []string, error) {
+	files := HistoryFiles(home)
+	var history []string
+	for _, p := range files {
+		cmds, err := ReadHistory(p)
+		if err != nil {
+			return nil, files, err
+		}
+		history = append(history, cmds...)
+	}
+	return Suggest(history, allow, deny, limit), files, nil
+}
+
+// Suggest ranks allowlist candidates from history commands by frequency.
+// Commands matching the denylist or Dangerous are dropped, as are prefixes
+// the allowlist already covers and prefixes that would also allow a blocked
+// pattern, which Score rates as destructive. limit <= 0 returns every candidate.
+func Suggest(history, allow, deny []string, limit int) []Suggestion {
+	blocked := append(append([]string{}, deny...), Dangerous()...)
+	counts := map[string]int{}
+	for _, line := range history {
+		if _, ok := matchWholeLine(line, blocked); ok {
+			continue
+		}
+		for _, seg := range Split(line) {
+			if _, ok := matchDeny(seg.Text, blocked); ok {
+				continue
+			}
+			if e := Normalize(seg.Text); e != "" {
+				counts[e]++
+			}
+		}
+	}
+
+	var out []Suggestion
+	for e, n := range counts {
+		if _, ok := matchAllow(e, allow); ok {
+			continue
+		}
+		if _, ok := matchDeny(e, blocked); ok {
+			continue
+		}
+		if _, ok := alsoAllows(e, blocked); ok {
+			continue
+		}
+		out = append(out, Suggestion{Entry: e, Count: n})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Entry < out[j].Entry
+	})
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out
+}
@@ -0,0 +1,262 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FindingKind classifies a lint finding.
+type FindingKind int
+
+const (
+	KindConflict  FindingKind = iota // entry is in both columns
+	KindDuplicate                    // entry repeats in the same column
+	KindRisky                        // allow entry is dangerously broad
+	KindShadowed                     // allow entry can never apply because a deny entry covers it
+)
+
+// Label returns the short badge text for the kind.
+func (k FindingKind) Label() string {
+	switch k {
+	case KindConflict:
+		return "BOTH"
+	case KindDuplicate:
+		return "DUP"
+	case KindRisky:
+		return "RISKY"
+	case KindShadowed:
+		return "SHADOWED"
+	}
+	return "?"
+}
+
+// Finding is one problem with a list entry.
+type Finding struct {
+	Kind    FindingKind
+	Column  Column
+	Entry   string
+	Message string
+}
+
+// IsError reports whether the finding makes the policy contradictory rather
+// than merely risky.
+func (f Finding) IsError() bool {
+	return f.Kind == KindConflict
+}
+
+// Risk scores how much an allow entry lets Droid do without asking, from 0
+// (one narrow command) to 10 (anything at all).
+type Risk struct {
+	Entry   string
+	Score   int
+	Reasons []string
+}
+
+// riskyThreshold is the score from which an allow entry is reported as risky.
+const riskyThreshold = 7
+
+// Report is the result of linting a policy.
+type Report struct {
+	Findings []Finding
+	Risks    []Risk // one per distinct allow entry, in list order
+}
+
+// For returns the findings for one entry.
+func (r Report) For(col Column, entry string) []Finding {
+	var out []Finding
+	for _, f := range r.Findings {
+		if f.Column == col && f.Entry == entry {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// RiskOf returns the risk of an allow entry.
+func (r Report) RiskOf(entry string) (Risk, bool) {
+	for _, rk := range r.Risks {
+		if rk.Entry == entry {
+			return rk, true
+		}
+	}
+	return Risk{}, false
+}
+
+// Lint analyses the lists for contradictions, duplicates, shadowed allow
+// entries and risky allow entries.
+func Lint(allow, deny []string) Report {
+	var r Report
+	for _, col := range []Column{AllowColumn, DenyColumn} {
+		list, other := allow, deny
+		if col == DenyColumn {
+			list, other = deny, allow
+		}
+		seen := map[string]bool{}
+		for _, e := range list {
+			key := strings.Join(Fields(e), " ")
+			if seen[key] {
+				r.Findings = append(r.Findings, Finding{KindDuplicate, col, e, "listed more than once"})
+				continue
+			}
+			seen[key] = true
+			if col == AllowColumn && containsNormalized(other, key) {
+				r.Findings = append(r.Findings, Finding{KindConflict, AllowColumn, e, "also in the denylist — deny wins, so this entry has no effect"})
+				r.Findings = append(r.Findings, Finding{KindConflict, DenyColumn, e, "also in the allowlist"})
+			}
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, e := range allow {
+		if seen[e] {
+			continue
+		}
+		seen[e] = true
+		if !containsNormalized(deny, strings.Join(Fields(e), " ")) {
+			if d, ok := matchDeny(e, deny); ok {
+				r.Findings = append(r.Findings, Finding{KindShadowed, AllowColumn, e,
+					fmt.Sprintf("shadowed by deny entry %q — every command it allows is denied", d)})
+			}
+		}
+		rk := Score(e)
+		r.Risks = append(r.Risks, rk)
+		if rk.Score >= riskyThreshold {
+			r.Findings = append(r.Findings, Finding{KindRisky, AllowColumn, e,
+				fmt.Sprintf("risk %d/10: %s", rk.Score, strings.Join(rk.Reasons, "; "))})
+		}
+	}
+	return r
+}
+
+func containsNormalized(list []string, key string) bool {
+	for _, v := range list {
+		if strings.Join(Fields(v), " ") == key {
+			return true
+		}
+	}
+	return false
+}
+
+// programRisk rates allow entries that name only a program, or a program whose
+// arguments make it dangerous however they are narrowed.
+var programRisk = map[string]struct {
+	score  int
+	reason string
+}{
+	"sudo":    {10, "runs any command as root"},
+	"doas":    {10, "runs any command as root"},
+	"su":      {10, "switches to another user, usually root"},
+	"sh":      {9, "runs arbitrary shell scripts"},
+	"bash":    {9, "runs arbitrary shell scripts"},
+	"zsh":     {9, "runs arbitrary shell scripts"},
+	"fish":    {9, "runs arbitrary shell scripts"},
+	"eval":    {9, "runs arbitrary shell code"},
+	"exec":    {8, "runs its arguments as a command"},
+	"env":     {8, "runs its arguments as a command"},
+	"xargs":   {8, "runs its arguments as a command"},
+	"dd":      {9, "can overwrite disks"},
+	"mkfs":    {9, "formats file systems"},
+	"rm":      {8, "deletes files, including recursively"},
+	"curl":    {7, "downloads and uploads data, can feed a shell"},
+	"wget":    {7, "downloads data that can feed a shell"},
+	"ssh":     {7, "runs commands on other machines"},
+	"scp":     {6, "copies files to and from other machines"},
+	"python":  {7, "runs arbitrary code"},
+	"python3": {7, "runs arbitrary code"},
+	"node":    {7, "runs arbitrary code"},
+	"perl":    {7, "runs arbitrary code"},
+	"ruby":    {7, "runs arbitrary code"},
+	"docker":  {7, "controls containers, including privileged ones"},
+	"kubectl": {7, "changes cluster state"},
+	"git":     {6, "includes push --force, reset --hard and clean"},
+	"chmod":   {6, "changes permissions"},
+	"chown":   {6, "changes ownership"},
+	"mv":      {5, "can overwrite files"},
+	"find":    {4, "can run -exec and -delete"},
+	"npm":     {5, "runs package install scripts"},
+	"npx":     {6, "downloads and runs packages"},
+	"pip":     {5, "runs package install scripts"},
+	"make":    {4, "runs arbitrary Makefile recipes"},
+}
+
+// Score rates an allow entry. The rating considers wildcards, interpreters
+// and wrappers, programs allowed with any arguments, and overlap with the
+// destructive-operations patterns.
+func Score(entry string) Risk {
+	rk := Risk{Entry: entry}
+	add := func(score int, reason string) {
+		if score > rk.Score {
+			rk.Score = score
+		}
+		rk.Reasons = append(rk.Reasons, reason)
+	}
+
+	words := Fields(entry)
+	switch {
+	case len(words) == 0:
+		return rk
+	case strings.HasPrefix(words[0], "*") || words[0] == "?":
+		add(10, "matches every command")
+		return rk
+	}
+
+	prog := words[0]
+	if pr, ok := programRisk[prog]; ok {
+		score := pr.score
+		if len(words) > 1 && !wrappers[prog] && !isInterpreterFlag(prog, words[1]) {
+			score -= 3 // narrowed by a subcommand or argument
+		}
+		if score >= 5 {
+			add(score, prog+" "+pr.reason)
+		}
+	}
+	if len(words) > 1 && isInterpreterFlag(prog, words[1]) {
+		add(9, "runs the script passed on the command line")
+	}
+	if len(words) == 1 && rk.Score == 0 {
+		add(3, "allows "+prog+" with any arguments")
+	}
+	if strings.ContainsAny(entry, "*?") {
+		add(min(rk.Score+2, 10), "contains wildcards")
+	}
+	if d, ok := alsoAllows(entry, Dangerous()); ok {
+		add(9, fmt.Sprintf("also allows the destructive pattern %q", d))
+	}
+	if rk.Score == 0 {
+		rk.Score = 1
+	}
+	return rk
+}
+
+// alsoAllows returns the first deny pattern that allowing entry would also
+// allow: one that matches entry itself, or a longer one that entry matches as
+// a prefix. Pipelines such as "curl * | sh" are skipped: allowing one side
+// still leaves the other to ask.
+func alsoAllows(entry string, deny []string) (string, bool) {
+	words := Fields(entry)
+	norm := strings.Join(words, " ")
+	if norm == "" {
+		return "", false
+	}
+	for _, d := range deny {
+		if strings.ContainsAny(d, "|;&") {
+			continue
+		}
+		if MatchLoose(d, norm) || (len(words) < len(Fields(d)) && Match(norm, strings.Join(Fields(d), " "))) {
+			return d, true
+		}
+	}
+	return "", false
+}
+
+func isInterpreterFlag(prog, arg string) bool {
+	switch prog {
+	case "sh", "bash", "zsh", "dash", "ksh", "fish":
+		return arg == "-c"
+	case "python", "python3", "perl", "ruby":
+		return arg == "-c" || arg == "-e"
+	case "node", "deno", "bun":
+		return arg == "-e" || arg == "--eval"
+	}
+	return false
+}
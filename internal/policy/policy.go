@@ -0,0 +1,240 @@
+// Package policy evaluates shell commands against Droid's commandAllowlist
+// and commandDenylist.
+package policy
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Verdict is the outcome of checking a command.
+type Verdict int
+
+const (
+	Ask   Verdict = iota // no entry matched — Droid's autonomy level decides
+	Allow                // every command in the line is allowlisted
+	Deny                 // at least one command in the line is denylisted
+)
+
+func (v Verdict) String() string {
+	switch v {
+	case Allow:
+		return "allowed"
+	case Deny:
+		return "denied"
+	}
+	return "ask"
+}
+
+// SegmentResult is the verdict for one simple command.
+type SegmentResult struct {
+	Segment
+	Verdict Verdict
+	Entry   string // list entry that matched, "" for Ask
+}
+
+// Result is the verdict for a full command line.
+type Result struct {
+	Command  string
+	Verdict  Verdict
+	Entry    string // entry that decided the verdict; "" for Ask
+	Segments []SegmentResult
+	Hidden   bool     // a chain hides a denied command behind an allowed prefix
+	Warnings []string // human-readable notes, including the Hidden explanation
+}
+
+// Check evaluates cmd under the given lists. Each simple command is checked
+// on its own: a deny match anywhere denies the whole line, the line is only
+// allowed when every command is allowed, and anything else asks.
+//
+// Deny entries are matched loosely (see MatchLoose) against the command with
+// wrappers such as sudo, env and xargs removed as well as against the command
+// as written, so "sudo rm -rf /" is caught by a "rm -rf" entry. Deny entries
+// that contain shell operators, such as "curl * | sh", are also matched
+// against the whole line. Allow entries only match the command as written.
+func Check(cmd string, allow, deny []string) Result {
+	res := Result{Command: cmd, Verdict: Allow}
+	segs := Split(cmd)
+	if len(segs) == 0 {
+		res.Verdict = Ask
+		return res
+	}
+
+	if e, ok := matchWholeLine(cmd, deny); ok {
+		res.Verdict, res.Entry = Deny, e
+	}
+
+	for _, seg := range segs {
+		sr := SegmentResult{Segment: seg}
+		if e, ok := matchDeny(seg.Text, deny); ok {
+			sr.Verdict, sr.Entry = Deny, e
+		} else if e, ok := matchAllow(seg.Text, allow); ok {
+			sr.Verdict, sr.Entry = Allow, e
+		}
+		res.Segments = append(res.Segments, sr)
+
+		switch {
+		case sr.Verdict == Deny && res.Verdict != Deny:
+			res.Verdict, res.Entry = Deny, sr.Entry
+		case sr.Verdict == Ask && res.Verdict == Allow:
+			res.Verdict, res.Entry = Ask, ""
+		}
+	}
+	if res.Verdict == Deny && res.Entry != "" && !anyDenied(res.Segments) {
+		// Denied by a whole-line entry: mark the segments it spans.
+		for i := range res.Segments {
+			res.Segments[i].Verdict, res.Segments[i].Entry = Deny, res.Entry
+		}
+	}
+	if res.Verdict == Allow {
+		res.Entry = res.Segments[0].Entry
+	}
+
+	// A naive prefix check on the whole line would accept it because it starts
+	// with an allowed command. Flag what that prefix is hiding.
+	first := res.Segments[0]
+	prefixAllowed := first.Verdict == Allow
+	if e, ok := matchAllow(cmd, allow); ok && !prefixAllowed {
+		prefixAllowed, first.Entry = true, e
+	}
+	if prefixAllowed && len(res.Segments) > 1 {
+		for _, sr := range res.Segments[1:] {
+			switch sr.Verdict {
+			case Deny:
+				res.Hidden = true
+				res.Warnings = append(res.Warnings, fmt.Sprintf(
+					"%s hides denied %q behind allowed prefix %q", describeOp(sr.Segment), sr.Text, first.Entry))
+			case Ask:
+				res.Warnings = append(res.Warnings, fmt.Sprintf(
+					"%s runs %q, which no entry allows, after allowed prefix %q", describeOp(sr.Segment), sr.Text, first.Entry))
+			}
+		}
+	}
+	return res
+}
+
+// matchWholeLine matches the deny entries that contain shell operators against
+// the full command line.
+func matchWholeLine(cmd string, deny []string) (string, bool) {
+	line := strings.Join(strings.Fields(cmd), " ")
+	for _, e := range deny {
+		if strings.ContainsAny(e, "|;&") && Match(e, line) {
+			return e, true
+		}
+	}
+	return "", false
+}
+
+func anyDenied(segs []SegmentResult) bool {
+	for _, s := range segs {
+		if s.Verdict == Deny {
+			return true
+		}
+	}
+	return false
+}
+
+func describeOp(s Segment) string {
+	switch {
+	case s.Nested:
+		return "a nested command"
+	case s.Op == "|":
+		return "a pipe"
+	case s.Op == "\n":
+		return "a new line"
+	case s.Op != "":
+		return "a " + s.Op + " chain"
+	}
+	return "the line"
+}
+
+func matchDeny(text string, deny []string) (string, bool) {
+	words := stripPrefixWords(Fields(text))
+	forms := []string{strings.Join(words, " "), strings.Join(unwrap(words), " ")}
+	for _, e := range deny {
+		for _, f := range forms {
+			if f != "" && MatchLoose(e, f) {
+				return e, true
+			}
+		}
+	}
+	return "", false
+}
+
+func matchAllow(text string, allow []string) (string, bool) {
+	words := Fields(text)
+	for len(words) > 0 && shellKeywords[words[0]] {
+		words = words[1:]
+	}
+	form := strings.Join(words, " ")
+	if form == "" {
+		return "", false
+	}
+	for _, e := range allow {
+		if Match(e, form) {
+			return e, true
+		}
+	}
+	return "", false
+}
+
+// Match reports whether a list entry matches a normalised command. An entry
+// matches the command itself or any command that extends it with further
+// arguments ("git push" matches "git push origin main"). "*" matches any run
+// of characters and "?" any single character.
+func Match(pattern, command string) bool {
+	p := strings.Join(Fields(pattern), " ")
+	if p == "" {
+		return false
+	}
+	return glob(p, command) || glob(p+" *", command)
+}
+
+// MatchLoose is Match, but additionally accepts a command whose first word is
+// the entry's first word and which contains the entry's remaining words in
+// order, so "git push --force" also matches "git push origin main --force".
+// It is used for deny entries, where catching reordered flags matters more
+// than precision.
+func MatchLoose(pattern, command string) bool {
+	if Match(pattern, command) {
+		return true
+	}
+	pw, cw := Fields(pattern), Fields(command)
+	if len(pw) < 2 || len(cw) == 0 || !glob(pw[0], cw[0]) {
+		return false
+	}
+	j := 1
+	for _, w := range cw[1:] {
+		if j < len(pw) && glob(pw[j], w) {
+			j++
+		}
+	}
+	return j == len(pw)
+}
+
+// glob matches s against a pattern containing * and ? wildcards.
+func glob(pattern, s string) bool {
+	p, t := []rune(pattern), []rune(s)
+	pi, ti := 0, 0
+	star, mark := -1, 0
+	for ti < len(t) {
+		switch {
+		case pi < len(p) && (p[pi] == '?' || p[pi] == t[ti]):
+			pi++
+			ti++
+		case pi < len(p) && p[pi] == '*':
+			star, mark = pi, ti
+			pi++
+		case star >= 0:
+			pi = star + 1
+			mark++
+			ti = mark
+		default:
+			return false
+		}
+	}
+	for pi < len(p) && p[pi] == '*' {
+		pi++
+	}
+	return pi == len(p)
+}
@@ -0,0 +1,295 @@
+package policy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Preset is a named set of allow and deny entries that can be merged into the
+// command policy.
+type Preset struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Allow       []string `json:"allow"`
+	Deny        []string `json:"deny"`
+	Source      string   `json:"-"` // file the preset was loaded from, "" for built-ins
+}
+
+// Builtin are the presets shipped with wrench.
+var Builtin = []Preset{
+	{
+		Name:        "Read-only exploration",
+		Description: "Look around a repository without changing anything",
+		Allow: []string{
+			"ls", "pwd", "cat", "head", "tail", "wc", "file", "stat", "tree",
+			"grep", "rg", "fd", "which", "du -sh", "diff",
+			"git status", "git log", "git diff", "git show",
+			"git branch --list", "git branch --show-current",
+			"git blame", "git remote -v", "git rev-parse",
+		},
+		Deny: []string{
+			"git branch -d", "git branch -D", "git branch --delete",
+			"git branch -m", "git branch -M", "git branch --move",
+			"fd -x", "fd -X", "fd --exec", "fd --exec-batch",
+		},
+	},
+	{
+		Name:        "Go development",
+		Description: "Build, test and inspect Go modules",
+		Allow: []string{
+			"go build", "go test", "go vet", "go run", "go fmt", "gofmt",
+			"go mod tidy", "go mod download", "go list", "go env", "go version",
+			"go doc", "go generate", "golangci-lint run", "staticcheck",
+		},
+	},
+	{
+		Name:        "Node development",
+		Description: "Install, lint, test and build JavaScript and TypeScript projects",
+		Allow: []string{
+			"npm install", "npm ci", "npm test", "npm run", "npm ls",
+			"npx tsc", "npx eslint", "npx prettier", "npx jest", "npx vitest",
+			"pnpm install", "pnpm test", "pnpm run", "yarn install", "yarn test",
+			"yarn run", "node --version", "bun test", "bun run",
+		},
+		Deny: []string{"npm publish", "pnpm publish", "yarn publish"},
+	},
+	{
+		Name:        "Python development",
+		Description: "Run tests, linters and formatters in Python projects",
+		Allow: []string{
+			"pytest", "python -m pytest", "python -m unittest", "python --version",
+			"pip list", "pip show", "pip install -r requirements.txt", "uv sync",
+			"uv run", "poetry install", "poetry run", "ruff check", "ruff format",
+			"black", "mypy", "flake8", "tox",
+		},
+		Deny: []string{"twine upload", "pip uninstall"},
+	},
+	{
+		Name:        "Git safe",
+		Description: "Everyday git work; history rewrites and force pushes are denied",
+		Allow: []string{
+			"git status", "git log", "git diff", "git show", "git branch",
+			"git add", "git commit", "git stash", "git fetch", "git pull",
+			"git switch", "git checkout", "git restore", "git worktree list",
+		},
+		Deny: []string{
+			"git push --force", "git push -f", "git push --force-with-lease",
+			"git push --mirror", "git push --delete", "git reset --hard",
+			"git clean -fd", "git clean -fdx", "git filter-branch",
+			"git filter-repo", "git branch -D", "git reflog expire",
+			"git update-ref -d",
+		},
+	},
+	{
+		Name:        "Destructive operations",
+		Description: "Deny commands that wipe data, rewrite history or pipe the internet into a shell",
+		Deny: []string{
+			"rm -rf /", "rm -rf ~", "rm -rf .", "rm -fr /", "rm -rf --no-preserve-root",
+			"sudo rm", "git push --force", "git push -f", "git reset --hard",
+			"curl * | sh", "curl * | bash", "wget * | sh", "wget * | bash",
+			"dd if=*", "mkfs", "shred", "chmod -R 777", "chown -R",
+			":(){ :|:& };:", "shutdown", "reboot", "kill -9 -1",
+			"docker system prune", "kubectl delete",
+			"terraform destroy",
+		},
+	},
+}
+
+// PresetDir is where user presets are read from: one JSON object per file
+// with the same fields as Preset.
+func PresetDir(base string) string {
+	return filepath.Join(base, "presets")
+}
+
+// LoadPresets returns the built-in presets followed by the user presets in
+// dir, sorted by name. Files that cannot be read are reported in the error;
+// the presets that did load are still returned.
+func LoadPresets(dir string) ([]Preset, error) {
+	out := append([]Preset{}, Builtin...)
+
+	paths, _ := filepath.Glob(filepath.Join(dir, "*.json"))
+	sort.Strings(paths)
+	var errs []error
+	var user []Preset
+	for _, path := range paths {
+		p, err := readPreset(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", filepath.Base(path), err))
+			continue
+		}
+		user = append(user, p)
+	}
+	sort.SliceStable(user, func(i, j int) bool { return user[i].Name < user[j].Name })
+	return append(out, user...), errors.Join(errs...)
+}
+
+func readPreset(path string) (Preset, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Preset{}, err
+	}
+	var p Preset
+	if err := json.Unmarshal(data, &p); err != nil {
+		return Preset{}, err
+	}
+	if p.Name == "" {
+		p.Name = strings.TrimSuffix(filepath.Base(path), ".json")
+	}
+	if len(p.Allow) == 0 && len(p.Deny) == 0 {
+		return Preset{}, fmt.Errorf("preset has no allow or deny entries")
+	}
+	p.Source = path
+	return p, nil
+}
+
+// ───────────────────────────────────────────────
+// Merge preview
+// ───────────────────────────────────────────────
+
+// Column names a policy list.
+type Column int
+
+const (
+	AllowColumn Column = iota
+	DenyColumn
+)
+
+func (c Column) String() string {
+	if c == DenyColumn {
+		return "deny"
+	}
+	return "allow"
+}
+
+// MergeEntry is one preset entry in a merge preview.
+type MergeEntry struct {
+	Entry  string
+	Column Column // column the preset puts the entry in
+	Reason string // why it conflicts; empty otherwise
+}
+
+// MergePlan previews merging a preset into the current lists.
+type MergePlan struct {
+	Added     []MergeEntry // new entries that Apply adds
+	Overlaps  []MergeEntry // new entries that Apply adds, but that partly clash with the other column
+	Present   []MergeEntry // already in the same column
+	Conflicts []MergeEntry // clash with the current lists; Apply skips them
+}
+
+// PlanMerge compares a preset with the current lists, using the same
+// matching as Check. An allow entry conflicts when it is in the denylist or
+// the current denylist would still deny it. A deny entry conflicts when it is
+// in the allowlist. Entries that only overlap the other column are added with
+// a warning: an allow entry that covers commands a deny entry (current or
+// from the preset) blocks, or a deny entry that blocks commands an allow entry
+// covers. Deny wins where they meet.
+func PlanMerge(p Preset, allow, deny []string) MergePlan {
+	var plan MergePlan
+	seen := map[string]bool{}
+	allDeny := append(append([]string{}, deny...), p.Deny...)
+	for _, e := range p.Allow {
+		key := strings.Join(Fields(e), " ")
+		if key == "" || seen["a"+key] {
+			continue
+		}
+		seen["a"+key] = true
+		me := MergeEntry{Entry: strings.TrimSpace(e), Column: AllowColumn}
+		switch {
+		case containsNormalized(allow, key):
+			plan.Present = append(plan.Present, me)
+		case containsNormalized(deny, key):
+			me.Reason = "already in the denylist"
+			plan.Conflicts = append(plan.Conflicts, me)
+		default:
+			if d, ok := matchDeny(e, deny); ok {
+				me.Reason = fmt.Sprintf("denied by %q", d)
+				plan.Conflicts = append(plan.Conflicts, me)
+			} else if d, ok := coveredEntry(e, allDeny); ok {
+				me.Reason = fmt.Sprintf("deny entry %q still blocks part of it", d)
+				plan.Overlaps = append(plan.Overlaps, me)
+			} else {
+				plan.Added = append(plan.Added, me)
+			}
+		}
+	}
+	for _, e := range p.Deny {
+		key := strings.Join(Fields(e), " ")
+		if key == "" || seen["d"+key] {
+			continue
+		}
+		seen["d"+key] = true
+		me := MergeEntry{Entry: strings.TrimSpace(e), Column: DenyColumn}
+		switch {
+		case containsNormalized(deny, key):
+			plan.Present = append(plan.Present, me)
+		case containsNormalized(allow, key):
+			me.Reason = "already in the allowlist"
+			plan.Conflicts = append(plan.Conflicts, me)
+		default:
+			if a, ok := shadowedAllow(key, allow); ok {
+				me.Reason = fmt.Sprintf("makes allow entry %q useless", a)
+				plan.Overlaps = append(plan.Overlaps, me)
+			} else if a, ok := coveringEntry(key, allow); ok {
+				me.Reason = fmt.Sprintf("blocks part of allow entry %q", a)
+				plan.Overlaps = append(plan.Overlaps, me)
+			} else {
+				plan.Added = append(plan.Added, me)
+			}
+		}
+	}
+	return plan
+}
+
+// coveredEntry returns the first entry of list whose command the allow
+// pattern matches, i.e. an entry that blocks some of what it allows.
+func coveredEntry(pattern string, list []string) (string, bool) {
+	for _, e := range list {
+		if Match(pattern, strings.Join(Fields(e), " ")) {
+			return e, true
+		}
+	}
+	return "", false
+}
+
+// coveringEntry returns the first allow entry that matches the deny entry's
+// command, i.e. one the deny entry narrows.
+func coveringEntry(command string, allow []string) (string, bool) {
+	for _, a := range allow {
+		if Match(a, command) {
+			return a, true
+		}
+	}
+	return "", false
+}
+
+// shadowedAllow returns the first allow entry the deny entry blocks
+// entirely, as Lint reports it once the entry is added.
+func shadowedAllow(deny string, allow []string) (string, bool) {
+	for _, a := range allow {
+		if _, ok := matchDeny(a, []string{deny}); ok {
+			return a, true
+		}
+	}
+	return "", false
+}
+
+// Apply returns the lists with the plan's added and overlapping entries
+// appended.
+func (plan MergePlan) Apply(allow, deny []string) ([]string, []string) {
+	allow = append([]string{}, allow...)
+	deny = append([]string{}, deny...)
+	for _, e := range slices.Concat(plan.Added, plan.Overlaps) {
+		if e.Column == AllowColumn {
+			allow = append(allow, e.Entry)
+		} else {
+			deny = append(deny, e.Entry)
+		}
+	}
+	return allow, deny
+}
@@ -0,0 +1,282 @@
+package policy
+
+import "strings"
+
+// Segment is one simple command extracted from a shell line.
+type Segment struct {
+	Text   string // command text as written, trimmed
+	Op     string // operator that led to it: "", "|", "&&", "||", ";", "&", "\n"
+	Nested bool   // runs inside a subshell, command substitution or sh -c
+}
+
+// Split breaks a shell command line into simple commands at pipes, && / ||
+// chains, ; and & separators and newlines. Subshells "( … )", command
+// substitutions "$( … )" and backticks, and the script passed to sh -c / bash -c
+// or eval are split recursively and returned as nested segments. Quotes and
+// backslash escapes are respected.
+func Split(cmd string) []Segment {
+	return split([]rune(cmd), false)
+}
+
+func split(rs []rune, nested bool) []Segment {
+	var segs, inner []Segment
+	var cur strings.Builder
+	op := ""
+
+	flush := func(next string) {
+		text := strings.TrimSpace(cur.String())
+		cur.Reset()
+		if text != "" {
+			segs = append(segs, Segment{Text: text, Op: op, Nested: nested})
+			segs = append(segs, wrappedScript(text)...)
+		}
+		segs = append(segs, inner...)
+		inner = nil
+		op = next
+	}
+
+	inDouble := false
+	for i := 0; i < len(rs); i++ {
+		c := rs[i]
+		switch {
+		case c == '\\' && i+1 < len(rs):
+			cur.WriteRune(c)
+			cur.WriteRune(rs[i+1])
+			i++
+
+		case c == '\'' && !inDouble:
+			j := indexRune(rs, i+1, '\'')
+			cur.WriteString(string(rs[i:min(j+1, len(rs))]))
+			i = j
+
+		case c == '"':
+			inDouble = !inDouble
+			cur.WriteRune(c)
+
+		case c == '$' && i+1 < len(rs) && rs[i+1] == '(':
+			j := matchParen(rs, i+1)
+			body := rs[i+2 : j]
+			if len(body) > 0 && body[0] == '(' {
+				// $(( arithmetic )) — not a command
+				cur.WriteString(string(rs[i:min(j+1, len(rs))]))
+				i = j
+				break
+			}
+			inner = append(inner, split(body, true)...)
+			cur.WriteString(string(rs[i:min(j+1, len(rs))]))
+			i = j
+
+		case c == '`':
+			j := indexRune(rs, i+1, '`')
+			inner = append(inner, split(rs[i+1:j], true)...)
+			cur.WriteString(string(rs[i:min(j+1, len(rs))]))
+			i = j
+
+		case inDouble:
+			cur.WriteRune(c)
+
+		case c == '(' && strings.TrimSpace(cur.String()) == "":
+			j := matchParen(rs, i)
+			inner = append(inner, split(rs[i+1:j], true)...)
+			i = j
+
+		case c == '&' && i+1 < len(rs) && rs[i+1] == '&':
+			flush("&&")
+			i++
+		case c == '|' && i+1 < len(rs) && rs[i+1] == '|':
+			flush("||")
+			i++
+		case c == '|' && i+1 < len(rs) && rs[i+1] == '&':
+			flush("|")
+			i++
+		case c == '|':
+			flush("|")
+		case c == ';' || c == '\n':
+			flush(string(c))
+		case c == '&' && !isRedirect(rs, i):
+			flush("&")
+
+		default:
+			cur.WriteRune(c)
+		}
+	}
+	flush("")
+	return segs
+}
+
+// isRedirect reports whether the & at rs[i] belongs to a redirection such as
+// 2>&1, &> or >&.
+func isRedirect(rs []rune, i int) bool {
+	if i > 0 && (rs[i-1] == '>' || rs[i-1] == '<') {
+		return true
+	}
+	return i+1 < len(rs) && rs[i+1] == '>'
+}
+
+// indexRune returns the index of the next unescaped r at or after from, or
+// len(rs) when it is missing (an unterminated quote runs to the end).
+func indexRune(rs []rune, from int, r rune) int {
+	for j := from; j < len(rs); j++ {
+		if rs[j] == '\\' && r != '\'' {
+			j++
+			continue
+		}
+		if rs[j] == r {
+			return j
+		}
+	}
+	return len(rs)
+}
+
+// matchParen returns the index of the ) closing the ( at rs[open], skipping
+// quoted text. A missing ) yields len(rs).
+func matchParen(rs []rune, open int) int {
+	depth := 0
+	for j := open; j < len(rs); j++ {
+		switch rs[j] {
+		case '\\':
+			j++
+		case '\'', '"', '`':
+			j = indexRune(rs, j+1, rs[j])
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return j
+			}
+		}
+	}
+	return len(rs)
+}
+
+// wrappedScript returns the nested segments of a script handed to a shell
+// interpreter: sh -c '…', bash -c "…", eval "…".
+func wrappedScript(text string) []Segment {
+	words := Fields(text)
+	words = stripPrefixWords(words)
+	if len(words) < 2 {
+		return nil
+	}
+	switch words[0] {
+	case "sh", "bash", "zsh", "dash", "ksh", "fish":
+		for i := 1; i < len(words)-1; i++ {
+			if words[i] == "-c" || (strings.HasPrefix(words[i], "-") && strings.HasSuffix(words[i], "c")) {
+				return split([]rune(words[i+1]), true)
+			}
+		}
+	case "eval":
+		return split([]rune(strings.Join(words[1:], " ")), true)
+	}
+	return nil
+}
+
+// Fields splits a simple command into words, removing quotes and escapes.
+func Fields(s string) []string {
+	var words []string
+	var cur strings.Builder
+	inWord := false
+	rs := []rune(s)
+	for i := 0; i < len(rs); i++ {
+		c := rs[i]
+		switch {
+		case c == '\\' && i+1 < len(rs):
+			cur.WriteRune(rs[i+1])
+			i++
+			inWord = true
+		case c == '\'' || c == '"':
+			j := indexRune(rs, i+1, c)
+			if j > i {
+				cur.WriteString(string(rs[i+1 : j]))
+			}
+			i = j
+			inWord = true
+		case c == ' ' || c == '\t':
+			if inWord {
+				words = append(words, cur.String())
+				cur.Reset()
+				inWord = false
+			}
+		default:
+			cur.WriteRune(c)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, cur.String())
+	}
+	return words
+}
+
+// shellKeywords introduce a command without being one.
+var shellKeywords = map[string]bool{
+	"if": true, "then": true, "elif": true, "else": true, "fi": true,
+	"do": true, "done": true, "while": true, "until": true,
+	"{": true, "}": true, "!": true,
+}
+
+// wrappers run their arguments as a command.
+var wrappers = map[string]bool{
+	"sudo": true, "doas": true, "env": true, "nohup": true, "time": true,
+	"exec": true, "command": true, "builtin": true, "nice": true,
+	"xargs": true, "timeout": true, "watch": true,
+}
+
+// stripPrefixWords drops shell keywords and leading VAR=value assignments.
+func stripPrefixWords(words []string) []string {
+	for len(words) > 0 && (shellKeywords[words[0]] || isAssignment(words[0])) {
+		words = words[1:]
+	}
+	for len(words) > 0 && shellKeywords[words[len(words)-1]] {
+		words = words[:len(words)-1]
+	}
+	return words
+}
+
+// unwrap drops wrapper commands (sudo, env, xargs, …) and their flags.
+func unwrap(words []string) []string {
+	for len(words) > 0 && wrappers[words[0]] {
+		w := words[0]
+		words = words[1:]
+		for len(words) > 0 && (strings.HasPrefix(words[0], "-") || isAssignment(words[0]) ||
+			(w == "timeout" && isDuration(words[0]))) {
+			words = words[1:]
+		}
+	}
+	return words
+}
+
+// Programs returns the programs a simple command starts: wrappers such as
+// sudo or xargs, then the command they run. "sudo xargs -r rm" yields sudo,
+// xargs and rm.
+func Programs(text string) []string {
+	words := stripPrefixWords(Fields(text))
+	inner := unwrap(words)
+	var out []string
+	for _, w := range words[:len(words)-len(inner)] {
+		if wrappers[w] {
+			out = append(out, w)
+		}
+	}
+	if len(inner) > 0 {
+		out = append(out, inner[0])
+	}
+	return out
+}
+
+func isAssignment(w string) bool {
+	i := strings.IndexByte(w, '=')
+	if i <= 0 {
+		return false
+	}
+	for _, c := range w[:i] {
+		if !(c == '_' || c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+func isDuration(w string) bool {
+	return strings.TrimRight(w, "0123456789.smhd") == "" && w != ""
+}
@@ -6,6 +6,7 @@ type Provider struct {
 	BaseURL         string
 	Type            string // generic-chat-completion-api | anthropic | openai
 	ModelsEndpoint  string // "" means no auto-fetch
+	ModelsFormat    string // response parser for ModelsEndpoint; "" means use Type
 	RequiresBaseURL bool
 	NoAuth          bool
 }
@@ -28,9 +29,10 @@ var All = []struct {
 		ModelsEndpoint: "/models",
 	}},
 	{"anthropic", Provider{
-		Name:    "Anthropic",
-		BaseURL: "https://api.anthropic.com",
-		Type:    "anthropic",
+		Name:           "Anthropic",
+		BaseURL:        "https://api.anthropic.com",
+		Type:           "anthropic",
+		ModelsEndpoint: "/v1/models",
 	}},
 	{"groq", Provider{
 		Name:           "Groq",
@@ -39,9 +41,11 @@ var All = []struct {
 		ModelsEndpoint: "/models",
 	}},
 	{"gemini", Provider{
-		Name:    "Google Gemini",
-		BaseURL: "https://generativelanguage.googleapis.com/v1beta/",
-		Type:    "generic-chat-completion-api",
+		Name:           "Google Gemini",
+		BaseURL:        "https://generativelanguage.googleapis.com/v1beta/",
+		Type:           "generic-chat-completion-api",
+		ModelsEndpoint: "/models",
+		ModelsFormat:   "gemini",
 	}},
 	{"deepinfra", Provider{
 		Name:           "DeepInfra",
@@ -97,6 +101,14 @@ var ProviderTypes = []struct {
 	{"anthropic", "Anthropic (Messages API)"},
 }
 
+// Format returns the models response format, defaulting to the provider type.
+func (p Provider) Format() string {
+	if p.ModelsFormat != "" {
+		return p.ModelsFormat
+	}
+	return p.Type
+}
+
 // Get returns a provider by key, nil if not found.
 func Get(key string) *Provider {
 	for _, p := range All {
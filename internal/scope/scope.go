@@ -0,0 +1,51 @@
+// Package scope locates the personal and project .factory folders that
+// hold Droid's file-based configuration, such as custom droids and slash
+// commands.
+package scope
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Scope says where a file lives.
+type Scope int
+
+const (
+	Personal Scope = iota // ~/.factory, available in every project
+	Project               // <project>/.factory, shared through the repository
+)
+
+func (s Scope) String() string {
+	if s == Project {
+		return "project"
+	}
+	return "personal"
+}
+
+// ProjectRoot returns the project containing dir: the nearest parent with a
+// .factory or .git directory, or dir itself. The home directory does not
+// count, since its .factory folder is the personal one.
+func ProjectRoot(dir, home string) string {
+	for d := dir; ; d = filepath.Dir(d) {
+		if d != home {
+			for _, marker := range []string{".factory", ".git"} {
+				if info, err := os.Stat(filepath.Join(d, marker)); err == nil && info.IsDir() {
+					return d
+				}
+			}
+		}
+		if filepath.Dir(d) == d {
+			return dir
+		}
+	}
+}
+
+// Dir returns the folder called name inside the .factory folder of s, e.g.
+// Dir(Project, home, cwd, "droids").
+func Dir(s Scope, home, cwd, name string) string {
+	if s == Project {
+		return filepath.Join(ProjectRoot(cwd, home), ".factory", name)
+	}
+	return filepath.Join(home, ".factory", name)
+}
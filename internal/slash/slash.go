@@ -0,0 +1,311 @@
+// Package slash manages Droid's custom slash commands: markdown prompt
+// files and executable scripts in the personal and project commands
+// folders. A file named review.md is run as /review.
+package slash
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/kaan-escober/wrench/internal/scope"
+)
+
+// Kind is how Droid runs a command.
+type Kind int
+
+const (
+	Markdown   Kind = iota // a prompt template sent to Droid
+	Executable             // a script whose output Droid receives
+)
+
+func (k Kind) String() string {
+	if k == Executable {
+		return "exec"
+	}
+	return "md"
+}
+
+// Command is one command file.
+type Command struct {
+	Name         string // without the leading slash
+	Path         string
+	Scope        scope.Scope
+	Kind         Kind
+	Description  string
+	ArgumentHint string // markdown commands: what to type after the name
+	Problems     []string
+}
+
+// Dir returns the commands folder of s.
+func Dir(s scope.Scope, home, cwd string) string {
+	return scope.Dir(s, home, cwd, "commands")
+}
+
+var namePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// ValidName reports why name cannot be used as a command name, or nil.
+func ValidName(name string) error {
+	if name == "" {
+		return errors.New("the name is empty")
+	}
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("%q: use letters, digits, - and _", name)
+	}
+	return nil
+}
+
+// nameOf returns the command name of a file: its name without extension.
+func nameOf(path string) string {
+	base := filepath.Base(path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// Load reads the commands of both scopes, personal first, each sorted by
+// name. Collisions are noted on the commands involved: a project command
+// overrides the personal one of the same name, and two files of one scope
+// cannot share a name.
+func Load(home, cwd string) ([]Command, error) {
+	var out []Command
+	var errs []error
+	for _, s := range []scope.Scope{scope.Personal, scope.Project} {
+		dir := Dir(s, home, cwd)
+		if s == scope.Project && dir == Dir(scope.Personal, home, cwd) {
+			continue // run from the home directory: same folder
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		for _, e := range entries {
+			if e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+				continue
+			}
+			c, err := read(filepath.Join(dir, e.Name()), s)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			out = append(out, c)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Scope != out[j].Scope {
+			return out[i].Scope < out[j].Scope
+		}
+		return out[i].Name < out[j].Name
+	})
+
+	type key struct {
+		name  string
+		scope scope.Scope
+	}
+	count := map[key]int{}
+	project := map[string]bool{}
+	for _, c := range out {
+		count[key{c.Name, c.Scope}]++
+		if c.Scope == scope.Project {
+			project[c.Name] = true
+		}
+	}
+	for i, c := range out {
+		if count[key{c.Name, c.Scope}] > 1 {
+			out[i].Problems = append(out[i].Problems, fmt.Sprintf("another %s file is also /%s", c.Scope, c.Name))
+		}
+		if c.Scope == scope.Personal && project[c.Name] {
+			out[i].Problems = append(out[i].Problems, "overridden by the project command of the same name")
+		}
+	}
+	return out, errors.Join(errs...)
+}
+
+func read(path string, s scope.Scope) (Command, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return Command{}, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Command{}, err
+	}
+	c := Command{Name: nameOf(path), Path: path, Scope: s}
+	if err := ValidName(c.Name); err != nil {
+		c.Problems = append(c.Problems, "name: "+err.Error())
+	}
+	if strings.EqualFold(filepath.Ext(path), ".md") {
+		c.Kind = Markdown
+		c.Description, c.ArgumentHint = frontmatter(string(data))
+		return c, nil
+	}
+	c.Kind = Executable
+	c.Description = scriptDescription(string(data))
+	c.Problems = append(c.Problems, checkScript(string(data), info.Mode())...)
+	return c, nil
+}
+
+// frontmatter reads description and argument-hint from a markdown
+// command's frontmatter, if it has one.
+func frontmatter(text string) (description, hint string) {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	rest, ok := strings.CutPrefix(text, "---\n")
+	if !ok {
+		return "", ""
+	}
+	front, _, _ := strings.Cut(rest, "\n---")
+	for _, line := range strings.Split(front, "\n") {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		value = unquote(strings.TrimSpace(value))
+		switch strings.TrimSpace(key) {
+		case "description":
+			description = value
+		case "argument-hint":
+			hint = value
+		}
+	}
+	return description, hint
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+// scriptDescription returns the first comment line after the shebang.
+func scriptDescription(text string) string {
+	sc := bufio.NewScanner(strings.NewReader(text))
+	for i := 0; sc.Scan() && i < 5; i++ {
+		line := strings.TrimSpace(sc.Text())
+		if strings.HasPrefix(line, "#!") || line == "" {
+			continue
+		}
+		for _, prefix := range []string{"#", "//", "--"} {
+			if rest, ok := strings.CutPrefix(line, prefix); ok {
+				rest = strings.TrimSpace(rest)
+				return strings.TrimSpace(strings.TrimPrefix(rest, "description:"))
+			}
+		}
+		return ""
+	}
+	return ""
+}
+
+// checkScript reports what stops an executable command from running: a
+// missing or broken shebang and a missing execute permission.
+func checkScript(text string, mode os.FileMode) []string {
+	var out []string
+	first, _, _ := strings.Cut(text, "\n")
+	first = strings.TrimSpace(first)
+	if interp, ok := strings.CutPrefix(first, "#!"); !ok {
+		out = append(out, "no shebang: the first line must name the interpreter, e.g. #!/usr/bin/env bash")
+	} else if missing := Interpreter(interp); missing != "" {
+		out = append(out, "interpreter not found: "+missing)
+	}
+	if mode&0o111 == 0 {
+		out = append(out, "not executable: the file needs chmod +x")
+	}
+	return out
+}
+
+// Interpreter returns the program of a shebang line (without #!) that
+// cannot be found, or "". "/usr/bin/env prog" looks prog up on PATH.
+func Interpreter(line string) string {
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return "(empty shebang)"
+	}
+	prog := fields[0]
+	if filepath.Base(prog) == "env" && len(fields) > 1 {
+		args := fields[1:]
+		if args[0] == "-S" && len(args) > 1 {
+			args = args[1:]
+		}
+		if _, err := exec.LookPath(args[0]); err != nil {
+			return args[0]
+		}
+		return ""
+	}
+	if info, err := os.Stat(prog); err != nil || info.IsDir() || info.Mode()&0o111 == 0 {
+		return prog
+	}
+	return ""
+}
+
+// ─── Changes ──────────────────────────────────────────────────────────────────
+
+// Create writes a new command from t in dir and returns its path. It
+// refuses to replace an existing command of the same name.
+func Create(dir, name string, t Template) (string, error) {
+	if err := ValidName(name); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	if existing := find(dir, name); existing != "" {
+		return "", fmt.Errorf("%s already exists", existing)
+	}
+	perm := os.FileMode(0o644)
+	if t.Kind == Executable {
+		perm = 0o755
+	}
+	path := filepath.Join(dir, name+t.Ext)
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+	if err != nil {
+		return "", err
+	}
+	if _, err := f.WriteString(t.Render(name)); err != nil {
+		f.Close()
+		return "", err
+	}
+	return path, f.Close()
+}
+
+// find returns a file in dir whose command name is name, or "".
+func find(dir, name string) string {
+	entries, _ := os.ReadDir(dir)
+	for _, e := range entries {
+		if !e.IsDir() && nameOf(e.Name()) == name {
+			return filepath.Join(dir, e.Name())
+		}
+	}
+	return ""
+}
+
+// Rename gives c a new name, keeping its folder and extension, and returns
+// the new path.
+func Rename(c Command, name string) (string, error) {
+	if err := ValidName(name); err != nil {
+		return "", err
+	}
+	dir := filepath.Dir(c.Path)
+	if existing := find(dir, name); existing != "" {
+		return "", fmt.Errorf("%s already exists", existing)
+	}
+	path := filepath.Join(dir, name+filepath.Ext(c.Path))
+	return path, os.Rename(c.Path, path)
+}
+
+// MakeExecutable adds execute permission wherever the file has read
+// permission, like chmod +x.
+func MakeExecutable(c Command) error {
+	info, err := os.Stat(c.Path)
+	if err != nil {
+		return err
+	}
+	mode := info.Mode().Perm()
+	return os.Chmod(c.Path, mode|(mode&0o444)>>2)
+}
@@ -0,0 +1,73 @@
+package slash
+
+import "strings"
+
+// Template is a starting point for a new command.
+type Template struct {
+	Name        string
+	Description string
+	Kind        Kind
+	Ext         string // file extension including the dot
+	Body        string // {{name}} is replaced by the command name
+}
+
+// Render returns the file contents of a command called name.
+func (t Template) Render(name string) string {
+	return strings.ReplaceAll(t.Body, "{{name}}", name)
+}
+
+// Templates are the built-in templates.
+var Templates = []Template{
+	{
+		Name:        "Prompt",
+		Description: "A markdown prompt · $ARGUMENTS is what you type after the command",
+		Kind:        Markdown,
+		Ext:         ".md",
+		Body: `---
+description: Describe what /{{name}} does
+argument-hint: <what to work on>
+---
+
+Work on the following: $ARGUMENTS
+`,
+	},
+	{
+		Name:        "Code review",
+		Description: "Ask Droid to review the current changes",
+		Kind:        Markdown,
+		Ext:         ".md",
+		Body: `---
+description: Review the uncommitted changes
+argument-hint: [focus area]
+---
+
+Review the uncommitted changes in this repository (git diff HEAD).
+Look for bugs, missing tests and unclear names, and list each finding
+with its file and line. Pay extra attention to: $ARGUMENTS
+`,
+	},
+	{
+		Name:        "Shell script",
+		Description: "An executable bash script · Droid receives what it prints",
+		Kind:        Executable,
+		Ext:         ".sh",
+		Body: `#!/usr/bin/env bash
+# description: Describe what /{{name}} does
+set -euo pipefail
+
+echo "/{{name}} ran with: $*"
+`,
+	},
+	{
+		Name:        "Python script",
+		Description: "An executable Python script · Droid receives what it prints",
+		Kind:        Executable,
+		Ext:         ".py",
+		Body: `#!/usr/bin/env python3
+# description: Describe what /{{name}} does
+import sys
+
+print("/{{name}} ran with:", " ".join(sys.argv[1:]))
+`,
+	},
+}
@@ -0,0 +1,207 @@
+package theme
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/kaan-escober/wrench/internal/config"
+)
+
+// ─── Theme files ──────────────────────────────────────────────────────────────
+
+// Dir returns the directory of the user's theme files, one <name>.json each.
+func Dir() string {
+	return filepath.Join(config.WrenchDir(), "themes")
+}
+
+// File is the format of a theme file: a built-in theme to start from and the
+// styles it changes.
+type File struct {
+	Base   string               `json:"base,omitempty"` // a built-in theme, or "auto" (the default)
+	Styles map[string]StyleSpec `json:"styles"`
+}
+
+// StyleSpec overrides parts of one style. Colors are "#RRGGBB", "#RGB" or an
+// ANSI color number from 0 to 255; fields left out keep the base theme's
+// value.
+type StyleSpec struct {
+	Foreground string `json:"foreground,omitempty"`
+	Background string `json:"background,omitempty"`
+	Border     string `json:"border,omitempty"` // input only
+	Bold       *bool  `json:"bold,omitempty"`
+	Faint      *bool  `json:"faint,omitempty"`
+	Italic     *bool  `json:"italic,omitempty"`
+	Underline  *bool  `json:"underline,omitempty"`
+	Reverse    *bool  `json:"reverse,omitempty"`
+}
+
+// StyleNames are the keys of the "styles" object of a theme file.
+var StyleNames = []string{
+	"accent", "primary", "muted", "success", "error", "teal", "bold",
+	"badge", "badge-success", "badge-error", "badge-muted", "input",
+}
+
+func (t *Theme) style(name string) *lipgloss.Style {
+	switch name {
+	case "accent":
+		return &t.Accent
+	case "primary":
+		return &t.Primary
+	case "muted":
+		return &t.Muted
+	case "success":
+		return &t.Success
+	case "error":
+		return &t.Error
+	case "teal":
+		return &t.Teal
+	case "bold":
+		return &t.Bold
+	case "badge":
+		return &t.Badge
+	case "badge-success":
+		return &t.BadgeSuccess
+	case "badge-error":
+		return &t.BadgeError
+	case "badge-muted":
+		return &t.BadgeMuted
+	case "input":
+		return &t.Input
+	}
+	return nil
+}
+
+var hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+func parseColor(s string) (lipgloss.Color, error) {
+	if hexColor.MatchString(s) {
+		return lipgloss.Color(s), nil
+	}
+	if n, err := strconv.Atoi(s); err == nil && n >= 0 && n <= 255 {
+		return lipgloss.Color(s), nil
+	}
+	return "", fmt.Errorf("%q is not a color (use #RRGGBB, #RGB or 0-255)", s)
+}
+
+// apply layers the spec over s.
+func (spec StyleSpec) apply(s lipgloss.Style) (lipgloss.Style, error) {
+	if spec.Foreground != "" {
+		c, err := parseColor(spec.Foreground)
+		if err != nil {
+			return s, err
+		}
+		s = s.Foreground(c)
+	}
+	if spec.Background != "" {
+		c, err := parseColor(spec.Background)
+		if err != nil {
+			return s, err
+		}
+		s = s.Background(c)
+	}
+	if spec.Border != "" {
+		c, err := parseColor(spec.Border)
+		if err != nil {
+			return s, err
+		}
+		s = s.BorderForeground(c)
+	}
+	if spec.Bold != nil {
+		s = s.Bold(*spec.Bold)
+	}
+	if spec.Faint != nil {
+		s = s.Faint(*spec.Faint)
+	}
+	if spec.Italic != nil {
+		s = s.Italic(*spec.Italic)
+	}
+	if spec.Underline != nil {
+		s = s.Underline(*spec.Underline)
+	}
+	if spec.Reverse != nil {
+		s = s.Reverse(*spec.Reverse)
+	}
+	return s, nil
+}
+
+// Parse builds the theme described by a theme file.
+func Parse(name string, data []byte) (Theme, error) {
+	var f File
+	if err := json.Unmarshal(data, &f); err != nil {
+		return Theme{}, err
+	}
+	t, ok := builtin(f.Base)
+	if !ok {
+		return Theme{}, fmt.Errorf("unknown base theme %q (use %s)", f.Base, strings.Join(Names(), ", "))
+	}
+	t.Name = name
+	for key, spec := range f.Styles {
+		s := t.style(key)
+		if s == nil {
+			return Theme{}, fmt.Errorf("unknown style %q (use %s)", key, strings.Join(StyleNames, ", "))
+		}
+		styled, err := spec.apply(*s)
+		if err != nil {
+			return Theme{}, fmt.Errorf("%s: %w", key, err)
+		}
+		*s = styled
+	}
+	return t, nil
+}
+
+// Names lists the built-in theme names, "auto" first.
+func Names() []string {
+	names := []string{"auto"}
+	for _, t := range Builtin {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+// builtin returns the built-in theme of that name. "auto" and "" pick dark
+// or light from the terminal background.
+func builtin(name string) (Theme, bool) {
+	if name == "" || name == "auto" {
+		if lipgloss.HasDarkBackground() {
+			return Dark, true
+		}
+		return Light, true
+	}
+	for _, t := range Builtin {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return Theme{}, false
+}
+
+// Load returns the theme of that name: a built-in one, or the user's
+// <name>.json in Dir. A theme that does not exist or cannot be read is
+// reported, and the auto theme is used.
+func Load(name string) (Theme, error) {
+	if t, ok := builtin(name); ok {
+		return t, nil
+	}
+	fallback, _ := builtin("auto")
+	path := filepath.Join(Dir(), name+".json")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fallback, fmt.Errorf("unknown theme %q (use %s, or add %s) · using the %s theme",
+				name, strings.Join(Names(), ", "), path, fallback.Name)
+		}
+		return fallback, err
+	}
+	t, err := Parse(name, data)
+	if err != nil {
+		return fallback, fmt.Errorf("%s: %w · using the %s theme", path, err, fallback.Name)
+	}
+	return t, nil
+}
@@ -1,55 +1,162 @@
+// Package theme holds the styles the TUI draws with. The styles are package
+// variables so views can use them directly; Apply swaps them for another
+// theme before the program starts.
 package theme
 
 import "github.com/charmbracelet/lipgloss"
 
-// Colors — extracted from Factory/Droid screenshots
-const (
-	ColorAccent  = lipgloss.Color("#FF9E64") // orange — primary accent
-	ColorPrimary = lipgloss.Color("#C0CAF5") // off-white lavender — body text
-	ColorMuted   = lipgloss.Color("#565F89") // slate — secondary/hints
-	ColorSuccess = lipgloss.Color("#9ECE6A") // green
-	ColorError   = lipgloss.Color("#F7768E") // red
-	ColorTeal    = lipgloss.Color("#7DCFFF") // teal — code/commands
-	ColorBlack   = lipgloss.Color("#1A1B26") // badge text bg-color
-)
-
 // Base styles
 var (
-	Accent  = lipgloss.NewStyle().Foreground(ColorAccent)
-	Primary = lipgloss.NewStyle().Foreground(ColorPrimary)
-	Muted   = lipgloss.NewStyle().Foreground(ColorMuted)
-	Success = lipgloss.NewStyle().Foreground(ColorSuccess)
-	Error   = lipgloss.NewStyle().Foreground(ColorError)
-	Teal    = lipgloss.NewStyle().Foreground(ColorTeal)
-	Bold    = lipgloss.NewStyle().Foreground(ColorPrimary).Bold(true)
+	Accent  lipgloss.Style
+	Primary lipgloss.Style
+	Muted   lipgloss.Style
+	Success lipgloss.Style
+	Error   lipgloss.Style
+	Teal    lipgloss.Style
+	Bold    lipgloss.Style
 )
 
-// Badge — solid orange rect, dark text, sharp corners. Matches ASK USER / PLAN etc.
-var Badge = lipgloss.NewStyle().
-	Background(ColorAccent).
-	Foreground(ColorBlack).
-	Bold(true).
-	Padding(0, 1)
+// Badge — solid accent rect, dark text, sharp corners. Matches ASK USER / PLAN etc.
+var Badge lipgloss.Style
 
 // BadgeSuccess — same shape, green
-var BadgeSuccess = lipgloss.NewStyle().
-	Background(ColorSuccess).
-	Foreground(ColorBlack).
-	Bold(true).
-	Padding(0, 1)
+var BadgeSuccess lipgloss.Style
 
 // BadgeError — same shape, red
-var BadgeError = lipgloss.NewStyle().
-	Background(ColorError).
-	Foreground(ColorBlack).
-	Bold(true).
-	Padding(0, 1)
+var BadgeError lipgloss.Style
+
+// BadgeMuted — same shape, dimmed; the badges of rows the cursor is not on
+var BadgeMuted lipgloss.Style
 
 // Input — the text input box, thin dim border
-var Input = lipgloss.NewStyle().
-	BorderStyle(lipgloss.NormalBorder()).
-	BorderForeground(ColorMuted).
-	Padding(0, 1)
+var Input lipgloss.Style
+
+// Theme is one complete set of styles.
+type Theme struct {
+	Name         string
+	Accent       lipgloss.Style
+	Primary      lipgloss.Style
+	Muted        lipgloss.Style
+	Success      lipgloss.Style
+	Error        lipgloss.Style
+	Teal         lipgloss.Style
+	Bold         lipgloss.Style
+	Badge        lipgloss.Style
+	BadgeSuccess lipgloss.Style
+	BadgeError   lipgloss.Style
+	BadgeMuted   lipgloss.Style
+	Input        lipgloss.Style
+}
+
+// Apply makes t the theme every view draws with. Styles copied out of the
+// package variables before the call (text inputs, the spinner) keep the old
+// theme, so Apply runs before the model is built.
+func Apply(t Theme) {
+	Accent, Primary, Muted = t.Accent, t.Primary, t.Muted
+	Success, Error, Teal, Bold = t.Success, t.Error, t.Teal, t.Bold
+	Badge, BadgeSuccess, BadgeError, BadgeMuted = t.Badge, t.BadgeSuccess, t.BadgeError, t.BadgeMuted
+	Input = t.Input
+}
+
+func init() {
+	Apply(Dark)
+}
+
+// palette is the handful of colors a colored theme is built from.
+type palette struct {
+	accent  lipgloss.TerminalColor
+	primary lipgloss.TerminalColor
+	muted   lipgloss.TerminalColor
+	success lipgloss.TerminalColor
+	err     lipgloss.TerminalColor
+	teal    lipgloss.TerminalColor
+	onBadge lipgloss.TerminalColor // text on a badge
+	dim     lipgloss.TerminalColor // background of a muted badge
+}
+
+func (p palette) theme(name string) Theme {
+	badge := func(bg, fg lipgloss.TerminalColor) lipgloss.Style {
+		return lipgloss.NewStyle().Background(bg).Foreground(fg).Bold(true).Padding(0, 1)
+	}
+	return Theme{
+		Name:         name,
+		Accent:       lipgloss.NewStyle().Foreground(p.accent),
+		Primary:      lipgloss.NewStyle().Foreground(p.primary),
+		Muted:        lipgloss.NewStyle().Foreground(p.muted),
+		Success:      lipgloss.NewStyle().Foreground(p.success),
+		Error:        lipgloss.NewStyle().Foreground(p.err),
+		Teal:         lipgloss.NewStyle().Foreground(p.teal),
+		Bold:         lipgloss.NewStyle().Foreground(p.primary).Bold(true),
+		Badge:        badge(p.accent, p.onBadge),
+		BadgeSuccess: badge(p.success, p.onBadge),
+		BadgeError:   badge(p.err, p.onBadge),
+		BadgeMuted:   badge(p.dim, p.muted),
+		Input:        lipgloss.NewStyle().BorderStyle(lipgloss.NormalBorder()).BorderForeground(p.muted).Padding(0, 1),
+	}
+}
+
+// Dark — extracted from Factory/Droid screenshots (Tokyo Night)
+var Dark = palette{
+	accent:  lipgloss.Color("#FF9E64"), // orange — primary accent
+	primary: lipgloss.Color("#C0CAF5"), // off-white lavender — body text
+	muted:   lipgloss.Color("#565F89"), // slate — secondary/hints
+	success: lipgloss.Color("#9ECE6A"), // green
+	err:     lipgloss.Color("#F7768E"), // red
+	teal:    lipgloss.Color("#7DCFFF"), // teal — code/commands
+	onBadge: lipgloss.Color("#1A1B26"), // badge text
+	dim:     lipgloss.Color("#2A2B3A"),
+}.theme("dark")
+
+// Light — Tokyo Night Day, darkened where the day colors are too pale to
+// read on white
+var Light = palette{
+	accent:  lipgloss.Color("#B15C00"),
+	primary: lipgloss.Color("#343B58"),
+	muted:   lipgloss.Color("#6C6F85"),
+	success: lipgloss.Color("#3F6B1E"),
+	err:     lipgloss.Color("#C4214A"),
+	teal:    lipgloss.Color("#006A8E"),
+	onBadge: lipgloss.Color("#FFFFFF"),
+	dim:     lipgloss.Color("#D5D6DB"),
+}.theme("light")
+
+// HighContrast — full-strength colors, black or white text depending on the
+// terminal background
+var HighContrast = palette{
+	accent:  lipgloss.AdaptiveColor{Light: "#A63C00", Dark: "#FFAF00"},
+	primary: lipgloss.AdaptiveColor{Light: "#000000", Dark: "#FFFFFF"},
+	muted:   lipgloss.AdaptiveColor{Light: "#303030", Dark: "#D0D0D0"},
+	success: lipgloss.AdaptiveColor{Light: "#005F00", Dark: "#5FFF5F"},
+	err:     lipgloss.AdaptiveColor{Light: "#AF0000", Dark: "#FF5F5F"},
+	teal:    lipgloss.AdaptiveColor{Light: "#00005F", Dark: "#5FFFFF"},
+	onBadge: lipgloss.AdaptiveColor{Light: "#FFFFFF", Dark: "#000000"},
+	dim:     lipgloss.AdaptiveColor{Light: "#C6C6C6", Dark: "#3A3A3A"},
+}.theme("high-contrast")
+
+// Monochrome — no colors at all, only bold, faint and reverse; used for
+// NO_COLOR and --color=never
+var Monochrome = func() Theme {
+	plain := lipgloss.NewStyle()
+	badge := plain.Reverse(true).Bold(true).Padding(0, 1)
+	return Theme{
+		Name:         "monochrome",
+		Accent:       plain.Bold(true),
+		Primary:      plain,
+		Muted:        plain.Faint(true),
+		Success:      plain,
+		Error:        plain.Bold(true),
+		Teal:         plain,
+		Bold:         plain.Bold(true),
+		Badge:        badge,
+		BadgeSuccess: badge,
+		BadgeError:   badge,
+		BadgeMuted:   plain.Faint(true).Bold(true).Padding(0, 1),
+		Input:        plain.BorderStyle(lipgloss.NormalBorder()).Padding(0, 1),
+	}
+}()
+
+// Builtin lists the built-in themes by name.
+var Builtin = []Theme{Dark, Light, HighContrast, Monochrome}
 
 // Prompt — orange `>` before input fields
 const Prompt = "❯ "
@@ -1,5 +1,7 @@
 package ui
 
+import "github.com/kaan-escober/wrench/internal/config"
+
 // AppMode is the top-level navigation state of the application.
 type AppMode int
 
@@ -11,184 +13,72 @@ const (
 	ModeTextInput                  // free-text entry for a setting
 	ModeCommandEdit                // command allow/deny list editor
 	ModeCommandAdd                 // text input inside command editor
+	ModeCommandTest                // policy tester pane inside command editor
+	ModeCommandPreset              // preset picker and merge preview inside command editor
+	ModeCommandSuggest             // allowlist suggestions from shell history
+	ModeCommandPaste               // multi-line paste of entries inside command editor
+	ModeCommandFilter              // typing a filter for the command editor columns
+	ModeOther                      // top-level settings.json keys without a screen
+	ModeOtherTree                  // JSON tree editor for an object or array key
+	ModeOtherInput                 // typing a value or key on the Advanced screens
+	ModeOtherConfirm               // confirming a delete on the Advanced screens
+	ModeHooks                      // hooks grouped by event
+	ModeHookForm                   // fields of the hook being added or edited
+	ModeHookInput                  // typing a matcher, command or timeout
+	ModeHookPick                   // picking an event or a template
+	ModeHookRun                    // running a hook once with sample input
+	ModeHookConfirm                // confirming a hook delete
+	ModeDroids                     // custom droids of both scopes
+	ModeDroidWizard                // creating or duplicating a droid
+	ModeSlash                      // custom slash commands of both scopes
+	ModeSlashPick                  // picking a template or scope for a new command
+	ModeSlashInput                 // typing the name of a new or renamed command
+	ModeSlashConfirm               // confirming a command delete
+	ModeMCP                        // MCP servers from mcp.json
+	ModeMCPForm                    // fields of the server being added or edited
+	ModeMCPInput                   // typing a name, command or URL
+	ModeMCPLines                   // editing args, env or headers one per line
+	ModeMCPTest                    // launching a stdio server once
+	ModeMCPConfirm                 // confirming a server delete
+	ModePalette                    // searching settings, models and actions
 	ModeBYOK                       // full BYOK wizard
 )
 
-// Category identifies a settings group.
-type Category int
+// Category identifies a settings group: a schema category id, or one of
+// the screens below that are not driven by the schema.
+type Category string
 
 const (
-	CatBYOK     Category = iota
-	CatModel
-	CatAutonomy
-	CatDisplay
-	CatSound
-	CatSecurity
-	CatBehavior
-	CatCommands
+	CatBYOK     Category = "byok"
+	CatCommands Category = "commands" // schema category with its own editor
+	CatHooks    Category = "hooks"    // the hooks section of settings.json
+	CatDroids   Category = "droids"   // custom droid files, not settings.json
+	CatSlash    Category = "slash"    // custom slash command files
+	CatMCP      Category = "mcp"      // mcp.json next to settings.json
+	CatOther    Category = "other"    // settings.json keys nothing else covers
 )
 
-// SettingKind is the input type for a setting.
-type SettingKind int
+// unsetOption is the picker value that removes a setting's key from
+// settings.json.
+const unsetOption = "__unset__"
 
-const (
-	KindEnum SettingKind = iota
-	KindBool
-	KindText
-)
+// unsetLabel is the picker label for unsetOption.
+const unsetLabel = "Reset to default (remove key)"
 
-// OptionDef is one selectable value in an enum picker.
-type OptionDef struct {
-	Value string
-	Label string
-	Desc  string
-}
+// customOption is the picker value that asks for a value outside an enum's
+// options.
+const customOption = "__custom__"
 
-// SettingDef describes one configurable setting within a category.
-type SettingDef struct {
-	Label   string
-	Key     string
-	Kind    SettingKind
-	Options []OptionDef
-	Default string // displayed when value is unset
+// settingsIn returns the settings of a category from the schema.
+func settingsIn(cat Category) []config.SettingSpec {
+	sc, _ := config.CurrentSchema()
+	return sc.InCategory(string(cat))
 }
 
-// ─────────────────────────────────────────────────────────────────────────────
-// All category definitions
-// ─────────────────────────────────────────────────────────────────────────────
-
-var categorySettings = map[Category][]SettingDef{
-	CatModel: {
-		{
-			Label: "Default Model", Key: "model", Kind: KindEnum, Default: "opus",
-			Options: []OptionDef{
-				{Value: "opus", Label: "opus", Desc: "Claude Opus 4.5  (default)"},
-				{Value: "opus-4-6", Label: "opus-4-6", Desc: "Claude Opus 4.6 · Max reasoning"},
-				{Value: "opus-4-6-fast", Label: "opus-4-6-fast", Desc: "Opus 4.6 Fast · tuned for speed"},
-				{Value: "sonnet", Label: "sonnet", Desc: "Claude Sonnet 4.5 · balanced"},
-				{Value: "gpt-5.1", Label: "gpt-5.1", Desc: "OpenAI GPT-5.1"},
-				{Value: "gpt-5.1-codex", Label: "gpt-5.1-codex", Desc: "Advanced coding focus"},
-				{Value: "gpt-5.1-codex-max", Label: "gpt-5.1-codex-max", Desc: "Extra High reasoning"},
-				{Value: "gpt-5.2", Label: "gpt-5.2", Desc: "OpenAI GPT-5.2"},
-				{Value: "gpt-5.2-codex", Label: "gpt-5.2-codex", Desc: "GPT-5.2 coding · Extra High"},
-				{Value: "gpt-5.3-codex", Label: "gpt-5.3-codex", Desc: "Latest OpenAI coding model"},
-				{Value: "haiku", Label: "haiku", Desc: "Claude Haiku 4.5 · fast & cheap"},
-				{Value: "gemini-3-pro", Label: "gemini-3-pro", Desc: "Google Gemini 3 Pro"},
-				{Value: "droid-core", Label: "droid-core", Desc: "GLM-4.7 open-source"},
-				{Value: "kimi-k2.5", Label: "kimi-k2.5", Desc: "Kimi K2.5 · image support"},
-				{Value: "minimax-m2.5", Label: "minimax-m2.5", Desc: "MiniMax M2.5 · 0.12× cost"},
-				{Value: "custom-model", Label: "custom-model", Desc: "Your BYOK configured model"},
-			},
-		},
-		{
-			Label: "Reasoning Effort", Key: "reasoningEffort", Kind: KindEnum, Default: "model default",
-			Options: []OptionDef{
-				{Value: "off", Label: "off", Desc: "No structured reasoning · fastest"},
-				{Value: "none", Label: "none", Desc: "Alias for off"},
-				{Value: "low", Label: "low", Desc: "Light deliberation"},
-				{Value: "medium", Label: "medium", Desc: "Balanced thinking · GPT-5 default"},
-				{Value: "high", Label: "high", Desc: "Maximum deliberation · slowest"},
-			},
-		},
-	},
-
-	CatAutonomy: {
-		{
-			Label: "Autonomy Level", Key: "autonomyLevel", Kind: KindEnum, Default: "normal",
-			Options: []OptionDef{
-				{Value: "normal", Label: "normal", Desc: "Ask before every tool use  (default)"},
-				{Value: "spec", Label: "spec", Desc: "Plan first, then execute"},
-				{Value: "auto-low", Label: "auto-low", Desc: "Auto-approve low-risk actions"},
-				{Value: "auto-medium", Label: "auto-medium", Desc: "Auto-approve medium-risk actions"},
-				{Value: "auto-high", Label: "auto-high", Desc: "Auto-approve most actions"},
-			},
-		},
-	},
-
-	CatDisplay: {
-		{
-			Label: "Diff Mode", Key: "diffMode", Kind: KindEnum, Default: "github",
-			Options: []OptionDef{
-				{Value: "github", Label: "github", Desc: "Side-by-side GitHub-style  (default)"},
-				{Value: "unified", Label: "unified", Desc: "Traditional single-column"},
-			},
-		},
-		{
-			Label: "Todo Display", Key: "todoDisplayMode", Kind: KindEnum, Default: "pinned",
-			Options: []OptionDef{
-				{Value: "pinned", Label: "pinned", Desc: "Pinned above input area  (default)"},
-				{Value: "inline", Label: "inline", Desc: "Inline within message flow"},
-			},
-		},
-		{
-			Label: "Show AI Thinking", Key: "showThinkingInMainView", Kind: KindBool, Default: "off",
-		},
-	},
-
-	CatSound: {
-		{
-			Label: "Completion Sound", Key: "completionSound", Kind: KindEnum, Default: "fx-ok01",
-			Options: []OptionDef{
-				{Value: "fx-ok01", Label: "fx-ok01", Desc: "Soft success bloop  (default)"},
-				{Value: "fx-ack01", Label: "fx-ack01", Desc: "Tactile ripple feedback"},
-				{Value: "bell", Label: "bell", Desc: "System terminal bell"},
-				{Value: "off", Label: "off", Desc: "No sound"},
-				{Value: "__custom__", Label: "Custom file path...", Desc: "Provide your own .wav / .mp3"},
-			},
-		},
-		{
-			Label: "Input Awaiting Sound", Key: "awaitingInputSound", Kind: KindEnum, Default: "fx-ack01",
-			Options: []OptionDef{
-				{Value: "fx-ok01", Label: "fx-ok01", Desc: "Soft success bloop"},
-				{Value: "fx-ack01", Label: "fx-ack01", Desc: "Tactile ripple  (default)"},
-				{Value: "bell", Label: "bell", Desc: "System terminal bell"},
-				{Value: "off", Label: "off", Desc: "No sound"},
-				{Value: "__custom__", Label: "Custom file path...", Desc: "Provide your own .wav / .mp3"},
-			},
-		},
-		{
-			Label: "Sound Focus Mode", Key: "soundFocusMode", Kind: KindEnum, Default: "always",
-			Options: []OptionDef{
-				{Value: "always", Label: "always", Desc: "Play regardless of focus  (default)"},
-				{Value: "focused", Label: "focused", Desc: "Only when terminal is focused"},
-				{Value: "unfocused", Label: "unfocused", Desc: "Only when terminal is not focused"},
-			},
-		},
-	},
-
-	CatSecurity: {
-		{Label: "Droid Shield", Key: "enableDroidShield", Kind: KindBool, Default: "on",
-			Options: []OptionDef{{Value: "", Desc: "Secret scanning & git guardrails"}},
-		},
-		{Label: "Co-authored Commits", Key: "includeCoAuthoredByDroid", Kind: KindBool, Default: "on",
-			Options: []OptionDef{{Value: "", Desc: "Append co-author trailer to commits"}},
-		},
-		{Label: "Background Processes", Key: "allowBackgroundProcesses", Kind: KindBool, Default: "off",
-			Options: []OptionDef{{Value: "", Desc: "Allow Droid to spawn background procs"}},
-		},
-	},
-
-	CatBehavior: {
-		{Label: "Cloud Session Sync", Key: "cloudSessionSync", Kind: KindBool, Default: "on",
-			Options: []OptionDef{{Value: "", Desc: "Mirror CLI sessions to Factory web"}},
-		},
-		{Label: "IDE Auto-Connect", Key: "ideAutoConnect", Kind: KindBool, Default: "off",
-			Options: []OptionDef{{Value: "", Desc: "Auto-connect to IDE from any terminal"}},
-		},
-		{Label: "Custom Droids", Key: "enableCustomDroids", Kind: KindBool, Default: "on",
-			Options: []OptionDef{{Value: "", Desc: "Enable the Custom Droids feature"}},
-		},
-		{Label: "Hooks Disabled", Key: "hooksDisabled", Kind: KindBool, Default: "off",
-			Options: []OptionDef{{Value: "", Desc: "Globally disable all hooks execution"}},
-		},
-		{Label: "Spec Save", Key: "specSaveEnabled", Kind: KindBool, Default: "off",
-			Options: []OptionDef{{Value: "", Desc: "Persist spec outputs to disk"}},
-		},
-		{Label: "Spec Save Dir", Key: "specSaveDir", Kind: KindText, Default: ".factory/docs"},
-		{Label: "Readiness Report", Key: "enableReadinessReport", Kind: KindBool, Default: "off",
-			Options: []OptionDef{{Value: "", Desc: "Enable /readiness-report command"}},
-		},
-	},
+// lookupSetting returns the schema entry for a settings.json key.
+func lookupSetting(key string) (config.SettingSpec, bool) {
+	sc, _ := config.CurrentSchema()
+	return sc.Lookup(key)
 }
 
 // menuEntry is one row in the main dashboard menu.
@@ -198,13 +88,22 @@ type menuEntry struct {
 	label string
 }
 
-var menuEntries = []menuEntry{
-	{CatBYOK, "BYOK", "Custom Models"},
-	{CatModel, "MOD", "Model & Reasoning"},
-	{CatAutonomy, "AUTO", "Autonomy"},
-	{CatDisplay, "DISP", "Display"},
-	{CatSound, "SND", "Sound"},
-	{CatSecurity, "SEC", "Security"},
-	{CatBehavior, "BEHV", "Agent Behavior"},
-	{CatCommands, "CMD", "Command Policies"},
+// menuEntries lists BYOK first, then the schema's categories in order, then
+// Hooks, Droids, slash commands, MCP servers and the catch-all Advanced
+// screen.
+var menuEntries = buildMenuEntries()
+
+func buildMenuEntries() []menuEntry {
+	entries := []menuEntry{{CatBYOK, "BYOK", "Custom Models"}}
+	sc, _ := config.CurrentSchema()
+	for _, c := range sc.Categories {
+		entries = append(entries, menuEntry{Category(c.ID), c.Badge, c.Label})
+	}
+	return append(entries,
+		menuEntry{CatHooks, "HOOK", "Hooks"},
+		menuEntry{CatDroids, "DRD", "Droids"},
+		menuEntry{CatSlash, "SLSH", "Slash Commands"},
+		menuEntry{CatMCP, "MCP", "MCP Servers"},
+		menuEntry{CatOther, "ADV", "Advanced / Other"},
+	)
 }
@@ -0,0 +1,231 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ─────────────────────────────────────────────────────────────────────────────
+// JSON tree helpers for the Advanced / Other screen. Values are decoded JSON:
+// map[string]any, []any, string, float64, bool or nil. A path addresses a
+// node by object keys (string) and array indices (int).
+// ─────────────────────────────────────────────────────────────────────────────
+
+// treeRow is one visible line of the tree editor.
+type treeRow struct {
+	path  []any
+	depth int
+	label string
+	value any
+}
+
+// jsonKind names the JSON type of v.
+func jsonKind(v any) string {
+	switch v.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "bool"
+	case nil:
+		return "null"
+	}
+	return "unknown"
+}
+
+func isContainer(v any) bool {
+	switch v.(type) {
+	case map[string]any, []any:
+		return true
+	}
+	return false
+}
+
+// jsonPreview renders v on one line: scalars as JSON, containers by size.
+func jsonPreview(v any) string {
+	switch v := v.(type) {
+	case map[string]any:
+		return fmt.Sprintf("{%d keys}", len(v))
+	case []any:
+		return fmt.Sprintf("[%d items]", len(v))
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+// pathKey identifies a node for the expanded set, like a JSON pointer.
+func pathKey(path []any) string {
+	var sb strings.Builder
+	for _, p := range path {
+		sb.WriteString("/")
+		sb.WriteString(fmt.Sprint(p))
+	}
+	return sb.String()
+}
+
+// flattenTree lists the visible rows under root; open holds the pathKeys of
+// expanded containers.
+func flattenTree(root any, label string, open map[string]bool) []treeRow {
+	var rows []treeRow
+	var walk func(path []any, depth int, label string, v any)
+	walk = func(path []any, depth int, label string, v any) {
+		rows = append(rows, treeRow{path: path, depth: depth, label: label, value: v})
+		if !open[pathKey(path)] {
+			return
+		}
+		switch v := v.(type) {
+		case map[string]any:
+			keys := make([]string, 0, len(v))
+			for k := range v {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				walk(appendPath(path, k), depth+1, k, v[k])
+			}
+		case []any:
+			for i, e := range v {
+				walk(appendPath(path, i), depth+1, "["+strconv.Itoa(i)+"]", e)
+			}
+		}
+	}
+	walk(nil, 0, label, root)
+	return rows
+}
+
+// appendPath returns a new path; paths are kept in rows and must not share
+// backing arrays.
+func appendPath(path []any, elem any) []any {
+	out := make([]any, len(path), len(path)+1)
+	copy(out, path)
+	return append(out, elem)
+}
+
+// cloneJSON deep-copies a decoded JSON value.
+func cloneJSON(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for k, e := range v {
+			out[k] = cloneJSON(e)
+		}
+		return out
+	case []any:
+		out := make([]any, len(v))
+		for i, e := range v {
+			out[i] = cloneJSON(e)
+		}
+		return out
+	}
+	return v
+}
+
+// getPath returns the node at path, or nil past the end of an array.
+func getPath(root any, path []any) any {
+	v := root
+	for _, p := range path {
+		switch c := v.(type) {
+		case map[string]any:
+			v = c[p.(string)]
+		case []any:
+			i := p.(int)
+			if i >= len(c) {
+				return nil
+			}
+			v = c[i]
+		default:
+			return nil
+		}
+	}
+	return v
+}
+
+// setPath replaces the node at path and returns the new root. Setting a key
+// that does not exist adds it; setting index len(array) appends.
+func setPath(root any, path []any, val any) any {
+	if len(path) == 0 {
+		return val
+	}
+	switch c := root.(type) {
+	case map[string]any:
+		k := path[0].(string)
+		c[k] = setPath(c[k], path[1:], val)
+		return c
+	case []any:
+		i := path[0].(int)
+		if i == len(c) {
+			return append(c, setPath(nil, path[1:], val))
+		}
+		c[i] = setPath(c[i], path[1:], val)
+		return c
+	}
+	return root
+}
+
+// deletePath removes the node at path and returns the new root.
+func deletePath(root any, path []any) any {
+	if len(path) == 0 {
+		return nil
+	}
+	parent := getPath(root, path[:len(path)-1])
+	switch c := parent.(type) {
+	case map[string]any:
+		delete(c, path[len(path)-1].(string))
+		return root
+	case []any:
+		i := path[len(path)-1].(int)
+		return setPath(root, path[:len(path)-1], append(c[:i:i], c[i+1:]...))
+	}
+	return root
+}
+
+// parseJSONValue reads text as a JSON literal, or as a plain string when it
+// is not valid JSON.
+func parseJSONValue(text string) any {
+	var v any
+	if err := json.Unmarshal([]byte(text), &v); err != nil {
+		return text
+	}
+	return v
+}
+
+// parseLike converts text to the JSON type of old, so editing a number keeps
+// it a number. null leaves accept any JSON literal.
+func parseLike(old any, text string) (any, error) {
+	switch old.(type) {
+	case string:
+		return text, nil
+	case float64:
+		f, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", text)
+		}
+		return f, nil
+	case bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(text))
+		if err != nil {
+			return nil, fmt.Errorf("%q is not true or false", text)
+		}
+		return b, nil
+	}
+	return parseJSONValue(text), nil
+}
+
+// editText is the text shown in the input when editing a scalar.
+func editText(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return jsonPreview(v)
+}
@@ -1,11 +1,22 @@
 package ui
 
 import (
+	"context"
+
 	"github.com/charmbracelet/bubbles/spinner"
+	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/lipgloss"
 
 	"github.com/kaan-escober/wrench/internal/api"
 	"github.com/kaan-escober/wrench/internal/config"
+	"github.com/kaan-escober/wrench/internal/droids"
+	"github.com/kaan-escober/wrench/internal/hooks"
+	"github.com/kaan-escober/wrench/internal/keymap"
+	"github.com/kaan-escober/wrench/internal/mcp"
+	"github.com/kaan-escober/wrench/internal/policy"
+	"github.com/kaan-escober/wrench/internal/scope"
+	"github.com/kaan-escober/wrench/internal/slash"
 	"github.com/kaan-escober/wrench/internal/theme"
 )
 
@@ -32,6 +43,22 @@ const (
 	WizDone
 )
 
+// ─────────────────────────────────────────────────────────────────────────────
+// Droid wizard step enum (used when mode == ModeDroidWizard)
+// ─────────────────────────────────────────────────────────────────────────────
+
+type DroidStep int
+
+const (
+	DroidScope DroidStep = iota
+	DroidName
+	DroidDescription
+	DroidModel
+	DroidTools
+	DroidPrompt
+	DroidConfirm
+)
+
 // ─────────────────────────────────────────────────────────────────────────────
 // Custom list (shared navigation + multi-select component)
 // ─────────────────────────────────────────────────────────────────────────────
@@ -49,6 +76,8 @@ type customList struct {
 	multi    bool
 	height   int
 	offset   int
+	width    int  // screen width; 0 draws items untruncated
+	compact  bool // only the cursor row shows its sub column
 }
 
 func newList(items []listItem, multi bool, height int) customList {
@@ -73,6 +102,22 @@ func (l *customList) down() {
 	}
 }
 
+// focusValue moves the cursor to the item with the given value, if present.
+func (l *customList) focusValue(v string) {
+	for i, item := range l.items {
+		if item.value == v {
+			l.cursor = i
+			if l.cursor < l.offset {
+				l.offset = l.cursor
+			}
+			if l.cursor >= l.offset+l.height {
+				l.offset = l.cursor - l.height + 1
+			}
+			return
+		}
+	}
+}
+
 func (l *customList) toggleCurrent() {
 	if l.multi {
 		l.selected[l.cursor] = !l.selected[l.cursor]
@@ -118,9 +163,24 @@ func (l *customList) render(cursorActive bool) string {
 			prefix = "  "
 		}
 
-		label = theme.Primary.Render(item.label)
-		if item.sub != "" {
-			sub = "  " + theme.Muted.Render(item.sub)
+		// Labels are cut to the width first; the sub column takes what is
+		// left, and on narrow screens only the cursor row keeps it.
+		room := l.width - 4
+		if l.multi {
+			room -= 2
+		}
+		text, detail := item.label, item.sub
+		if l.width > 0 {
+			text = truncate(text, max(room, 1))
+			room -= lipgloss.Width(text) + 2
+			if l.compact && !isCursor || room < 8 {
+				detail = ""
+			}
+			detail = truncate(detail, room)
+		}
+		label = theme.Primary.Render(text)
+		if detail != "" {
+			sub = "  " + theme.Muted.Render(detail)
 		}
 
 		var line string
@@ -145,7 +205,7 @@ func (l *customList) render(cursorActive bool) string {
 				line = "  " + label + sub
 			}
 		}
-		out += line + "\n"
+		out += zoneMark(zoneList, i) + line + "\n"
 	}
 	if end < len(l.items) {
 		out += theme.Muted.Render("  ↓ more")
@@ -163,10 +223,65 @@ type settingsLoadedMsg struct {
 	raw      map[string]any
 }
 type modelsLoadedMsg struct {
+	seq          int
 	models       []api.ModelInfo
 	displayNames map[string]string
+	configured   map[string]bool // model IDs already in customModels for this base URL
+	err          error
 }
-type byokSavedMsg struct{ path string }
+type byokSavedMsg struct {
+	path    string
+	added   []string
+	skipped []string // already configured, not written again
+}
+type duplicatesMergedMsg struct{ removed int }
+type modelsReorderedMsg struct {
+	groups []config.ProviderGroup
+	focus  string   // list value to keep the cursor on
+	stale  []string // droids whose model is an ID that was renumbered
+}
+type variantsAddedMsg struct {
+	groups  []config.ProviderGroup
+	created []config.ModelConfig
+}
+type presetsLoadedMsg struct {
+	presets []policy.Preset
+	err     error // unreadable user preset files; the rest still loaded
+}
+type suggestionsLoadedMsg struct {
+	suggestions []policy.Suggestion
+	files       []string // history files that were read
+	err         error
+}
+type hooksLoadedMsg struct {
+	list []hooks.Hook
+	err  error // the hooks section could not be read; it is shown but not edited
+}
+type hookRanMsg struct{ result hooks.Result }
+type droidsLoadedMsg struct {
+	list  []droids.Droid
+	focus string // path of the droid to put the cursor on
+	err   error  // unreadable files; the rest still loaded
+}
+type droidCreatedMsg struct{ path string }
+type slashLoadedMsg struct {
+	list  []slash.Command
+	focus string // path of the command to put the cursor on
+	err   error  // unreadable folders or files; the rest still loaded
+}
+type slashChangedMsg struct {
+	focus string // path of the created or renamed command
+	flash string
+}
+type editorClosedMsg struct {
+	path string
+	err  error
+}
+type mcpLoadedMsg struct {
+	list []mcp.Server
+	err  error // mcp.json could not be read; it is shown but not edited
+}
+type mcpTestedMsg struct{ report mcp.Report }
 type settingsSavedMsg struct{}
 type clearFlashMsg struct{}
 type errMsg struct{ err error }
@@ -177,20 +292,24 @@ type errMsg struct{ err error }
 
 type Model struct {
 	width, height int
+	forceCompact  bool // --compact: the compact layout at any width
 
 	// ── App navigation ───────────────────────────────────────────────────────
 	mode AppMode
 
 	// ── Loaded settings ──────────────────────────────────────────────────────
-	settings config.Settings
-	rawCfg   map[string]any // preserves unknown fields
+	settings     config.Settings
+	rawCfg       map[string]any       // preserves unknown fields
+	customModels []config.ModelConfig // rawCfg's customModels, for the model picker
 
 	// ── Main menu ────────────────────────────────────────────────────────────
 	menuCursor int
+	menuOffset int
 
 	// ── Category view ────────────────────────────────────────────────────────
 	currentCat Category
 	catCursor  int
+	catOffset  int
 
 	// ── Option / bool picker ─────────────────────────────────────────────────
 	optionList  customList
@@ -200,11 +319,92 @@ type Model struct {
 	textInput textinput.Model
 
 	// ── Command policy editor ─────────────────────────────────────────────────
-	allowCmds   []string
-	denyCmds    []string
-	cmdFocusCol int
-	cmdCursor   int
-	cmdInput    textinput.Model
+	allowCmds    []string
+	denyCmds     []string
+	cmdFocusCol  int
+	cmdCursor    int
+	cmdInput     textinput.Model
+	cmdEditIdx   int          // entry being edited in ModeCommandAdd, -1 when adding
+	cmdMarked    map[int]bool // multi-selected entries of the focused column
+	cmdOffset    [2]int       // first visible row of each column
+	cmdFilter    textinput.Model
+	cmdPaste     textarea.Model
+	testInput    textinput.Model // command line checked by the policy tester
+	presets      []policy.Preset
+	presetList   customList
+	presetPlan   *policy.MergePlan // merge preview of the chosen preset, nil while picking
+	presetScroll int
+	suggestList  customList
+	historyFiles []string // nil while suggestions load
+
+	// ── Advanced / other keys ────────────────────────────────────────────────
+	otherList   customList      // top-level keys of rawCfg without a screen
+	treeKey     string          // key open in the tree editor
+	treeRoot    any             // working copy of rawCfg[treeKey]
+	treeOpen    map[string]bool // pathKeys of expanded containers
+	treeCursor  int
+	treeOffset  int
+	treeDirty   bool
+	otherPath   []any   // node being edited, added or deleted in the tree
+	otherAdding bool    // the input creates otherPath instead of editing it
+	otherNewKey string  // member name typed before the value of a new member
+	otherFrom   AppMode // screen that opened the input or confirmation
+
+	// ── Hooks ────────────────────────────────────────────────────────────────
+	hookList    []hooks.Hook
+	hookMissing map[string][]string // command → executables that were not found
+	hookCursor  int
+	hookLoadErr string     // why the hooks section cannot be edited
+	hookDraft   hooks.Hook // hook being added, edited or run
+	hookEditIdx int        // index of hookDraft in hookList, -1 when adding
+	hookForm    customList
+	hookField   string     // form field typed in ModeHookInput
+	hookPick    customList // event or template picker
+	hookPicking string     // "event" or "template"
+	hookFrom    AppMode    // screen the picker or run preview returns to
+	hookInput   []byte     // sample stdin of the run preview
+	hookResult  *hooks.Result
+	hookRunning bool
+
+	// ── Custom droids ───────────────────────────────────────────────────────
+	droidList   []droids.Droid
+	droidCursor int
+	droidStep   DroidStep
+	droidDraft  droids.Droid // droid being created or duplicated
+	droidPick   customList   // scope, model or tool picker of the wizard
+	droidPrompt textarea.Model
+
+	// ── Slash commands ───────────────────────────────────────────────────────
+	slashList     []slash.Command
+	slashCursor   int
+	slashPick     customList
+	slashPicking  string         // "template" or "scope"
+	slashTemplate slash.Template // template of the command being created
+	slashScope    scope.Scope    // scope of the command being created
+	slashRenaming bool           // ModeSlashInput renames the selected command
+
+	// ── MCP servers ──────────────────────────────────────────────────────────
+	mcpList     []mcp.Server
+	mcpProblems map[string][]string // server name → unset variables, missing command
+	mcpCursor   int
+	mcpLoadErr  string     // why mcp.json cannot be edited
+	mcpDraft    mcp.Server // server being added, edited or tested
+	mcpEditIdx  int        // index of mcpDraft in mcpList, -1 when adding
+	mcpForm     customList
+	mcpField    string // form field typed in ModeMCPInput or ModeMCPLines
+	mcpLines    textarea.Model
+	mcpFrom     AppMode // screen the launch test returns to
+	mcpReport   *mcp.Report
+	mcpTesting  bool
+
+	// ── Command palette ──────────────────────────────────────────────────────
+	paletteInput   textinput.Model
+	paletteEntries []paletteEntry // everything searchable, built when the palette opens
+	paletteHits    []paletteHit
+	paletteCursor  int
+	paletteOffset  int
+	paletteFrom    AppMode // screen esc returns to
+	paletteJump    string  // model target opened once the provider groups load
 
 	// ── BYOK wizard ──────────────────────────────────────────────────────────
 	byokStep        WizStep
@@ -221,11 +421,21 @@ type Model struct {
 	displayTitle    string
 	noAuth          bool
 	modelsEndpoint  string
+	modelsFormat    string
+
+	// ── Model fetch ──────────────────────────────────────────────────────────
+	fetchSeq    int                // bumped per fetch so stale results are dropped
+	fetchCancel context.CancelFunc // aborts the in-flight fetch
+	fetchFrom   WizStep            // step to return to when the fetch is cancelled
+	fetchErr    error              // last fetch failure, nil on success
 
 	availableModels   []api.ModelInfo
 	modelList         customList
 	modelDisplayNames map[string]string
 	selectedModels    []string
+	configuredModels  map[string]bool // fetched IDs already configured for baseURL
+	addedModels       []string
+	skippedModels     []string
 	maxOutputTokens   int
 	supportsImages    bool
 	savedPath         string
@@ -233,10 +443,15 @@ type Model struct {
 	// ── Model editor ─────────────────────────────────────────────────────────
 	editingModel config.ModelConfig
 	editFieldKey string
+	variant      config.VariantSpec // overrides collected by "Duplicate as variant"
 
 	// ── Spinner ───────────────────────────────────────────────────────────────
 	spinner spinner.Model
 
+	// ── Keys ─────────────────────────────────────────────────────────────────
+	keys     keymap.Map
+	showHelp bool // the ? overlay covers the screen
+
 	// ── Feedback ─────────────────────────────────────────────────────────────
 	err   string
 	flash string
@@ -256,25 +471,92 @@ func initialModel() Model {
 	ci.Width = 40
 	ci.Placeholder = "command or pattern"
 
+	pi := textinput.New()
+	pi.PromptStyle = theme.Accent
+	pi.TextStyle = theme.Primary
+	pi.Prompt = theme.Prompt
+	pi.Width = 60
+	pi.Placeholder = "git status && npm test"
+
+	fi := textinput.New()
+	fi.PromptStyle = theme.Accent
+	fi.TextStyle = theme.Primary
+	fi.Prompt = "/ "
+	fi.Width = 40
+	fi.Placeholder = "filter entries"
+
+	qi := textinput.New()
+	qi.PromptStyle = theme.Accent
+	qi.TextStyle = theme.Primary
+	qi.Prompt = theme.Prompt
+	qi.Width = 50
+	qi.Placeholder = "search settings, models and actions"
+
+	ta := textarea.New()
+	ta.Placeholder = "one command or pattern per line"
+	ta.ShowLineNumbers = false
+	ta.Prompt = "  "
+	ta.CharLimit = 0
+	ta.MaxHeight = 0
+	ta.FocusedStyle.CursorLine = lipgloss.NewStyle()
+	ta.FocusedStyle.Text = theme.Primary
+	ta.FocusedStyle.Placeholder = theme.Muted
+	ta.BlurredStyle = ta.FocusedStyle
+
+	dp := textarea.New()
+	dp.Placeholder = "You are a code reviewer. Read the diff and …"
+	dp.ShowLineNumbers = false
+	dp.Prompt = "  "
+	dp.CharLimit = 0
+	dp.MaxHeight = 0
+	dp.FocusedStyle = ta.FocusedStyle
+	dp.BlurredStyle = ta.FocusedStyle
+
+	ml := textarea.New()
+	ml.ShowLineNumbers = false
+	ml.Prompt = "  "
+	ml.CharLimit = 0
+	ml.MaxHeight = 0
+	ml.FocusedStyle = ta.FocusedStyle
+	ml.BlurredStyle = ta.FocusedStyle
+
 	sp := spinner.New()
 	sp.Spinner = spinner.Dot
 	sp.Style = theme.Accent
 
-	return Model{
+	m := Model{
 		mode:            ModeMenu,
 		rawCfg:          map[string]any{},
 		maxOutputTokens: 16384,
 		textInput:       ti,
 		cmdInput:        ci,
+		testInput:       pi,
+		cmdFilter:       fi,
+		paletteInput:    qi,
+		cmdPaste:        ta,
+		droidPrompt:     dp,
+		mcpLines:        ml,
+		cmdEditIdx:      -1,
+		hookEditIdx:     -1,
+		mcpEditIdx:      -1,
 		spinner:         sp,
 	}
+	if _, err := config.CurrentSchema(); err != nil {
+		m.err = err.Error()
+	}
+	keys, err := keymap.Load()
+	m.keys = keys
+	if err != nil && m.err == "" {
+		m.err = err.Error()
+	}
+	return m
 }
 
-// currentSettingDef returns the SettingDef at catCursor in currentCat.
-func (m Model) currentSettingDef() SettingDef {
-	defs := categorySettings[m.currentCat]
+// currentSettingDef returns the schema entry at catCursor in currentCat.
+func (m Model) currentSettingDef() config.SettingSpec {
+	defs := settingsIn(m.currentCat)
 	if m.catCursor >= 0 && m.catCursor < len(defs) {
 		return defs[m.catCursor]
 	}
-	return SettingDef{}
+	return config.SettingSpec{}
 }
@@ -0,0 +1,274 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// ─── Mouse ────────────────────────────────────────────────────────────────────
+//
+// Views mark the start of every clickable row with a zone mark. View strips
+// the marks before the frame is drawn; a click renders the frame again and
+// looks up the mark on the clicked line. A click then runs through the key
+// handlers, as the key that does the same thing, so mouse and keyboard cannot
+// drift apart.
+
+// zoneKind says what a clickable zone is.
+type zoneKind int
+
+const (
+	zoneMenu    zoneKind = iota + 1 // a row of the main menu
+	zoneSetting                     // a row of a settings category
+	zoneList                        // an item of the screen's customList
+	zoneRow                         // a row of the screen's own list: hooks, droids, MCP servers, …
+	zoneAllow                       // an entry of the allowlist column; -1 is its header
+	zoneDeny                        // an entry of the denylist column; -1 is its header
+	zoneYes                         // the delete button of a confirm screen
+	zoneNo                          // the keep button of a confirm screen
+)
+
+// zoneMark starts a zone. It runs to the next mark on the same line, or the end
+// of the line. Marks are escape sequences with no width, so they do not move
+// anything while the frame is laid out.
+func zoneMark(kind zoneKind, index int) string {
+	return fmt.Sprintf("\x1b[%d;%dz", kind, index)
+}
+
+var markRe = regexp.MustCompile(`\x1b\[(\d+);(-?\d+)z`)
+
+type zone struct {
+	kind  zoneKind
+	index int
+	x, y  int
+}
+
+// scanZones removes the marks from a frame and returns where they were.
+func scanZones(frame string) (string, []zone) {
+	lines := strings.Split(frame, "\n")
+	var zones []zone
+	for y, line := range lines {
+		locs := markRe.FindAllStringSubmatchIndex(line, -1)
+		if locs == nil {
+			continue
+		}
+		var sb strings.Builder
+		last := 0
+		for _, loc := range locs {
+			sb.WriteString(line[last:loc[0]])
+			kind, _ := strconv.Atoi(line[loc[2]:loc[3]])
+			index, _ := strconv.Atoi(line[loc[4]:loc[5]])
+			zones = append(zones, zone{kind: zoneKind(kind), index: index, x: lipgloss.Width(sb.String()), y: y})
+			last = loc[1]
+		}
+		sb.WriteString(line[last:])
+		lines[y] = sb.String()
+	}
+	return strings.Join(lines, "\n"), zones
+}
+
+// zoneAt returns the zone under the cell x, y.
+func zoneAt(zones []zone, x, y int) (zone, bool) {
+	var hit zone
+	found := false
+	for _, z := range zones {
+		if z.y != y {
+			continue
+		}
+		// The first zone of a line also takes the cells left of its mark.
+		if !found || z.x <= x {
+			hit, found = z, true
+		}
+	}
+	return hit, found
+}
+
+func (m Model) handleMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if msg.Action != tea.MouseActionPress {
+		return m, nil
+	}
+	switch msg.Button {
+	case tea.MouseButtonWheelUp, tea.MouseButtonWheelDown:
+		if m.showHelp {
+			return m, nil
+		}
+		up, down := m.keys.Up, m.keys.Down
+		if m.screen().Typing {
+			up, down = m.keys.PrevResult, m.keys.NextResult
+		}
+		if msg.Button == tea.MouseButtonWheelUp {
+			return m.press(up)
+		}
+		return m.press(down)
+	case tea.MouseButtonLeft:
+		if m.showHelp {
+			m.showHelp = false
+			return m, nil
+		}
+		if msg.X >= m.navWidth() {
+			return m, nil // the detail pane
+		}
+		_, zones := scanZones(m.frame())
+		if z, ok := zoneAt(zones, msg.X, msg.Y); ok {
+			return m.click(z)
+		}
+	}
+	return m, nil
+}
+
+// click acts on a clicked zone. A click moves the cursor to the row; a click
+// on the row already under the cursor opens it, as enter does. Rows with a
+// checkbox toggle on the first click.
+func (m Model) click(z zone) (tea.Model, tea.Cmd) {
+	switch z.kind {
+	case zoneMenu:
+		if m.mode != ModeMenu || z.index >= len(menuEntries) {
+			break
+		}
+		if m.menuCursor == z.index {
+			return m.press(m.keys.Select)
+		}
+		m.menuCursor = z.index
+	case zoneSetting:
+		if m.mode != ModeCategory || z.index >= len(settingsIn(m.currentCat)) {
+			break
+		}
+		if m.catCursor == z.index {
+			return m.press(m.keys.Select)
+		}
+		m.catCursor = z.index
+	case zoneList:
+		l := m.activeList()
+		if l == nil || z.index >= len(l.items) {
+			break
+		}
+		again := l.cursor == z.index
+		l.cursor = z.index
+		if l.multi {
+			return m.press(m.keys.Toggle)
+		}
+		if again {
+			return m.press(m.keys.Select)
+		}
+	case zoneRow:
+		cursor, n := m.rowCursor()
+		if cursor == nil || z.index >= n {
+			break
+		}
+		if *cursor == z.index {
+			return m.press(m.keys.Select)
+		}
+		*cursor = z.index
+	case zoneAllow, zoneDeny:
+		if m.mode != ModeCommandEdit {
+			break
+		}
+		col := 0
+		if z.kind == zoneDeny {
+			col = 1
+		}
+		if col != m.cmdFocusCol {
+			m.cmdFocusCol = col
+			m.cmdCursor = 0
+			m.cmdMarked = nil
+		} else if z.index == m.cmdCursor {
+			return m.press(m.keys.Select)
+		}
+		if z.index >= 0 && z.index < len(m.visibleCmds(col)) {
+			m.cmdCursor = z.index
+		}
+		m.cmdOffset[m.cmdFocusCol], _ = m.cmdWindow(m.cmdFocusCol)
+	case zoneYes:
+		return m.press(m.keys.Yes)
+	case zoneNo:
+		return m.press(m.keys.No)
+	}
+	return m, nil
+}
+
+// activeList returns the customList the current screen shows, if any.
+func (m *Model) activeList() *customList {
+	switch m.mode {
+	case ModeOptionPick, ModeBoolPick:
+		return &m.optionList
+	case ModeCommandPreset:
+		if m.presetPlan == nil {
+			return &m.presetList
+		}
+	case ModeCommandSuggest:
+		return &m.suggestList
+	case ModeHookForm:
+		return &m.hookForm
+	case ModeHookPick:
+		return &m.hookPick
+	case ModeMCPForm:
+		return &m.mcpForm
+	case ModeSlashPick:
+		return &m.slashPick
+	case ModeDroidWizard:
+		return &m.droidPick
+	case ModeOther:
+		return &m.otherList
+	case ModeBYOK:
+		switch m.byokStep {
+		case WizProvider:
+			return &m.providerList
+		case WizModels:
+			return &m.modelList
+		}
+		return &m.detailList
+	}
+	return nil
+}
+
+// rowCursor returns the cursor of the list the current screen draws itself
+// and how many rows the list has now. A zone of an older frame can point past
+// the end when the list was reloaded in between.
+func (m *Model) rowCursor() (*int, int) {
+	switch m.mode {
+	case ModeHooks:
+		return &m.hookCursor, len(m.hookList)
+	case ModeSlash:
+		return &m.slashCursor, len(m.slashList)
+	case ModeMCP:
+		return &m.mcpCursor, len(m.mcpList)
+	case ModeDroids:
+		return &m.droidCursor, len(m.droidList)
+	case ModeOtherTree:
+		return &m.treeCursor, len(m.treeRows())
+	case ModePalette:
+		return &m.paletteCursor, len(m.paletteHits)
+	}
+	return nil, 0
+}
+
+// press runs the first key of b through the key handlers.
+func (m Model) press(b key.Binding) (tea.Model, tea.Cmd) {
+	for _, name := range b.Keys() {
+		if msg, ok := keyMsg(name); ok {
+			return m.handleKey(msg)
+		}
+	}
+	return m, nil
+}
+
+// keyMsg builds the key message Bubble Tea sends for a key name such as
+// "enter", "alt+v" or "x".
+func keyMsg(name string) (tea.KeyMsg, bool) {
+	rest, alt := strings.CutPrefix(name, "alt+")
+	if r := []rune(rest); len(r) == 1 {
+		return tea.KeyMsg{Type: tea.KeyRunes, Runes: r, Alt: alt}, true
+	}
+	for t := tea.KeyType(-128); t < 128; t++ {
+		msg := tea.KeyMsg{Type: t, Alt: alt}
+		if t != tea.KeyRunes && msg.String() == name {
+			return msg, true
+		}
+	}
+	return tea.KeyMsg{}, false
+}
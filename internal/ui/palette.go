@@ -0,0 +1,228 @@
+package ui
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/kaan-escober/wrench/internal/config"
+)
+
+// ─── Command palette ──────────────────────────────────────────────────────────
+
+// paletteEntry is one thing the palette can jump to: a screen, a setting, a
+// custom model or an action.
+type paletteEntry struct {
+	badge  string // category badge shown in front of the title
+	title  string
+	detail string // muted text after the title
+	target string // "cat:<id>", "setting:<key>", "model:<id>", "delete:<id>" or "action:<name>"
+	fields []paletteField
+}
+
+// paletteField is text a query is matched against. Short names match
+// fuzzily; descriptions only match a whole word or part of one, or every
+// short query would hit some long description.
+type paletteField struct {
+	text  string
+	fuzzy bool
+	hint  string // shown when the field matched but is not on screen, e.g. "option: spec"
+}
+
+// paletteHit is an entry that matched the query.
+type paletteHit struct {
+	entry paletteEntry
+	score int
+	hint  string
+}
+
+// buildPaletteEntries lists everything the palette searches: the screens of
+// the main menu, every visible setting, every custom model and the actions
+// that start a wizard.
+func (m Model) buildPaletteEntries() []paletteEntry {
+	var out []paletteEntry
+	for _, e := range menuEntries {
+		out = append(out, paletteEntry{
+			badge:  e.badge,
+			title:  e.label,
+			detail: "screen",
+			target: "cat:" + string(e.cat),
+			fields: []paletteField{{text: e.label, fuzzy: true}, {text: e.badge, fuzzy: true}},
+		})
+	}
+
+	sc, _ := config.CurrentSchema()
+	for _, c := range sc.Categories {
+		for _, def := range sc.InCategory(c.ID) {
+			fields := []paletteField{
+				{text: def.Label, fuzzy: true},
+				{text: def.Key, fuzzy: true},
+			}
+			for _, o := range def.Options {
+				fields = append(fields, paletteField{text: o.Title(), fuzzy: true, hint: "option: " + o.Title()})
+				if o.Value != o.Title() {
+					fields = append(fields, paletteField{text: o.Value, fuzzy: true, hint: "option: " + o.Title()})
+				}
+				if o.Desc != "" {
+					fields = append(fields, paletteField{text: o.Desc, hint: o.Title() + " · " + o.Desc})
+				}
+			}
+			if def.Description != "" {
+				fields = append(fields, paletteField{text: def.Description, hint: def.Description})
+			}
+			out = append(out, paletteEntry{
+				badge:  c.Badge,
+				title:  def.Label,
+				detail: def.Key,
+				target: "setting:" + def.Key,
+				fields: fields,
+			})
+		}
+	}
+
+	for _, cm := range m.customModels {
+		name := orDef(cm.DisplayName, cm.Model)
+		names := []paletteField{{text: name, fuzzy: true}, {text: cm.Model, fuzzy: true}, {text: cm.ID, fuzzy: true, hint: cm.ID}}
+		out = append(out,
+			paletteEntry{
+				badge:  "BYOK",
+				title:  name,
+				detail: cm.Model,
+				target: "model:" + cm.ID,
+				fields: append([]paletteField{{text: "edit model", fuzzy: true}}, names...),
+			},
+			paletteEntry{
+				badge:  "BYOK",
+				title:  "Delete " + name,
+				detail: "remove it from customModels",
+				target: "delete:" + cm.ID,
+				fields: append([]paletteField{{text: "delete model", fuzzy: true}}, names...),
+			},
+		)
+	}
+
+	actions := []paletteEntry{
+		{badge: "BYOK", title: "Add a provider", detail: "fetch its models into customModels", target: "action:add-provider"},
+		{badge: "CMD", title: "Test a command", detail: "check a command line against the allow and deny lists", target: "action:test-command"},
+		{badge: "DRD", title: "New droid", detail: "create a custom droid", target: "action:new-droid"},
+		{badge: "SLSH", title: "New slash command", detail: "create a /command from a template", target: "action:new-slash"},
+	}
+	if m.mcpLoadErr == "" {
+		actions = append(actions, paletteEntry{badge: "MCP", title: "Add an MCP server", detail: "add a stdio or http server to mcp.json", target: "action:add-mcp"})
+	}
+	for _, a := range actions {
+		a.fields = []paletteField{{text: a.title, fuzzy: true}, {text: a.detail}}
+		out = append(out, a)
+	}
+	return out
+}
+
+// filterPalette returns the entries matching every word of query, best
+// first. An empty query keeps all entries in their order.
+func filterPalette(entries []paletteEntry, query string) []paletteHit {
+	terms := strings.Fields(strings.ToLower(query))
+	var out []paletteHit
+	for _, e := range entries {
+		hit := paletteHit{entry: e}
+		matched := true
+		for _, term := range terms {
+			best, hint, ok := 0, "", false
+			for i, f := range e.fields {
+				score, found := matchField(term, f)
+				if !found {
+					continue
+				}
+				if i == 0 {
+					score += 10 // the title is what users remember
+				}
+				if !ok || score > best {
+					best, hint, ok = score, f.hint, true
+				}
+			}
+			if !ok {
+				matched = false
+				break
+			}
+			hit.score += best
+			if hit.hint == "" {
+				hit.hint = hint
+			}
+		}
+		if matched {
+			out = append(out, hit)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].score > out[j].score })
+	return out
+}
+
+func matchField(term string, f paletteField) (int, bool) {
+	if f.fuzzy {
+		return fuzzyScore(term, f.text)
+	}
+	if strings.Contains(strings.ToLower(f.text), term) {
+		return 1, true
+	}
+	return 0, false
+}
+
+// fuzzyScore matches pattern, which must be lower case, as a subsequence of
+// s ignoring case. Runs of consecutive letters and letters that start a
+// word (after a space, a dot or a camelCase hump) score higher, so "tdd"
+// prefers "Todo Display" over "autoUpdated". Every start of the first
+// letter is tried and the best score kept.
+func fuzzyScore(pattern, s string) (int, bool) {
+	p := []rune(pattern)
+	if len(p) == 0 {
+		return 0, true
+	}
+	text := []rune(s)
+	lower := []rune(strings.ToLower(s))
+	if len(lower) != len(text) {
+		lower = text // a case mapping changed the length: match as written
+	}
+
+	best, found := 0, false
+	for start := range lower {
+		if lower[start] != p[0] {
+			continue
+		}
+		score, pi, prev := 0, 0, -2
+		for i := start; i < len(lower) && pi < len(p); i++ {
+			if lower[i] != p[pi] {
+				continue
+			}
+			score++
+			if i == prev+1 {
+				score += 4
+			}
+			if wordStart(text, i) {
+				score += 6
+			}
+			if i == 0 {
+				score += 4
+			}
+			if prev >= 0 {
+				score -= min(i-prev-1, 3)
+			}
+			prev = i
+			pi++
+		}
+		if pi == len(p) && (!found || score > best) {
+			best, found = score, true
+		}
+	}
+	return best, found
+}
+
+// wordStart reports whether text[i] begins a word.
+func wordStart(text []rune, i int) bool {
+	if i == 0 {
+		return true
+	}
+	prev, cur := text[i-1], text[i]
+	if !unicode.IsLetter(prev) && !unicode.IsDigit(prev) {
+		return true
+	}
+	return unicode.IsLower(prev) && unicode.IsUpper(cur)
+}
@@ -1,13 +1,78 @@
 package ui
 
-import tea "github.com/charmbracelet/bubbletea"
-
-// Run starts the TUI program and blocks until it exits.
-func Run() error {
-	p := tea.NewProgram(
-		initialModel(),
-		tea.WithAltScreen(),
-	)
-	_, err := p.Run()
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+
+	"github.com/kaan-escober/wrench/internal/theme"
+)
+
+// Run starts the TUI program and blocks until it exits. args are the TUI's
+// flags: --theme, --color, --no-mouse and --compact.
+func Run(args []string) error {
+	fs := flag.NewFlagSet("wrench", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	themeName := fs.String("theme", os.Getenv("WRENCH_THEME"), "")
+	color := fs.String("color", "auto", "")
+	noMouse := fs.Bool("no-mouse", false, "")
+	compact := fs.Bool("compact", false, "")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unknown command %q", fs.Arg(0))
+	}
+	themeErr, err := setupTheme(*themeName, *color)
+	if err != nil {
+		return err
+	}
+
+	m := initialModel()
+	m.forceCompact = *compact
+	if themeErr != nil && m.err == "" {
+		m.err = themeErr.Error()
+	}
+	opts := []tea.ProgramOption{tea.WithAltScreen()}
+	if !*noMouse {
+		// Mouse reporting takes over the terminal's own text selection;
+		// most terminals still select with shift held.
+		opts = append(opts, tea.WithMouseCellMotion())
+	}
+	_, err = tea.NewProgram(m, opts...).Run()
 	return err
 }
+
+// setupTheme applies the theme for --theme and --color. NO_COLOR and
+// --color=never use the monochrome theme whatever --theme says; it keeps
+// bold and reverse, which NO_COLOR allows, so the cursor stays visible. A
+// theme that cannot be loaded is returned as themeErr for the TUI to show.
+func setupTheme(name, color string) (themeErr, err error) {
+	switch color {
+	case "auto":
+		if os.Getenv("NO_COLOR") != "" {
+			color = "never"
+		}
+	case "always":
+		lipgloss.SetColorProfile(termenv.ANSI256)
+	case "never":
+	default:
+		return nil, fmt.Errorf("--color: %q is not auto, always or never", color)
+	}
+	if color == "never" {
+		lipgloss.SetColorProfile(termenv.ANSI)
+		theme.Apply(theme.Monochrome)
+		return nil, nil
+	}
+	// Ask the terminal for its background now, before the program owns
+	// stdin; auto and high-contrast read the cached answer.
+	lipgloss.HasDarkBackground()
+	t, themeErr := theme.Load(name)
+	theme.Apply(t)
+	return themeErr, nil
+}
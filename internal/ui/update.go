@@ -1,17 +1,34 @@
 package ui
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 
 	"github.com/kaan-escober/wrench/internal/api"
 	"github.com/kaan-escober/wrench/internal/config"
+	"github.com/kaan-escober/wrench/internal/droids"
+	"github.com/kaan-escober/wrench/internal/hooks"
+	"github.com/kaan-escober/wrench/internal/mcp"
+	"github.com/kaan-escober/wrench/internal/policy"
 	"github.com/kaan-escober/wrench/internal/providers"
+	"github.com/kaan-escober/wrench/internal/scope"
+	"github.com/kaan-escober/wrench/internal/slash"
+	"github.com/kaan-escober/wrench/internal/theme"
 )
 
 // ─────────────────────────────────────────────────────────────────────────────
@@ -21,6 +38,7 @@ import (
 func (m Model) Init() tea.Cmd {
 	return tea.Batch(
 		loadAllSettings(),
+		loadMCP(),
 		m.spinner.Tick,
 	)
 }
@@ -30,54 +48,202 @@ func (m Model) Init() tea.Cmd {
 // ─────────────────────────────────────────────────────────────────────────────
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	next, cmd := m.update(msg)
+	m = next.(Model)
+	m.fitLists()
+	return m, cmd
+}
+
+func (m Model) update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 
 	case tea.WindowSizeMsg:
+		// Lists are sized by fitLists after every update.
 		m.width, m.height = msg.Width, msg.Height
-		m.providerList.height = listHeight(m.height)
-		m.modelList.height = listHeight(m.height)
-		m.optionList.height = listHeight(m.height)
+		inputW := max(m.width-8, 10)
+		m.textInput.Width = min(50, inputW)
+		m.cmdInput.Width = min(40, inputW)
+		m.testInput.Width = min(60, inputW)
+		m.cmdFilter.Width = min(40, inputW)
+		m.paletteInput.Width = min(50, inputW)
+		m.droidPrompt.SetWidth(max(m.width-6, 20))
+		m.droidPrompt.SetHeight(max(listHeight(m.height)-4, 3))
+		m.mcpLines.SetWidth(max(m.width-6, 20))
+		m.mcpLines.SetHeight(max(listHeight(m.height)-4, 3))
+		m.cmdPaste.SetWidth(max(m.width-6, 20))
+		m.cmdPaste.SetHeight(max(listHeight(m.height)-4, 3))
 		return m, nil
 
 	case tea.KeyMsg:
-		if msg.String() == "ctrl+c" {
+		if key.Matches(msg, m.keys.Quit) {
 			return m, tea.Quit
 		}
 		return m.handleKey(msg)
 
+	case tea.MouseMsg:
+		return m.handleMouse(msg)
+
 	case settingsLoadedMsg:
 		m.settings = msg.settings
 		m.rawCfg = msg.raw
+		m.customModels, _ = config.ParseCustomModels(msg.raw)
 		return m, nil
 
 	case groupsLoadedMsg:
 		m.providerGroups = msg.groups
 		m.providerList = buildProviderList(msg.groups)
 		m.providerList.height = listHeight(m.height)
+		if m.paletteJump != "" {
+			return m.paletteFinishJump()
+		}
 		return m, nil
 
 	case modelDeletedMsg:
 		m.byokStep = WizProvider
 		m.flash = "  ✓ Model deleted"
-		return m, tea.Batch(loadProviderGroups(), clearFlashAfter())
+		if msg.clearedDefault {
+			m.flash += " · it was the default model, so Droid's default applies again"
+		}
+		return m, tea.Batch(loadProviderGroups(), loadAllSettings(), clearFlashAfter())
 
 	case modelsLoadedMsg:
+		if msg.seq != m.fetchSeq || m.byokStep != WizFetching {
+			return m, nil // cancelled or superseded
+		}
+		m.fetchCancel = nil
+		m.fetchErr = msg.err
 		m.availableModels = msg.models
 		m.modelDisplayNames = msg.displayNames
-		m.modelList = buildModelList(msg.models)
+		m.configuredModels = msg.configured
+		m.modelList = buildModelList(msg.models, msg.configured)
 		m.modelList.height = listHeight(m.height)
 		m.byokStep = WizModels
+		if len(msg.models) == 0 {
+			m.focusInput("model-id", "")
+		}
 		return m, nil
 
 	case byokSavedMsg:
 		m.savedPath = msg.path
+		m.addedModels = msg.added
+		m.skippedModels = msg.skipped
 		m.byokStep = WizDone
 		m.detailList = buildDoneList()
 		return m, loadAllSettings()
 
+	case modelsReorderedMsg:
+		m.refreshGroups(msg.groups, msg.focus)
+		if len(msg.stale) > 0 {
+			m.err = "droid model not updated: " + strings.Join(msg.stale, "; ")
+		}
+		return m, loadAllSettings()
+
+	case variantsAddedMsg:
+		m.byokStep = WizGroupDetail
+		m.refreshGroups(msg.groups, "model:"+msg.created[0].ID)
+		m.flash = "  ✓ Variant added as " + msg.created[0].ID
+		return m, tea.Batch(loadAllSettings(), clearFlashAfter())
+
+	case duplicatesMergedMsg:
+		m.flash = fmt.Sprintf("  ✓ Merged %d duplicate model(s)", msg.removed)
+		return m, tea.Batch(loadProviderGroups(), loadAllSettings(), clearFlashAfter())
+
+	case presetsLoadedMsg:
+		m.presets = msg.presets
+		m.presetList = buildPresetList(msg.presets)
+		m.presetList.height = listHeight(m.height)
+		if msg.err != nil {
+			m.err = msg.err.Error()
+		}
+		return m, nil
+
+	case suggestionsLoadedMsg:
+		if msg.err != nil {
+			m.err = msg.err.Error()
+		}
+		m.historyFiles = msg.files
+		if m.historyFiles == nil {
+			m.historyFiles = []string{}
+		}
+		m.suggestList = buildSuggestionList(msg.suggestions)
+		m.suggestList.height = listHeight(m.height)
+		return m, nil
+
+	case hooksLoadedMsg:
+		m.hookLoadErr = ""
+		if msg.err != nil {
+			m.hookLoadErr = msg.err.Error()
+		}
+		m.hookList = msg.list
+		m.hookCursor = min(m.hookCursor, max(len(m.hookList)-1, 0))
+		m.refreshHookMissing()
+		return m, nil
+
+	case hookRanMsg:
+		m.hookRunning = false
+		m.hookResult = &msg.result
+		return m, nil
+
+	case slashLoadedMsg:
+		if msg.err != nil {
+			m.err = msg.err.Error()
+		}
+		m.slashList = msg.list
+		m.slashCursor = min(m.slashCursor, max(len(m.slashList)-1, 0))
+		for i, c := range m.slashList {
+			if c.Path == msg.focus {
+				m.slashCursor = i
+			}
+		}
+		return m, nil
+
+	case slashChangedMsg:
+		m.mode = ModeSlash
+		m.flash = msg.flash
+		return m, tea.Batch(loadSlash(msg.focus), clearFlashAfter())
+
+	case editorClosedMsg:
+		if msg.err != nil {
+			m.err = "editor: " + msg.err.Error()
+		}
+		return m, loadSlash(msg.path)
+
+	case mcpLoadedMsg:
+		m.mcpLoadErr = ""
+		if msg.err != nil {
+			m.mcpLoadErr = msg.err.Error()
+		}
+		m.mcpList = msg.list
+		m.mcpCursor = min(m.mcpCursor, max(len(m.mcpList)-1, 0))
+		m.refreshMCPProblems()
+		return m, nil
+
+	case mcpTestedMsg:
+		m.mcpTesting = false
+		m.mcpReport = &msg.report
+		return m, nil
+
+	case droidsLoadedMsg:
+		if msg.err != nil {
+			m.err = msg.err.Error()
+		}
+		m.droidList = msg.list
+		m.droidCursor = min(m.droidCursor, max(len(m.droidList)-1, 0))
+		for i, d := range m.droidList {
+			if d.Path == msg.focus {
+				m.droidCursor = i
+			}
+		}
+		return m, nil
+
+	case droidCreatedMsg:
+		m.mode = ModeDroids
+		m.flash = "  ✓ Created " + msg.path
+		return m, tea.Batch(m.loadDroids(msg.path), clearFlashAfter())
+
 	case settingsSavedMsg:
 		m.flash = "  ✓ Saved"
-		return m, clearFlashAfter()
+		return m, tea.Batch(loadAllSettings(), clearFlashAfter())
 
 	case clearFlashMsg:
 		m.flash = ""
@@ -104,6 +270,31 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.cmdInput, c = m.cmdInput.Update(msg)
 		cmds = append(cmds, c)
 	}
+	if m.testInput.Focused() {
+		var c tea.Cmd
+		m.testInput, c = m.testInput.Update(msg)
+		cmds = append(cmds, c)
+	}
+	if m.cmdFilter.Focused() {
+		var c tea.Cmd
+		m.cmdFilter, c = m.cmdFilter.Update(msg)
+		cmds = append(cmds, c)
+	}
+	if m.cmdPaste.Focused() {
+		var c tea.Cmd
+		m.cmdPaste, c = m.cmdPaste.Update(msg)
+		cmds = append(cmds, c)
+	}
+	if m.droidPrompt.Focused() {
+		var c tea.Cmd
+		m.droidPrompt, c = m.droidPrompt.Update(msg)
+		cmds = append(cmds, c)
+	}
+	if m.mcpLines.Focused() {
+		var c tea.Cmd
+		m.mcpLines, c = m.mcpLines.Update(msg)
+		cmds = append(cmds, c)
+	}
 	return m, tea.Batch(cmds...)
 }
 
@@ -114,6 +305,15 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	m.err = ""
 
+	if m.showHelp {
+		m.showHelp = false // any key closes the overlay
+		return m, nil
+	}
+	if key.Matches(msg, m.keys.Help) && !m.screen().Typing {
+		m.showHelp = true
+		return m, nil
+	}
+
 	switch m.mode {
 	case ModeMenu:
 		return m.handleMenuKey(msg)
@@ -129,6 +329,62 @@ func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m.handleCommandEditKey(msg)
 	case ModeCommandAdd:
 		return m.handleCommandAddKey(msg)
+	case ModeCommandTest:
+		return m.handleCommandTestKey(msg)
+	case ModeCommandPreset:
+		return m.handleCommandPresetKey(msg)
+	case ModeCommandSuggest:
+		return m.handleCommandSuggestKey(msg)
+	case ModeCommandPaste:
+		return m.handleCommandPasteKey(msg)
+	case ModeCommandFilter:
+		return m.handleCommandFilterKey(msg)
+	case ModeHooks:
+		return m.handleHooksKey(msg)
+	case ModeHookForm:
+		return m.handleHookFormKey(msg)
+	case ModeHookInput:
+		return m.handleHookInputKey(msg)
+	case ModeHookPick:
+		return m.handleHookPickKey(msg)
+	case ModeHookRun:
+		return m.handleHookRunKey(msg)
+	case ModeHookConfirm:
+		return m.handleHookConfirmKey(msg)
+	case ModeSlash:
+		return m.handleSlashKey(msg)
+	case ModeSlashPick:
+		return m.handleSlashPickKey(msg)
+	case ModeSlashInput:
+		return m.handleSlashInputKey(msg)
+	case ModeSlashConfirm:
+		return m.handleSlashConfirmKey(msg)
+	case ModeMCP:
+		return m.handleMCPKey(msg)
+	case ModeMCPForm:
+		return m.handleMCPFormKey(msg)
+	case ModeMCPInput:
+		return m.handleMCPInputKey(msg)
+	case ModeMCPLines:
+		return m.handleMCPLinesKey(msg)
+	case ModeMCPTest:
+		return m.handleMCPTestKey(msg)
+	case ModeMCPConfirm:
+		return m.handleMCPConfirmKey(msg)
+	case ModeDroids:
+		return m.handleDroidsKey(msg)
+	case ModeDroidWizard:
+		return m.handleDroidWizardKey(msg)
+	case ModeOther:
+		return m.handleOtherKey(msg)
+	case ModeOtherTree:
+		return m.handleOtherTreeKey(msg)
+	case ModeOtherInput:
+		return m.handleOtherInputKey(msg)
+	case ModeOtherConfirm:
+		return m.handleOtherConfirmKey(msg)
+	case ModePalette:
+		return m.handlePaletteKey(msg)
 	case ModeBYOK:
 		return m.handleBYOKKey(msg)
 	}
@@ -141,18 +397,20 @@ func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 
 func (m Model) handleMenuKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	n := len(menuEntries)
-	switch msg.String() {
-	case "up", "k":
+	switch {
+	case key.Matches(msg, m.keys.Up):
 		if m.menuCursor > 0 {
 			m.menuCursor--
 		}
-	case "down", "j":
+	case key.Matches(msg, m.keys.Down):
 		if m.menuCursor < n-1 {
 			m.menuCursor++
 		}
-	case "enter":
+	case key.Matches(msg, m.keys.Select):
 		entry := menuEntries[m.menuCursor]
 		return m.enterCategory(entry.cat)
+	case key.Matches(msg, m.keys.Search):
+		return m.openPalette()
 	}
 	return m, nil
 }
@@ -160,6 +418,7 @@ func (m Model) handleMenuKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 func (m Model) enterCategory(cat Category) (tea.Model, tea.Cmd) {
 	m.currentCat = cat
 	m.catCursor = 0
+	m.catOffset = 0
 	m.err = ""
 	m.flash = ""
 
@@ -173,8 +432,34 @@ func (m Model) enterCategory(cat Category) (tea.Model, tea.Cmd) {
 		m.mode = ModeCommandEdit
 		m.cmdFocusCol = 0
 		m.cmdCursor = 0
-		m.allowCmds = append([]string{}, m.settings.CommandAllowlist...)
-		m.denyCmds = append([]string{}, m.settings.CommandDenylist...)
+		// Unset lists load as nil and stay unset on save.
+		m.allowCmds = m.settings.GetList("commandAllowlist")
+		m.denyCmds = m.settings.GetList("commandDenylist")
+		return m, nil
+
+	case CatHooks:
+		m.mode = ModeHooks
+		m.hookCursor = 0
+		return m, loadHooks()
+
+	case CatSlash:
+		m.mode = ModeSlash
+		m.slashCursor = 0
+		return m, loadSlash("")
+
+	case CatMCP:
+		m.mode = ModeMCP
+		m.mcpCursor = 0
+		return m, loadMCP()
+
+	case CatDroids:
+		m.mode = ModeDroids
+		m.droidCursor = 0
+		return m, m.loadDroids("")
+
+	case CatOther:
+		m.mode = ModeOther
+		m.otherList = buildOtherList(m.rawCfg, listHeight(m.height))
 		return m, nil
 
 	default:
@@ -183,67 +468,266 @@ func (m Model) enterCategory(cat Category) (tea.Model, tea.Cmd) {
 	}
 }
 
+// ─────────────────────────────────────────────────────────────────────────────
+// Command palette
+// ─────────────────────────────────────────────────────────────────────────────
+
+// openPalette searches from the current screen; esc comes back to it.
+func (m Model) openPalette() (tea.Model, tea.Cmd) {
+	m.paletteFrom = m.mode
+	m.paletteEntries = m.buildPaletteEntries()
+	m.paletteInput.Reset()
+	m.paletteInput.Focus()
+	m.mode = ModePalette
+	m.refilterPalette()
+	return m, nil
+}
+
+func (m *Model) refilterPalette() {
+	m.paletteHits = filterPalette(m.paletteEntries, m.paletteInput.Value())
+	m.paletteCursor = 0
+	m.paletteOffset = 0
+}
+
+func (m Model) handlePaletteKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	height := listHeight(m.height) - 2 // the search field above the results
+	switch {
+	case key.Matches(msg, m.keys.Back):
+		m.paletteInput.Blur()
+		m.mode = m.paletteFrom
+		return m, nil
+	case key.Matches(msg, m.keys.PrevResult):
+		if m.paletteCursor > 0 {
+			m.paletteCursor--
+		}
+		m.paletteOffset = scrollWindow(m.paletteCursor, m.paletteOffset, height)
+		return m, nil
+	case key.Matches(msg, m.keys.NextResult):
+		if m.paletteCursor < len(m.paletteHits)-1 {
+			m.paletteCursor++
+		}
+		m.paletteOffset = scrollWindow(m.paletteCursor, m.paletteOffset, height)
+		return m, nil
+	case key.Matches(msg, m.keys.Select):
+		if len(m.paletteHits) == 0 {
+			return m, nil
+		}
+		m.paletteInput.Blur()
+		return m.paletteOpen(m.paletteHits[m.paletteCursor].entry.target)
+	}
+	var cmd tea.Cmd
+	before := m.paletteInput.Value()
+	m.paletteInput, cmd = m.paletteInput.Update(msg)
+	if m.paletteInput.Value() != before {
+		m.refilterPalette()
+	}
+	return m, cmd
+}
+
+// paletteOpen jumps to the screen of a palette target. Settings open
+// straight in their editor; esc from there lands on their category.
+func (m Model) paletteOpen(target string) (tea.Model, tea.Cmd) {
+	kind, value, _ := strings.Cut(target, ":")
+	switch kind {
+	case "cat":
+		m.focusMenuRow(Category(value))
+		return m.enterCategory(Category(value))
+
+	case "setting":
+		def, ok := lookupSetting(value)
+		if !ok {
+			m.mode = ModeMenu
+			return m, nil
+		}
+		cat := Category(def.Category)
+		m.focusMenuRow(cat)
+		if cat == CatCommands {
+			next, cmd := m.enterCategory(cat)
+			m = next.(Model)
+			if def.Key == "commandDenylist" {
+				m.cmdFocusCol = 1
+			}
+			return m, cmd
+		}
+		next, _ := m.enterCategory(cat)
+		m = next.(Model)
+		for i, d := range settingsIn(cat) {
+			if d.Key == def.Key {
+				m.catCursor = i
+			}
+		}
+		return m.enterSettingEdit(def)
+
+	case "model", "delete":
+		// The provider groups may be stale or not loaded yet; the jump
+		// finishes when they arrive.
+		m.focusMenuRow(CatBYOK)
+		next, cmd := m.enterCategory(CatBYOK)
+		m = next.(Model)
+		m.paletteJump = target
+		return m, cmd
+
+	case "action":
+		switch value {
+		case "add-provider":
+			m.focusMenuRow(CatBYOK)
+			return m.enterCategory(CatBYOK)
+		case "test-command":
+			m.focusMenuRow(CatCommands)
+			next, cmd := m.enterCategory(CatCommands)
+			m = next.(Model)
+			m.testInput.Focus()
+			m.mode = ModeCommandTest
+			return m, cmd
+		case "new-droid":
+			m.focusMenuRow(CatDroids)
+			next, cmd := m.enterCategory(CatDroids)
+			next, wizard := next.(Model).startDroidWizard(droids.Droid{Model: droids.Inherit})
+			return next, tea.Batch(cmd, wizard)
+		case "new-slash":
+			m.focusMenuRow(CatSlash)
+			next, cmd := m.enterCategory(CatSlash)
+			next, _ = next.(Model).pickSlashTemplate()
+			return next, cmd
+		case "add-mcp":
+			m.focusMenuRow(CatMCP)
+			next, cmd := m.enterCategory(CatMCP)
+			m = next.(Model)
+			m.mcpDraft = mcp.Server{Type: mcp.Stdio}
+			m.mcpEditIdx = -1
+			next, _ = m.openMCPForm()
+			return next, cmd
+		}
+	}
+	m.mode = m.paletteFrom
+	return m, nil
+}
+
+// focusMenuRow puts the menu cursor on cat, so esc back to the menu shows
+// where the palette went.
+func (m *Model) focusMenuRow(cat Category) {
+	for i, e := range menuEntries {
+		if e.cat == cat {
+			m.menuCursor = i
+		}
+	}
+}
+
+// paletteFinishJump opens the model of a pending "model:" or "delete:"
+// target once the provider groups have loaded. A model that is gone leaves
+// the provider list open.
+func (m Model) paletteFinishJump() (tea.Model, tea.Cmd) {
+	kind, id, _ := strings.Cut(m.paletteJump, ":")
+	m.paletteJump = ""
+	if m.mode != ModeBYOK || m.byokStep != WizProvider {
+		return m, nil // the user moved on while the groups loaded
+	}
+	for _, g := range m.providerGroups {
+		for _, model := range g.Models {
+			if model.ID != id {
+				continue
+			}
+			m.providerList.focusValue("group:" + g.Prefix)
+			next, _ := m.wizHandleProviderSelect("group:" + g.Prefix)
+			next, _ = next.(Model).wizHandleGroupAction("model:" + id)
+			m = next.(Model)
+			if kind == "delete" {
+				m.detailList.focusValue("delete")
+				return m.wizEnterModelField("delete")
+			}
+			return m, nil
+		}
+	}
+	m.err = "model " + id + " is no longer in customModels"
+	return m, nil
+}
+
 // ─────────────────────────────────────────────────────────────────────────────
 // Category list
 // ─────────────────────────────────────────────────────────────────────────────
 
 func (m Model) handleCategoryKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	defs := categorySettings[m.currentCat]
+	defs := settingsIn(m.currentCat)
 
-	switch msg.String() {
-	case "up", "k":
+	switch {
+	case key.Matches(msg, m.keys.Up):
 		if m.catCursor > 0 {
 			m.catCursor--
 		}
-	case "down", "j":
+	case key.Matches(msg, m.keys.Down):
 		if m.catCursor < len(defs)-1 {
 			m.catCursor++
 		}
-	case "enter":
+	case key.Matches(msg, m.keys.Select):
 		if len(defs) == 0 {
 			break
 		}
 		def := defs[m.catCursor]
 		return m.enterSettingEdit(def)
-	case "esc":
+	case key.Matches(msg, m.keys.Reset):
+		if len(defs) == 0 {
+			break
+		}
+		def := defs[m.catCursor]
+		m.settings.Unset(def.Key)
+		return m, saveSettings(m.settings, m.rawCfg)
+	case key.Matches(msg, m.keys.Search):
+		return m.openPalette()
+	case key.Matches(msg, m.keys.Back):
 		m.mode = ModeMenu
 	}
 	return m, nil
 }
 
-func (m Model) enterSettingEdit(def SettingDef) (tea.Model, tea.Cmd) {
-	switch def.Kind {
-	case KindEnum:
+func (m Model) enterSettingEdit(def config.SettingSpec) (tea.Model, tea.Cmd) {
+	switch def.Type {
+	case config.TypeEnum:
 		current := m.settings.GetField(def.Key)
 		items := make([]listItem, len(def.Options))
 		cursor := 0
 		for i, o := range def.Options {
-			items[i] = listItem{label: o.Label, value: o.Value, sub: o.Desc}
+			items[i] = listItem{label: o.Title(), value: o.Value, sub: o.Desc}
 			if o.Value == current {
 				cursor = i
 			}
 		}
+		if def.Key == "model" {
+			for _, cm := range m.customModels {
+				if cm.ID == current {
+					cursor = len(items)
+				}
+				items = append(items, customModelItem(cm))
+			}
+		}
+		if def.Custom != nil {
+			items = append(items, listItem{label: def.Custom.Label, value: customOption, sub: def.Custom.Desc})
+		}
+		items = append(items, listItem{label: unsetLabel, value: unsetOption, sub: "default: " + def.DefaultText()})
 		m.optionList = newList(items, false, listHeight(m.height))
 		m.optionList.cursor = cursor
 		m.mode = ModeOptionPick
 
-	case KindBool:
+	case config.TypeBool:
 		m.optionList = newList([]listItem{
-			{label: "Enable", value: "true"},
-			{label: "Disable", value: "false"},
+			{label: "Inherit", value: config.Inherit.String(), sub: "use Droid's default (" + def.DefaultText() + ") · removes the key"},
+			{label: "On", value: config.On.String()},
+			{label: "Off", value: config.Off.String()},
 		}, false, 4)
-		b := m.settings.GetBool(def.Key)
-		if b != nil && !*b {
-			m.optionList.cursor = 1
-		}
+		m.optionList.cursor = int(m.settings.GetTri(def.Key))
 		m.mode = ModeBoolPick
 
-	case KindText:
+	case config.TypeString, config.TypePath, config.TypeInteger, config.TypeList:
 		m.textInput.Reset()
-		m.textInput.Placeholder = def.Default
+		m.textInput.Placeholder = def.DefaultText()
+		if def.Type == config.TypeList {
+			m.textInput.Placeholder = "comma-separated values"
+		}
 		m.textInput.SetValue(m.settings.GetField(def.Key))
 		m.textInput.Focus()
 		m.mode = ModeTextInput
+
+	case config.TypeObject:
+		m.err = def.Label + " is a JSON object · edit it in settings.json"
 	}
 	return m, nil
 }
@@ -253,33 +737,37 @@ func (m Model) enterSettingEdit(def SettingDef) (tea.Model, tea.Cmd) {
 // ─────────────────────────────────────────────────────────────────────────────
 
 func (m Model) handleOptionPickKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "up", "k":
+	switch {
+	case key.Matches(msg, m.keys.Up):
 		m.optionList.up()
-	case "down", "j":
+	case key.Matches(msg, m.keys.Down):
 		m.optionList.down()
-	case "enter":
+	case key.Matches(msg, m.keys.Select):
 		val := m.optionList.items[m.optionList.cursor].value
 
-		if val == "__custom__" {
+		if val == customOption {
 			def := m.currentSettingDef()
 			m.textInput.Reset()
-			m.textInput.Placeholder = "/path/to/sound.wav"
+			m.textInput.Placeholder = def.Custom.Placeholder
 			m.textInput.SetValue("")
 			m.textInput.Focus()
 			m.customInput = true
-			_ = def
 			m.mode = ModeTextInput
 			return m, nil
 		}
 
 		m.customInput = false
 		def := m.currentSettingDef()
-		m.settings.SetField(def.Key, val)
+		if val == unsetOption {
+			m.settings.Unset(def.Key)
+		} else if err := m.settings.SetField(def.Key, val); err != nil {
+			m.err = err.Error()
+			return m, nil
+		}
 		m.mode = ModeCategory
 		return m, saveSettings(m.settings, m.rawCfg)
 
-	case "esc":
+	case key.Matches(msg, m.keys.Back):
 		m.mode = ModeCategory
 	}
 	return m, nil
@@ -290,18 +778,17 @@ func (m Model) handleOptionPickKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 // ─────────────────────────────────────────────────────────────────────────────
 
 func (m Model) handleBoolPickKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "up", "k":
+	switch {
+	case key.Matches(msg, m.keys.Up):
 		m.optionList.up()
-	case "down", "j":
+	case key.Matches(msg, m.keys.Down):
 		m.optionList.down()
-	case "enter":
-		val := m.optionList.items[m.optionList.cursor].value == "true"
+	case key.Matches(msg, m.keys.Select):
 		def := m.currentSettingDef()
-		m.settings.SetBool(def.Key, val)
+		m.settings.SetTri(def.Key, config.TriState(m.optionList.cursor))
 		m.mode = ModeCategory
 		return m, saveSettings(m.settings, m.rawCfg)
-	case "esc":
+	case key.Matches(msg, m.keys.Back):
 		m.mode = ModeCategory
 	}
 	return m, nil
@@ -312,19 +799,20 @@ func (m Model) handleBoolPickKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 // ─────────────────────────────────────────────────────────────────────────────
 
 func (m Model) handleTextInputKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "enter":
+	switch {
+	case key.Matches(msg, m.keys.Select):
 		val := strings.TrimSpace(m.textInput.Value())
 		def := m.currentSettingDef()
-		if val == "" {
-			val = def.Default
+		// An empty value resets to the default by removing the key.
+		if err := m.settings.SetField(def.Key, val); err != nil {
+			m.err = err.Error()
+			return m, nil
 		}
-		m.settings.SetField(def.Key, val)
 		m.textInput.Blur()
 		m.customInput = false
 		m.mode = ModeCategory
 		return m, saveSettings(m.settings, m.rawCfg)
-	case "esc":
+	case key.Matches(msg, m.keys.Back):
 		m.textInput.Blur()
 		m.customInput = false
 		m.mode = ModeCategory
@@ -341,75 +829,1763 @@ func (m Model) handleTextInputKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 // ─────────────────────────────────────────────────────────────────────────────
 
 func (m Model) handleCommandEditKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	list := m.activeCommandList()
-	n := len(list)
+	m, cmd := m.commandEditKey(msg)
+	m.cmdOffset[m.cmdFocusCol], _ = m.cmdWindow(m.cmdFocusCol)
+	return m, cmd
+}
+
+func (m Model) commandEditKey(msg tea.KeyMsg) (Model, tea.Cmd) {
+	vis := m.visibleCmds(m.cmdFocusCol)
+	n := len(vis)
 
-	switch msg.String() {
-	case "tab":
+	switch {
+	case key.Matches(msg, m.keys.Column):
 		m.cmdFocusCol = 1 - m.cmdFocusCol
 		m.cmdCursor = 0
-	case "up", "k":
+		m.cmdMarked = nil
+	case key.Matches(msg, m.keys.Up):
 		if m.cmdCursor > 0 {
 			m.cmdCursor--
 		}
-	case "down", "j":
+	case key.Matches(msg, m.keys.Down):
 		if m.cmdCursor < n-1 {
 			m.cmdCursor++
 		}
-	case "a":
+	case key.Matches(msg, m.keys.PageUp):
+		m.cmdCursor = max(m.cmdCursor-m.cmdListHeight(), 0)
+	case key.Matches(msg, m.keys.PageDown):
+		m.cmdCursor = max(min(m.cmdCursor+m.cmdListHeight(), n-1), 0)
+	case key.Matches(msg, m.keys.Top):
+		m.cmdCursor = 0
+	case key.Matches(msg, m.keys.Bottom):
+		m.cmdCursor = max(n-1, 0)
+	case key.Matches(msg, m.keys.Toggle):
+		if i := m.cmdIndex(); i >= 0 {
+			if m.cmdMarked == nil {
+				m.cmdMarked = map[int]bool{}
+			}
+			m.cmdMarked[i] = !m.cmdMarked[i]
+			if m.cmdCursor < n-1 {
+				m.cmdCursor++
+			}
+		}
+	case key.Matches(msg, m.keys.Add):
+		m.cmdEditIdx = -1
 		m.cmdInput.Reset()
 		m.cmdInput.Focus()
 		m.mode = ModeCommandAdd
 		return m, nil
-	case "d", "backspace":
-		if n > 0 && m.cmdCursor < n {
-			if m.cmdFocusCol == 0 {
-				m.allowCmds = append(m.allowCmds[:m.cmdCursor], m.allowCmds[m.cmdCursor+1:]...)
-			} else {
-				m.denyCmds = append(m.denyCmds[:m.cmdCursor], m.denyCmds[m.cmdCursor+1:]...)
+	case key.Matches(msg, m.keys.Edit, m.keys.Select):
+		if i := m.cmdIndex(); i >= 0 {
+			m.cmdEditIdx = i
+			m.cmdInput.SetValue(m.activeCommandList()[i])
+			m.cmdInput.CursorEnd()
+			m.cmdInput.Focus()
+			m.mode = ModeCommandAdd
+		}
+		return m, nil
+	case key.Matches(msg, m.keys.Paste):
+		m.cmdPaste.Reset()
+		m.cmdPaste.Focus()
+		m.mode = ModeCommandPaste
+		return m, nil
+	case key.Matches(msg, m.keys.Filter):
+		m.cmdFilter.Focus()
+		m.mode = ModeCommandFilter
+		return m, nil
+	case key.Matches(msg, m.keys.Test):
+		m.testInput.Focus()
+		m.mode = ModeCommandTest
+		return m, nil
+	case key.Matches(msg, m.keys.Presets):
+		m.presetPlan = nil
+		m.presetList = customList{}
+		m.mode = ModeCommandPreset
+		return m, loadPresets()
+	case key.Matches(msg, m.keys.Suggest):
+		m.historyFiles = nil
+		m.suggestList = customList{}
+		m.mode = ModeCommandSuggest
+		return m, loadSuggestions(m.allowCmds, m.denyCmds)
+	case key.Matches(msg, m.keys.Delete):
+		targets := m.cmdTargets()
+		if len(targets) == 0 {
+			break
+		}
+		list := m.cmdList(m.cmdFocusCol)
+		*list = removeIndices(*list, targets)
+		m.cmdMarked = nil
+		m.clampCmdCursor()
+		if len(targets) > 1 {
+			m.flash = fmt.Sprintf("  ✓ Deleted %d entries", len(targets))
+			return m, clearFlashAfter()
+		}
+	case key.Matches(msg, m.keys.Transfer):
+		targets := m.cmdTargets()
+		if len(targets) == 0 {
+			break
+		}
+		from, to := m.cmdList(m.cmdFocusCol), m.cmdList(1-m.cmdFocusCol)
+		for _, i := range targets {
+			if !slices.Contains(*to, (*from)[i]) {
+				*to = append(*to, (*from)[i])
+			}
+		}
+		*from = removeIndices(*from, targets)
+		m.cmdMarked = nil
+		m.clampCmdCursor()
+		dest := "denylist"
+		if m.cmdFocusCol == 1 {
+			dest = "allowlist"
+		}
+		m.flash = fmt.Sprintf("  ✓ Moved %d entries to the %s", len(targets), dest)
+		return m, clearFlashAfter()
+	case key.Matches(msg, m.keys.Sort):
+		slices.SortFunc(*m.cmdList(m.cmdFocusCol), func(a, b string) int {
+			return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+		})
+		m.cmdMarked = nil
+	case key.Matches(msg, m.keys.UnsetColumn):
+		// Unset the column: its key is removed from settings.json on save.
+		*m.cmdList(m.cmdFocusCol) = nil
+		m.cmdMarked = nil
+		m.cmdCursor = 0
+	case key.Matches(msg, m.keys.Dedupe):
+		removed := 0
+		for col := 0; col < 2; col++ {
+			list := m.cmdList(col)
+			before := len(*list)
+			*list = dedupeCmds(*list)
+			removed += before - len(*list)
+		}
+		m.cmdMarked = nil
+		m.clampCmdCursor()
+		m.flash = fmt.Sprintf("  ✓ Removed %d duplicate entries", removed)
+		return m, clearFlashAfter()
+	case key.Matches(msg, m.keys.Back):
+		if m.cmdFilter.Value() != "" || len(m.cmdMarked) > 0 {
+			m.cmdFilter.Reset()
+			m.cmdMarked = nil
+			m.clampCmdCursor()
+			break
+		}
+		m.settings.SetList("commandAllowlist", m.allowCmds)
+		m.settings.SetList("commandDenylist", m.denyCmds)
+		m.mode = ModeMenu
+		return m, saveSettings(m.settings, m.rawCfg)
+	}
+	return m, nil
+}
+
+// handleCommandAddKey adds a new entry, or replaces the one being edited when
+// cmdEditIdx >= 0. A paste containing several lines adds one entry per line.
+func (m Model) handleCommandAddKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.Paste && m.cmdEditIdx < 0 && strings.ContainsAny(string(msg.Runes), "\r\n") {
+		added := m.addCmdLines(string(msg.Runes))
+		m.cmdInput.Blur()
+		m.mode = ModeCommandEdit
+		m.flash = fmt.Sprintf("  ✓ Added %d entries", added)
+		return m, clearFlashAfter()
+	}
+
+	switch {
+	case key.Matches(msg, m.keys.Select):
+		val := strings.TrimSpace(m.cmdInput.Value())
+		list := m.cmdList(m.cmdFocusCol)
+		switch {
+		case val == "":
+		case m.cmdEditIdx >= 0 && m.cmdEditIdx < len(*list):
+			(*list)[m.cmdEditIdx] = val
+		default:
+			*list = append(*list, val)
+		}
+		m.cmdInput.Blur()
+		m.mode = ModeCommandEdit
+	case key.Matches(msg, m.keys.Back):
+		m.cmdInput.Blur()
+		m.mode = ModeCommandEdit
+	default:
+		var cmd tea.Cmd
+		m.cmdInput, cmd = m.cmdInput.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+// handleCommandPasteKey collects many newline-separated entries at once.
+func (m Model) handleCommandPasteKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Save):
+		added := m.addCmdLines(m.cmdPaste.Value())
+		m.cmdPaste.Blur()
+		m.mode = ModeCommandEdit
+		m.flash = fmt.Sprintf("  ✓ Added %d entries", added)
+		return m, clearFlashAfter()
+	case key.Matches(msg, m.keys.Back):
+		m.cmdPaste.Blur()
+		m.mode = ModeCommandEdit
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.cmdPaste, cmd = m.cmdPaste.Update(msg)
+	return m, cmd
+}
+
+// handleCommandFilterKey narrows both columns to entries containing the typed
+// text. enter keeps the filter while navigating; esc clears it.
+func (m Model) handleCommandFilterKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Select):
+		m.cmdFilter.Blur()
+		m.mode = ModeCommandEdit
+		return m, nil
+	case key.Matches(msg, m.keys.Back):
+		m.cmdFilter.Reset()
+		m.cmdFilter.Blur()
+		m.mode = ModeCommandEdit
+		m.clampCmdCursor()
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.cmdFilter, cmd = m.cmdFilter.Update(msg)
+	m.cmdMarked = nil
+	m.cmdCursor = 0
+	return m, cmd
+}
+
+// handleCommandTestKey edits the tester's command line; the verdict is
+// recomputed on every render, so list edits made before opening the tester
+// are reflected immediately. The typed command is kept between openings.
+func (m Model) handleCommandTestKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Back, m.keys.Select):
+		m.testInput.Blur()
+		m.mode = ModeCommandEdit
+	case key.Matches(msg, m.keys.Clear):
+		m.testInput.Reset()
+	default:
+		var cmd tea.Cmd
+		m.testInput, cmd = m.testInput.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+// handleCommandPresetKey drives the preset picker and, once a preset is
+// chosen, its merge preview. Applying only touches the in-memory lists; esc in
+// the editor saves as usual.
+func (m Model) handleCommandPresetKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.presetPlan != nil {
+		switch {
+		case key.Matches(msg, m.keys.Select, m.keys.Yes):
+			plan := *m.presetPlan
+			name := m.presets[m.presetList.cursor].Name
+			m.allowCmds, m.denyCmds = plan.Apply(m.allowCmds, m.denyCmds)
+			m.presetPlan = nil
+			m.mode = ModeCommandEdit
+			m.cmdCursor = 0
+			m.flash = fmt.Sprintf("  ✓ Added %d entries from %s", len(plan.Added)+len(plan.Overlaps), name)
+			return m, clearFlashAfter()
+		case key.Matches(msg, m.keys.Up):
+			if m.presetScroll > 0 {
+				m.presetScroll--
+			}
+		case key.Matches(msg, m.keys.Down):
+			p := m.presetPlan
+			if m.presetScroll < len(p.Added)+len(p.Overlaps)+len(p.Conflicts)+len(p.Present) {
+				m.presetScroll++
+			}
+		case key.Matches(msg, m.keys.Back, m.keys.No):
+			m.presetPlan = nil
+		}
+		return m, nil
+	}
+
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		m.presetList.up()
+	case key.Matches(msg, m.keys.Down):
+		m.presetList.down()
+	case key.Matches(msg, m.keys.Select):
+		if len(m.presets) == 0 {
+			break
+		}
+		plan := policy.PlanMerge(m.presets[m.presetList.cursor], m.allowCmds, m.denyCmds)
+		m.presetPlan = &plan
+		m.presetScroll = 0
+	case key.Matches(msg, m.keys.Back):
+		m.mode = ModeCommandEdit
+	}
+	return m, nil
+}
+
+// handleCommandSuggestKey lets the user pick history suggestions to append
+// to the allowlist.
+func (m Model) handleCommandSuggestKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		m.suggestList.up()
+	case key.Matches(msg, m.keys.Down):
+		m.suggestList.down()
+	case key.Matches(msg, m.keys.Toggle):
+		m.suggestList.toggleCurrent()
+	case key.Matches(msg, m.keys.SelectAll):
+		for i := range m.suggestList.items {
+			m.suggestList.selected[i] = true
+		}
+	case key.Matches(msg, m.keys.Select):
+		picked := m.suggestList.selectedValues()
+		if len(picked) == 0 && len(m.suggestList.items) > 0 {
+			picked = []string{m.suggestList.items[m.suggestList.cursor].value}
+		}
+		m.allowCmds = append(m.allowCmds, picked...)
+		m.cmdFocusCol = 0
+		m.cmdCursor = 0
+		m.mode = ModeCommandEdit
+		if len(picked) > 0 {
+			m.flash = fmt.Sprintf("  ✓ Added %d entries to the allowlist", len(picked))
+			return m, clearFlashAfter()
+		}
+	case key.Matches(msg, m.keys.Back):
+		m.mode = ModeCommandEdit
+	}
+	return m, nil
+}
+
+func (m Model) activeCommandList() []string {
+	if m.cmdFocusCol == 0 {
+		return m.allowCmds
+	}
+	return m.denyCmds
+}
+
+func (m *Model) cmdList(col int) *[]string {
+	if col == 0 {
+		return &m.allowCmds
+	}
+	return &m.denyCmds
+}
+
+// visibleCmds returns the indices of the entries in a column that match the
+// filter, in list order.
+func (m Model) visibleCmds(col int) []int {
+	list := m.allowCmds
+	if col == 1 {
+		list = m.denyCmds
+	}
+	q := strings.ToLower(strings.TrimSpace(m.cmdFilter.Value()))
+	out := make([]int, 0, len(list))
+	for i, c := range list {
+		if q == "" || strings.Contains(strings.ToLower(c), q) {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+// cmdIndex returns the list index under the cursor, or -1.
+func (m Model) cmdIndex() int {
+	vis := m.visibleCmds(m.cmdFocusCol)
+	if m.cmdCursor >= 0 && m.cmdCursor < len(vis) {
+		return vis[m.cmdCursor]
+	}
+	return -1
+}
+
+// cmdTargets returns the marked entries of the focused column, or the entry
+// under the cursor when nothing is marked.
+func (m Model) cmdTargets() []int {
+	var out []int
+	for i, on := range m.cmdMarked {
+		if on {
+			out = append(out, i)
+		}
+	}
+	if len(out) == 0 {
+		if i := m.cmdIndex(); i >= 0 {
+			out = append(out, i)
+		}
+	}
+	slices.Sort(out)
+	return out
+}
+
+func (m *Model) clampCmdCursor() {
+	n := len(m.visibleCmds(m.cmdFocusCol))
+	if m.cmdCursor >= n {
+		m.cmdCursor = n - 1
+	}
+	if m.cmdCursor < 0 {
+		m.cmdCursor = 0
+	}
+}
+
+// cmdWindow returns the range of visible rows a column shows, starting from
+// its saved offset and scrolled just enough to keep the cursor on screen.
+func (m Model) cmdWindow(col int) (start, end int) {
+	n := len(m.visibleCmds(col))
+	h := m.cmdListHeight()
+	start = m.cmdOffset[col]
+	if col == m.cmdFocusCol {
+		if m.cmdCursor < start {
+			start = m.cmdCursor
+		}
+		if m.cmdCursor >= start+h {
+			start = m.cmdCursor - h + 1
+		}
+	}
+	start = max(min(start, n-h), 0)
+	return start, min(start+h, n)
+}
+
+// cmdListHeight is the number of entry rows each column shows.
+func (m Model) cmdListHeight() int {
+	// Header, column headers, lint detail and footer take about 14 lines,
+	// the filter line two more.
+	h := m.height - 14
+	if m.mode == ModeCommandFilter || m.cmdFilter.Value() != "" {
+		h -= 2
+	}
+	return max(h, 4)
+}
+
+// addCmdLines appends each non-empty line of text to the focused column,
+// skipping "#" comments and entries the column already has.
+func (m *Model) addCmdLines(text string) int {
+	list := m.cmdList(m.cmdFocusCol)
+	added := 0
+	for _, line := range strings.FieldsFunc(text, func(r rune) bool { return r == '\n' || r == '\r' }) {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || slices.Contains(*list, line) {
+			continue
+		}
+		*list = append(*list, line)
+		added++
+	}
+	return added
+}
+
+// removeIndices returns list without the entries at the sorted indices.
+func removeIndices(list []string, idx []int) []string {
+	out := make([]string, 0, len(list))
+	j := 0
+	for i, v := range list {
+		if j < len(idx) && idx[j] == i {
+			j++
+			continue
+		}
+		out = append(out, v)
+	}
+	return out
+}
+
+// dedupeCmds drops repeats of an entry, comparing with whitespace collapsed.
+func dedupeCmds(list []string) []string {
+	if list == nil {
+		return nil
+	}
+	seen := map[string]bool{}
+	out := make([]string, 0, len(list))
+	for _, c := range list {
+		key := strings.Join(strings.Fields(c), " ")
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, c)
+	}
+	return out
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Hooks
+// ─────────────────────────────────────────────────────────────────────────────
+
+func (m Model) handleHooksKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.hookLoadErr != "" {
+		// Never rewrite a section we could not parse.
+		if key.Matches(msg, m.keys.Back) {
+			m.mode = ModeMenu
+		}
+		return m, nil
+	}
+	n := len(m.hookList)
+
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		if m.hookCursor > 0 {
+			m.hookCursor--
+		}
+	case key.Matches(msg, m.keys.Down):
+		if m.hookCursor < n-1 {
+			m.hookCursor++
+		}
+	case key.Matches(msg, m.keys.Add):
+		m.hookDraft = hooks.Hook{Enabled: true}
+		m.hookEditIdx = -1
+		return m.pickHookEvent(ModeHooks)
+	case key.Matches(msg, m.keys.Template):
+		m.hookPicking = "template"
+		m.hookFrom = ModeHooks
+		m.hookPick = buildTemplateList(listHeight(m.height))
+		m.mode = ModeHookPick
+		return m, nil
+	case key.Matches(msg, m.keys.Back):
+		m.mode = ModeMenu
+		return m, nil
+	}
+	if n == 0 {
+		return m, nil
+	}
+
+	switch {
+	case key.Matches(msg, m.keys.Select, m.keys.Edit):
+		m.hookDraft = m.hookList[m.hookCursor]
+		m.hookEditIdx = m.hookCursor
+		return m.openHookForm()
+	case key.Matches(msg, m.keys.Toggle):
+		list := slices.Clone(m.hookList)
+		list[m.hookCursor].Enabled = !list[m.hookCursor].Enabled
+		return m.saveHooks(list, m.hookCursor)
+	case key.Matches(msg, m.keys.MoveUp):
+		list := slices.Clone(m.hookList)
+		return m.saveHooks(list, hooks.Move(list, m.hookCursor, -1))
+	case key.Matches(msg, m.keys.MoveDown):
+		list := slices.Clone(m.hookList)
+		return m.saveHooks(list, hooks.Move(list, m.hookCursor, 1))
+	case key.Matches(msg, m.keys.Delete):
+		m.mode = ModeHookConfirm
+	case key.Matches(msg, m.keys.Run):
+		m.hookDraft = m.hookList[m.hookCursor]
+		return m.openHookRun(ModeHooks)
+	}
+	return m, nil
+}
+
+// saveHooks replaces the list, keeps the cursor on index focus and writes
+// settings.json and the disabled hooks file.
+func (m Model) saveHooks(list []hooks.Hook, focus int) (tea.Model, tea.Cmd) {
+	m.hookList = list
+	m.hookCursor = min(max(focus, 0), max(len(list)-1, 0))
+	m.refreshHookMissing()
+	m.mode = ModeHooks
+	return m, writeHooks(list)
+}
+
+// refreshHookMissing checks the executables of every hook command once, so
+// rendering does not search PATH.
+func (m *Model) refreshHookMissing() {
+	m.hookMissing = map[string][]string{}
+	for _, h := range m.hookList {
+		if _, ok := m.hookMissing[h.Command]; !ok {
+			m.hookMissing[h.Command] = hooks.Missing(h.Command)
+		}
+	}
+}
+
+func (m Model) pickHookEvent(from AppMode) (tea.Model, tea.Cmd) {
+	items := make([]listItem, len(hooks.Events))
+	for i, e := range hooks.Events {
+		items[i] = listItem{label: e.Name, value: e.Name, sub: e.Desc}
+	}
+	m.hookPick = newList(items, false, listHeight(m.height))
+	m.hookPick.focusValue(m.hookDraft.Event)
+	m.hookPicking = "event"
+	m.hookFrom = from
+	m.mode = ModeHookPick
+	return m, nil
+}
+
+func buildTemplateList(height int) customList {
+	templates := hooks.Templates()
+	items := make([]listItem, len(templates))
+	for i, t := range templates {
+		items[i] = listItem{label: t.Name, value: strconv.Itoa(i), sub: t.Description}
+	}
+	return newList(items, false, height)
+}
+
+func (m Model) handleHookPickKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		m.hookPick.up()
+	case key.Matches(msg, m.keys.Down):
+		m.hookPick.down()
+	case key.Matches(msg, m.keys.Select):
+		value := m.hookPick.items[m.hookPick.cursor].value
+		if m.hookPicking == "template" {
+			i, _ := strconv.Atoi(value)
+			m.hookDraft = hooks.Templates()[i].Hook
+			m.hookEditIdx = -1
+		} else {
+			m.hookDraft.Event = value
+			if e, _ := hooks.LookupEvent(value); e.MatcherHint == "" {
+				m.hookDraft.Matcher = ""
+			}
+		}
+		return m.openHookForm()
+	case key.Matches(msg, m.keys.Back):
+		m.mode = m.hookFrom
+	}
+	return m, nil
+}
+
+func (m Model) openHookForm() (tea.Model, tea.Cmd) {
+	cursor := m.hookForm.cursor
+	m.hookForm = buildHookForm(m.hookDraft)
+	if m.mode == ModeHookInput || m.mode == ModeHookRun || m.mode == ModeHookPick && m.hookFrom == ModeHookForm {
+		m.hookForm.cursor = cursor
+	}
+	m.mode = ModeHookForm
+	return m, nil
+}
+
+func buildHookForm(h hooks.Hook) customList {
+	matcher := h.Matcher
+	if e, ok := hooks.LookupEvent(h.Event); ok && e.MatcherHint == "" {
+		matcher = "— (" + h.Event + " has no matcher)"
+	} else if matcher == "" {
+		matcher = "* (everything)"
+	}
+	timeout := fmt.Sprintf("%d s (Droid's default)", int(hooks.DefaultTimeout.Seconds()))
+	if h.Timeout > 0 {
+		timeout = fmt.Sprintf("%d s", h.Timeout)
+	}
+	enabled := "Yes"
+	if !h.Enabled {
+		enabled = "No · kept in wrench until enabled"
+	}
+	items := []listItem{
+		{label: "Event", value: "event", sub: h.Event},
+		{label: "Matcher", value: "matcher", sub: matcher},
+		{label: "Command", value: "command", sub: h.Command},
+		{label: "Timeout", value: "timeout", sub: timeout},
+		{label: "Enabled", value: "enabled", sub: enabled},
+		{label: "Run once with sample input", value: "run"},
+		{label: "✓ Save hook", value: "save"},
+		{label: "← Cancel", value: "cancel"},
+	}
+	return newList(items, false, len(items))
+}
+
+func (m Model) handleHookFormKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		m.hookForm.up()
+	case key.Matches(msg, m.keys.Down):
+		m.hookForm.down()
+	case key.Matches(msg, m.keys.Back):
+		m.mode = ModeHooks
+	case key.Matches(msg, m.keys.Select):
+		switch field := m.hookForm.items[m.hookForm.cursor].value; field {
+		case "event":
+			return m.pickHookEvent(ModeHookForm)
+		case "matcher":
+			if e, ok := hooks.LookupEvent(m.hookDraft.Event); ok && e.MatcherHint == "" {
+				m.err = m.hookDraft.Event + " hooks run without a matcher"
+				return m, nil
+			}
+			e, _ := hooks.LookupEvent(m.hookDraft.Event)
+			return m.focusHookInput(field, m.hookDraft.Matcher, orDef(e.MatcherHint, "empty matches everything"))
+		case "command":
+			return m.focusHookInput(field, m.hookDraft.Command, "shell command · reads the event JSON on stdin")
+		case "timeout":
+			text := ""
+			if m.hookDraft.Timeout > 0 {
+				text = strconv.Itoa(m.hookDraft.Timeout)
+			}
+			return m.focusHookInput(field, text, "seconds · empty uses Droid's default")
+		case "enabled":
+			m.hookDraft.Enabled = !m.hookDraft.Enabled
+			return m.openHookForm()
+		case "run":
+			if err := m.hookDraft.Validate(); err != nil {
+				m.err = err.Error()
+				return m, nil
+			}
+			return m.openHookRun(ModeHookForm)
+		case "save":
+			if m.hookDraft.Event == "" {
+				m.err = "choose an event"
+				return m, nil
+			}
+			if err := m.hookDraft.Validate(); err != nil {
+				m.err = err.Error()
+				return m, nil
+			}
+			list := slices.Clone(m.hookList)
+			if m.hookEditIdx >= 0 && list[m.hookEditIdx].Event == m.hookDraft.Event {
+				list[m.hookEditIdx] = m.hookDraft
+				return m.saveHooks(list, m.hookEditIdx)
+			}
+			if m.hookEditIdx >= 0 {
+				// A new event moves the hook to the end of that event's group.
+				list = slices.Delete(list, m.hookEditIdx, m.hookEditIdx+1)
+			}
+			list, at := hooks.Add(list, m.hookDraft)
+			return m.saveHooks(list, at)
+		case "cancel":
+			m.mode = ModeHooks
+		}
+	}
+	return m, nil
+}
+
+func (m Model) focusHookInput(field, value, placeholder string) (tea.Model, tea.Cmd) {
+	m.hookField = field
+	m.textInput.Reset()
+	m.textInput.Placeholder = placeholder
+	m.textInput.SetValue(value)
+	m.textInput.Focus()
+	m.mode = ModeHookInput
+	return m, nil
+}
+
+func (m Model) handleHookInputKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Select):
+		text := strings.TrimSpace(m.textInput.Value())
+		draft := m.hookDraft
+		switch m.hookField {
+		case "matcher":
+			if _, err := regexp.Compile(text); err != nil {
+				m.err = fmt.Sprintf("%q is not a valid pattern", text)
+				return m, nil
+			}
+			draft.Matcher = text
+		case "command":
+			if text == "" {
+				m.err = "enter a command"
+				return m, nil
+			}
+			draft.Command = text
+		case "timeout":
+			n := 0
+			if text != "" {
+				v, err := strconv.Atoi(text)
+				if err != nil || v < 0 {
+					m.err = fmt.Sprintf("%q is not a number of seconds", text)
+					return m, nil
+				}
+				n = v
+			}
+			draft.Timeout = n
+		}
+		m.hookDraft = draft
+		m.textInput.Blur()
+		return m.openHookForm()
+	case key.Matches(msg, m.keys.Back):
+		m.textInput.Blur()
+		return m.openHookForm()
+	}
+	var cmd tea.Cmd
+	m.textInput, cmd = m.textInput.Update(msg)
+	return m, cmd
+}
+
+func (m Model) openHookRun(from AppMode) (tea.Model, tea.Cmd) {
+	dir, _ := os.Getwd()
+	m.hookInput = hooks.SampleInput(m.hookDraft, dir)
+	m.hookResult = nil
+	m.hookRunning = false
+	m.hookFrom = from
+	m.mode = ModeHookRun
+	return m, nil
+}
+
+func (m Model) handleHookRunKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Select, m.keys.Run):
+		if m.hookRunning {
+			return m, nil
+		}
+		m.hookRunning = true
+		m.hookResult = nil
+		return m, runHook(m.hookDraft, m.hookInput)
+	case key.Matches(msg, m.keys.Back):
+		if m.hookFrom == ModeHookForm {
+			return m.openHookForm()
+		}
+		m.mode = m.hookFrom
+	}
+	return m, nil
+}
+
+func (m Model) handleHookConfirmKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Yes):
+		list := slices.Delete(slices.Clone(m.hookList), m.hookCursor, m.hookCursor+1)
+		return m.saveHooks(list, m.hookCursor)
+	case key.Matches(msg, m.keys.No, m.keys.Back):
+		m.mode = ModeHooks
+	}
+	return m, nil
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Slash commands
+// ─────────────────────────────────────────────────────────────────────────────
+
+func (m Model) handleSlashKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	n := len(m.slashList)
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		if m.slashCursor > 0 {
+			m.slashCursor--
+		}
+	case key.Matches(msg, m.keys.Down):
+		if m.slashCursor < n-1 {
+			m.slashCursor++
+		}
+	case key.Matches(msg, m.keys.Add):
+		return m.pickSlashTemplate()
+	case key.Matches(msg, m.keys.Reload):
+		return m, loadSlash("")
+	case key.Matches(msg, m.keys.Back):
+		m.mode = ModeMenu
+		return m, nil
+	}
+	if n == 0 {
+		return m, nil
+	}
+
+	c := m.slashList[m.slashCursor]
+	switch {
+	case key.Matches(msg, m.keys.Select, m.keys.Edit):
+		return m, openEditor(c.Path)
+	case key.Matches(msg, m.keys.Rename):
+		m.slashRenaming = true
+		m.focusInput("new name", c.Name)
+		m.mode = ModeSlashInput
+	case key.Matches(msg, m.keys.Chmod):
+		if c.Kind != slash.Executable {
+			m.err = "/" + c.Name + " is a markdown command · only scripts need to be executable"
+			return m, nil
+		}
+		return m, chmodSlash(c)
+	case key.Matches(msg, m.keys.Delete):
+		m.mode = ModeSlashConfirm
+	}
+	return m, nil
+}
+
+// pickSlashTemplate starts a new command with its template picker.
+func (m Model) pickSlashTemplate() (tea.Model, tea.Cmd) {
+	items := make([]listItem, len(slash.Templates))
+	for i, t := range slash.Templates {
+		items[i] = listItem{label: t.Name, value: strconv.Itoa(i), sub: t.Description}
+	}
+	m.slashPick = newList(items, false, listHeight(m.height))
+	m.slashPicking = "template"
+	m.mode = ModeSlashPick
+	return m, nil
+}
+
+func (m Model) handleSlashPickKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		m.slashPick.up()
+	case key.Matches(msg, m.keys.Down):
+		m.slashPick.down()
+	case key.Matches(msg, m.keys.Select):
+		value := m.slashPick.items[m.slashPick.cursor].value
+		if m.slashPicking == "template" {
+			i, _ := strconv.Atoi(value)
+			m.slashTemplate = slash.Templates[i]
+			home, cwd := homeAndCwd()
+			m.slashPick = newList([]listItem{
+				{label: "Personal", value: "personal", sub: slash.Dir(scope.Personal, home, cwd)},
+				{label: "Project", value: "project", sub: slash.Dir(scope.Project, home, cwd)},
+			}, false, listHeight(m.height))
+			m.slashPicking = "scope"
+			return m, nil
+		}
+		m.slashScope = scope.Personal
+		if value == "project" {
+			m.slashScope = scope.Project
+		}
+		m.slashRenaming = false
+		m.focusInput("name, typed after the slash", "")
+		m.mode = ModeSlashInput
+	case key.Matches(msg, m.keys.Back):
+		m.mode = ModeSlash
+	}
+	return m, nil
+}
+
+func (m Model) handleSlashInputKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Select):
+		name := strings.TrimPrefix(strings.TrimSpace(m.textInput.Value()), "/")
+		if err := slash.ValidName(name); err != nil {
+			m.err = err.Error()
+			return m, nil
+		}
+		m.textInput.Blur()
+		if m.slashRenaming {
+			return m, renameSlash(m.slashList[m.slashCursor], name)
+		}
+		home, cwd := homeAndCwd()
+		return m, createSlash(slash.Dir(m.slashScope, home, cwd), name, m.slashTemplate)
+	case key.Matches(msg, m.keys.Back):
+		m.textInput.Blur()
+		m.mode = ModeSlash
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.textInput, cmd = m.textInput.Update(msg)
+	return m, cmd
+}
+
+func (m Model) handleSlashConfirmKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Yes):
+		return m, deleteSlash(m.slashList[m.slashCursor])
+	case key.Matches(msg, m.keys.No, m.keys.Back):
+		m.mode = ModeSlash
+	}
+	return m, nil
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// MCP servers
+// ─────────────────────────────────────────────────────────────────────────────
+
+func (m Model) handleMCPKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.mcpLoadErr != "" {
+		// Never rewrite a file we could not parse.
+		if key.Matches(msg, m.keys.Back) {
+			m.mode = ModeMenu
+		}
+		return m, nil
+	}
+	n := len(m.mcpList)
+
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		if m.mcpCursor > 0 {
+			m.mcpCursor--
+		}
+	case key.Matches(msg, m.keys.Down):
+		if m.mcpCursor < n-1 {
+			m.mcpCursor++
+		}
+	case key.Matches(msg, m.keys.Add):
+		m.mcpDraft = mcp.Server{Type: mcp.Stdio}
+		m.mcpEditIdx = -1
+		return m.openMCPForm()
+	case key.Matches(msg, m.keys.Back):
+		m.mode = ModeMenu
+		return m, nil
+	}
+	if n == 0 {
+		return m, nil
+	}
+
+	switch {
+	case key.Matches(msg, m.keys.Select, m.keys.Edit):
+		m.mcpDraft = m.mcpList[m.mcpCursor].Clone()
+		m.mcpEditIdx = m.mcpCursor
+		return m.openMCPForm()
+	case key.Matches(msg, m.keys.Toggle):
+		list := slices.Clone(m.mcpList)
+		list[m.mcpCursor].Disabled = !list[m.mcpCursor].Disabled
+		return m.saveMCP(list, list[m.mcpCursor].Name)
+	case key.Matches(msg, m.keys.Test):
+		m.mcpDraft = m.mcpList[m.mcpCursor]
+		return m.openMCPTest(ModeMCP)
+	case key.Matches(msg, m.keys.Delete):
+		m.mode = ModeMCPConfirm
+	}
+	return m, nil
+}
+
+// saveMCP replaces the list, keeps the cursor on the server named focus and
+// writes mcp.json.
+func (m Model) saveMCP(list []mcp.Server, focus string) (tea.Model, tea.Cmd) {
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	m.mcpList = list
+	m.mcpCursor = min(m.mcpCursor, max(len(list)-1, 0))
+	for i, s := range list {
+		if s.Name == focus {
+			m.mcpCursor = i
+		}
+	}
+	m.refreshMCPProblems()
+	m.mode = ModeMCP
+	return m, writeMCP(list)
+}
+
+// refreshMCPProblems checks every server once, so rendering does not search
+// PATH.
+func (m *Model) refreshMCPProblems() {
+	m.mcpProblems = map[string][]string{}
+	for _, s := range m.mcpList {
+		m.mcpProblems[s.Name] = s.Problems()
+	}
+}
+
+func (m Model) openMCPForm() (tea.Model, tea.Cmd) {
+	cursor := m.mcpForm.cursor
+	m.mcpForm = buildMCPForm(m.mcpDraft)
+	if m.mode != ModeMCP {
+		m.mcpForm.cursor = min(cursor, len(m.mcpForm.items)-1)
+	}
+	m.mode = ModeMCPForm
+	return m, nil
+}
+
+func buildMCPForm(s mcp.Server) customList {
+	transport := "stdio · a local process Droid starts"
+	if s.Remote() {
+		transport = s.Type + " · a remote server reached by URL"
+	}
+	enabled := "Yes"
+	if s.Disabled {
+		enabled = "No · Droid does not connect to it"
+	}
+	items := []listItem{
+		{label: "Name", value: "name", sub: orDef(s.Name, "—")},
+		{label: "Transport", value: "type", sub: transport},
+	}
+	if s.Remote() {
+		items = append(items,
+			listItem{label: "URL", value: "url", sub: orDef(s.URL, "—")},
+			listItem{label: "Headers", value: "headers", sub: mapKeysLabel(s.Headers)},
+		)
+	} else {
+		items = append(items,
+			listItem{label: "Command", value: "command", sub: orDef(s.Command, "—")},
+			listItem{label: "Arguments", value: "args", sub: orDef(strings.Join(s.Args, " "), "none")},
+			listItem{label: "Environment", value: "env", sub: mapKeysLabel(s.Env)},
+		)
+	}
+	items = append(items, listItem{label: "Enabled", value: "enabled", sub: enabled})
+	if !s.Remote() {
+		items = append(items, listItem{label: "Test launch", value: "test", sub: "start it, run the MCP handshake and list its tools"})
+	}
+	items = append(items,
+		listItem{label: "✓ Save server", value: "save"},
+		listItem{label: "← Cancel", value: "cancel"},
+	)
+	return newList(items, false, len(items))
+}
+
+// mapKeysLabel lists the names of env variables or headers; values stay off
+// screen since they are often secrets.
+func mapKeysLabel(m map[string]string) string {
+	if len(m) == 0 {
+		return "none"
+	}
+	return strings.Join(slices.Sorted(maps.Keys(m)), ", ")
+}
+
+func (m Model) handleMCPFormKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		m.mcpForm.up()
+	case key.Matches(msg, m.keys.Down):
+		m.mcpForm.down()
+	case key.Matches(msg, m.keys.Back):
+		m.mode = ModeMCP
+	case key.Matches(msg, m.keys.Select):
+		switch field := m.mcpForm.items[m.mcpForm.cursor].value; field {
+		case "name":
+			return m.focusMCPInput(field, m.mcpDraft.Name, "e.g. github")
+		case "type":
+			if m.mcpDraft.Remote() {
+				m.mcpDraft.Type = mcp.Stdio
+			} else {
+				m.mcpDraft.Type = mcp.HTTP
+			}
+			return m.openMCPForm()
+		case "command":
+			return m.focusMCPInput(field, m.mcpDraft.Command, "npx, uvx, docker or a path · ${VAR} is expanded")
+		case "url":
+			return m.focusMCPInput(field, m.mcpDraft.URL, "https://example.com/mcp")
+		case "args":
+			return m.focusMCPLines(field, strings.Join(m.mcpDraft.Args, "\n"), "one argument per line")
+		case "env":
+			return m.focusMCPLines(field, joinPairs(m.mcpDraft.Env, "="), "KEY=value per line · value may be ${VAR}")
+		case "headers":
+			return m.focusMCPLines(field, joinPairs(m.mcpDraft.Headers, ": "), "Name: value per line · e.g. Authorization: Bearer ${TOKEN}")
+		case "enabled":
+			m.mcpDraft.Disabled = !m.mcpDraft.Disabled
+			return m.openMCPForm()
+		case "test":
+			if m.mcpDraft.Command == "" {
+				m.err = "enter a command to launch"
+				return m, nil
+			}
+			return m.openMCPTest(ModeMCPForm)
+		case "save":
+			if err := m.mcpDraft.Validate(); err != nil {
+				m.err = err.Error()
+				return m, nil
+			}
+			list := slices.Clone(m.mcpList)
+			if m.mcpEditIdx >= 0 {
+				list[m.mcpEditIdx] = m.mcpDraft
+			} else {
+				list = append(list, m.mcpDraft)
+			}
+			return m.saveMCP(list, m.mcpDraft.Name)
+		case "cancel":
+			m.mode = ModeMCP
+		}
+	}
+	return m, nil
+}
+
+func joinPairs(m map[string]string, sep string) string {
+	var lines []string
+	for _, k := range slices.Sorted(maps.Keys(m)) {
+		lines = append(lines, k+sep+m[k])
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (m Model) focusMCPInput(field, value, placeholder string) (tea.Model, tea.Cmd) {
+	m.mcpField = field
+	m.textInput.Reset()
+	m.textInput.Placeholder = placeholder
+	m.textInput.SetValue(value)
+	m.textInput.Focus()
+	m.mode = ModeMCPInput
+	return m, nil
+}
+
+func (m Model) handleMCPInputKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Select):
+		text := strings.TrimSpace(m.textInput.Value())
+		switch m.mcpField {
+		case "name":
+			if text == "" {
+				m.err = "enter a name"
+				return m, nil
+			}
+			for i, s := range m.mcpList {
+				if s.Name == text && i != m.mcpEditIdx {
+					m.err = "a server named " + text + " already exists"
+					return m, nil
+				}
+			}
+			m.mcpDraft.Name = text
+		case "command":
+			if text == "" {
+				m.err = "enter a command"
+				return m, nil
+			}
+			m.mcpDraft.Command = text
+		case "url":
+			if err := mcp.ValidURL(text); err != nil {
+				m.err = err.Error()
+				return m, nil
+			}
+			m.mcpDraft.URL = text
+		}
+		m.textInput.Blur()
+		return m.openMCPForm()
+	case key.Matches(msg, m.keys.Back):
+		m.textInput.Blur()
+		return m.openMCPForm()
+	}
+	var cmd tea.Cmd
+	m.textInput, cmd = m.textInput.Update(msg)
+	return m, cmd
+}
+
+func (m Model) focusMCPLines(field, value, placeholder string) (tea.Model, tea.Cmd) {
+	m.mcpField = field
+	m.mcpLines.Placeholder = placeholder
+	m.mcpLines.SetValue(value)
+	m.mcpLines.Focus()
+	m.mode = ModeMCPLines
+	return m, nil
+}
+
+func (m Model) handleMCPLinesKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Save):
+		var lines []string
+		for _, line := range strings.Split(m.mcpLines.Value(), "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				lines = append(lines, line)
+			}
+		}
+		switch m.mcpField {
+		case "args":
+			m.mcpDraft.Args = lines
+		case "env", "headers":
+			sep := "="
+			if m.mcpField == "headers" {
+				sep = ":"
+			}
+			pairs := map[string]string{}
+			for _, line := range lines {
+				k, v, ok := strings.Cut(line, sep)
+				if k = strings.TrimSpace(k); !ok || k == "" {
+					m.err = fmt.Sprintf("%q: expected NAME%svalue", line, sep)
+					return m, nil
+				}
+				pairs[k] = strings.TrimSpace(v)
+			}
+			if m.mcpField == "env" {
+				m.mcpDraft.Env = pairs
+			} else {
+				m.mcpDraft.Headers = pairs
+			}
+		}
+		m.mcpLines.Blur()
+		return m.openMCPForm()
+	case key.Matches(msg, m.keys.Back):
+		m.mcpLines.Blur()
+		return m.openMCPForm()
+	}
+	var cmd tea.Cmd
+	m.mcpLines, cmd = m.mcpLines.Update(msg)
+	return m, cmd
+}
+
+func (m Model) openMCPTest(from AppMode) (tea.Model, tea.Cmd) {
+	m.mcpFrom = from
+	m.mcpReport = nil
+	m.mode = ModeMCPTest
+	if m.mcpDraft.Remote() {
+		report := mcp.Test(context.Background(), m.mcpDraft, "")
+		m.mcpReport = &report
+		return m, nil
+	}
+	m.mcpTesting = true
+	return m, testMCP(m.mcpDraft)
+}
+
+func (m Model) handleMCPTestKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Run):
+		if m.mcpTesting {
+			return m, nil
+		}
+		return m.openMCPTest(m.mcpFrom)
+	case key.Matches(msg, m.keys.Back, m.keys.Select):
+		if m.mcpFrom == ModeMCPForm {
+			return m.openMCPForm()
+		}
+		m.mode = m.mcpFrom
+	}
+	return m, nil
+}
+
+func (m Model) handleMCPConfirmKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Yes):
+		list := slices.Delete(slices.Clone(m.mcpList), m.mcpCursor, m.mcpCursor+1)
+		return m.saveMCP(list, "")
+	case key.Matches(msg, m.keys.No, m.keys.Back):
+		m.mode = ModeMCP
+	}
+	return m, nil
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Custom droids
+// ─────────────────────────────────────────────────────────────────────────────
+
+func (m Model) handleDroidsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		if m.droidCursor > 0 {
+			m.droidCursor--
+		}
+	case key.Matches(msg, m.keys.Down):
+		if m.droidCursor < len(m.droidList)-1 {
+			m.droidCursor++
+		}
+	case key.Matches(msg, m.keys.Add):
+		return m.startDroidWizard(droids.Droid{Model: droids.Inherit})
+	case key.Matches(msg, m.keys.Duplicate):
+		if len(m.droidList) == 0 {
+			break
+		}
+		src := m.droidList[m.droidCursor]
+		draft := droids.Droid{
+			Name:        src.Name + "-copy",
+			Description: src.Description,
+			Model:       src.Model,
+			Tools:       slices.Clone(src.Tools),
+			ToolsField:  src.ToolsField,
+			Prompt:      src.Prompt,
+			Scope:       src.Scope,
+		}
+		return m.startDroidWizard(draft)
+	case key.Matches(msg, m.keys.Reload):
+		return m, m.loadDroids("")
+	case key.Matches(msg, m.keys.Back):
+		m.mode = ModeMenu
+	}
+	return m, nil
+}
+
+// knownModels returns the model values a droid may use besides inherit:
+// the built-in model options and the customModels IDs.
+func (m Model) knownModels() map[string]bool {
+	known := map[string]bool{}
+	if def, ok := lookupSetting("model"); ok {
+		for _, o := range def.Options {
+			known[o.Value] = true
+		}
+	}
+	for _, cm := range m.customModels {
+		known[cm.ID] = true
+	}
+	return known
+}
+
+func (m Model) startDroidWizard(draft droids.Droid) (tea.Model, tea.Cmd) {
+	m.droidDraft = draft
+	m.mode = ModeDroidWizard
+	return m.droidStepTo(DroidScope)
+}
+
+// droidStepTo shows a wizard step, filled in from the draft.
+func (m Model) droidStepTo(step DroidStep) (tea.Model, tea.Cmd) {
+	m.droidStep = step
+	m.textInput.Blur()
+	m.droidPrompt.Blur()
+	d := m.droidDraft
+	home, cwd := homeAndCwd()
+
+	switch step {
+	case DroidScope:
+		m.droidPick = newList([]listItem{
+			{label: "Personal", value: "personal", sub: droids.PersonalDir(home)},
+			{label: "Project", value: "project", sub: droids.ProjectDir(cwd, home)},
+		}, false, listHeight(m.height))
+		if d.Scope == droids.Project {
+			m.droidPick.cursor = 1
+		}
+	case DroidName:
+		m.focusInput("lowercase-name", d.Name)
+	case DroidDescription:
+		m.focusInput("When Droid should hand a task to this droid", d.Description)
+	case DroidModel:
+		items := []listItem{{label: "Inherit", value: droids.Inherit, sub: "Use the model of the session"}}
+		if def, ok := lookupSetting("model"); ok {
+			for _, o := range def.Options {
+				items = append(items, listItem{label: o.Title(), value: o.Value, sub: o.Desc})
+			}
+		}
+		for _, cm := range m.customModels {
+			items = append(items, customModelItem(cm))
+		}
+		m.droidPick = newList(items, false, listHeight(m.height))
+		m.droidPick.focusValue(orDef(d.Model, droids.Inherit))
+	case DroidTools:
+		items := make([]listItem, len(droids.Tools))
+		for i, t := range droids.Tools {
+			items[i] = listItem{label: t.ID, value: t.ID, sub: t.Category}
+		}
+		m.droidPick = newList(items, true, listHeight(m.height))
+		for i, t := range droids.Tools {
+			m.droidPick.selected[i] = slices.Contains(d.Tools, t.ID)
+		}
+	case DroidPrompt:
+		m.droidPrompt.SetValue(d.Prompt)
+		m.droidPrompt.Focus()
+	}
+	return m, nil
+}
+
+func (m Model) handleDroidWizardKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if key.Matches(msg, m.keys.Back) {
+		if m.droidStep == DroidScope {
+			m.mode = ModeDroids
+			return m, nil
+		}
+		return m.droidStepTo(m.droidStep - 1)
+	}
+
+	switch m.droidStep {
+	case DroidScope, DroidModel, DroidTools:
+		switch {
+		case key.Matches(msg, m.keys.Up):
+			m.droidPick.up()
+		case key.Matches(msg, m.keys.Down):
+			m.droidPick.down()
+		case key.Matches(msg, m.keys.Toggle):
+			m.droidPick.toggleCurrent()
+		case key.Matches(msg, m.keys.Select):
+			value := m.droidPick.items[m.droidPick.cursor].value
+			switch m.droidStep {
+			case DroidScope:
+				m.droidDraft.Scope = droids.Personal
+				if value == "project" {
+					m.droidDraft.Scope = droids.Project
+				}
+			case DroidModel:
+				m.droidDraft.Model = value
+			case DroidTools:
+				// No selection leaves the tools field out: every tool. A
+				// duplicated category is kept while its tools are unchanged.
+				tools := m.droidPick.selectedValues()
+				if !slices.Equal(tools, droids.CategoryTools(m.droidDraft.ToolsField)) {
+					m.droidDraft.ToolsField = ""
+				}
+				m.droidDraft.Tools = nil
+				if len(tools) > 0 {
+					m.droidDraft.Tools = tools
+				}
+			}
+			return m.droidStepTo(m.droidStep + 1)
+		}
+		return m, nil
+
+	case DroidName, DroidDescription:
+		if !key.Matches(msg, m.keys.Select) {
+			var cmd tea.Cmd
+			m.textInput, cmd = m.textInput.Update(msg)
+			return m, cmd
+		}
+		text := strings.TrimSpace(m.textInput.Value())
+		if m.droidStep == DroidName {
+			if err := droids.ValidName(text); err != nil {
+				m.err = err.Error()
+				return m, nil
+			}
+			home, cwd := homeAndCwd()
+			path := filepath.Join(droids.Dir(m.droidDraft.Scope, home, cwd), text+".md")
+			if _, err := os.Stat(path); err == nil {
+				m.err = path + " already exists"
+				return m, nil
+			}
+			m.droidDraft.Name = text
+		} else {
+			if text == "" {
+				m.err = "enter a description · Droid reads it to decide when to use the droid"
+				return m, nil
+			}
+			m.droidDraft.Description = text
+		}
+		return m.droidStepTo(m.droidStep + 1)
+
+	case DroidPrompt:
+		if key.Matches(msg, m.keys.Save) {
+			text := strings.TrimSpace(m.droidPrompt.Value())
+			if text == "" {
+				m.err = "write the droid's system prompt"
+				return m, nil
+			}
+			m.droidDraft.Prompt = text
+			return m.droidStepTo(DroidConfirm)
+		}
+		var cmd tea.Cmd
+		m.droidPrompt, cmd = m.droidPrompt.Update(msg)
+		return m, cmd
+
+	case DroidConfirm:
+		if key.Matches(msg, m.keys.Select) {
+			return m, createDroid(m.droidDraft)
+		}
+	}
+	return m, nil
+}
+
+// ─────────────────────────────────────────────────────────────────────────────
+// Advanced / other keys
+// ─────────────────────────────────────────────────────────────────────────────
+
+// managedKeys are unmodelled keys that another screen edits.
+var managedKeys = map[string]bool{
+	"customModels": true,
+	"hooks":        true,
+}
+
+// otherKeys returns the top-level keys of raw that neither the schema nor
+// another screen covers, sorted.
+func otherKeys(raw map[string]any) []string {
+	known := map[string]bool{}
+	for _, k := range config.SettingKeys() {
+		known[k] = true
+	}
+	var keys []string
+	for k := range raw {
+		if k == "hooks" && hooksUnreadable(raw[k]) {
+			// The Hooks screen refuses to edit it; fixing it happens here.
+			keys = append(keys, k)
+			continue
+		}
+		if !known[k] && !managedKeys[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func hooksUnreadable(section any) bool {
+	_, err := hooks.Parse(section, nil)
+	return err != nil
+}
+
+func buildOtherList(raw map[string]any, height int) customList {
+	keys := otherKeys(raw)
+	items := make([]listItem, len(keys))
+	for i, k := range keys {
+		v := raw[k]
+		items[i] = listItem{label: k, value: k, sub: jsonKind(v) + "  " + jsonPreview(v)}
+	}
+	return newList(items, false, height)
+}
+
+func (m Model) handleOtherKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if len(m.otherList.items) == 0 {
+		if key.Matches(msg, m.keys.Back) {
+			m.mode = ModeMenu
+		}
+		return m, nil
+	}
+	name := m.otherList.items[m.otherList.cursor].value
+	v := m.rawCfg[name]
+
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		m.otherList.up()
+	case key.Matches(msg, m.keys.Down):
+		m.otherList.down()
+	case key.Matches(msg, m.keys.Select, m.keys.Edit, m.keys.Toggle):
+		switch v := v.(type) {
+		case bool:
+			return m.saveRawKey(name, !v)
+		case map[string]any, []any:
+			m.treeKey = name
+			m.treeRoot = cloneJSON(v)
+			m.treeOpen = map[string]bool{"": true}
+			m.treeCursor, m.treeOffset = 0, 0
+			m.treeDirty = false
+			m.mode = ModeOtherTree
+			return m, nil
+		}
+		m.otherFrom = ModeOther
+		m.otherPath = nil
+		m.otherAdding = false
+		return m.focusOtherInput(editText(v), "")
+	case key.Matches(msg, m.keys.Delete):
+		m.otherFrom = ModeOther
+		m.otherPath = nil
+		m.mode = ModeOtherConfirm
+	case key.Matches(msg, m.keys.Back):
+		m.mode = ModeMenu
+	}
+	return m, nil
+}
+
+// saveRawKey writes one top-level key of settings.json.
+func (m Model) saveRawKey(key string, v any) (tea.Model, tea.Cmd) {
+	return m.writeRaw(func(raw map[string]any) { raw[key] = v }, key)
+}
+
+func (m Model) deleteRawKey(key string) (tea.Model, tea.Cmd) {
+	return m.writeRaw(func(raw map[string]any) { delete(raw, key) }, key)
+}
+
+// writeRaw applies change to a copy of rawCfg, so earlier model snapshots
+// keep their map, and saves it.
+func (m Model) writeRaw(change func(map[string]any), focus string) (tea.Model, tea.Cmd) {
+	raw := maps.Clone(m.rawCfg)
+	change(raw)
+	m.rawCfg = raw
+	cursor := m.otherList.cursor
+	m.otherList = buildOtherList(raw, listHeight(m.height))
+	m.otherList.cursor = min(cursor, max(len(m.otherList.items)-1, 0))
+	m.otherList.focusValue(focus)
+	m.mode = ModeOther
+	return m, saveSettings(m.settings, raw)
+}
+
+func (m Model) treeRows() []treeRow {
+	return flattenTree(m.treeRoot, m.treeKey, m.treeOpen)
+}
+
+func (m Model) handleOtherTreeKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	rows := m.treeRows()
+	m.treeCursor = min(m.treeCursor, len(rows)-1)
+	row := rows[m.treeCursor]
+
+	switch {
+	case key.Matches(msg, m.keys.Up):
+		if m.treeCursor > 0 {
+			m.treeCursor--
+		}
+	case key.Matches(msg, m.keys.Down):
+		if m.treeCursor < len(rows)-1 {
+			m.treeCursor++
+		}
+	case key.Matches(msg, m.keys.Left):
+		if isContainer(row.value) && m.treeOpen[pathKey(row.path)] {
+			delete(m.treeOpen, pathKey(row.path))
+		} else if len(row.path) > 0 {
+			m.treeCursor = m.treeRowIndex(row.path[:len(row.path)-1])
+		}
+	case key.Matches(msg, m.keys.Right):
+		if isContainer(row.value) {
+			m.treeOpen[pathKey(row.path)] = true
+		}
+	case key.Matches(msg, m.keys.Select, m.keys.Toggle, m.keys.Edit):
+		switch v := row.value.(type) {
+		case map[string]any, []any:
+			k := pathKey(row.path)
+			m.treeOpen[k] = !m.treeOpen[k]
+		case bool:
+			m.treeRoot = setPath(m.treeRoot, row.path, !v)
+			m.treeDirty = true
+		default:
+			m.otherFrom = ModeOtherTree
+			m.otherPath = row.path
+			m.otherAdding = false
+			return m.focusOtherInput(editText(v), "")
+		}
+	case key.Matches(msg, m.keys.Add):
+		// Add to the container under the cursor, or next to a leaf.
+		parent := row.path
+		if !isContainer(row.value) {
+			parent = row.path[:len(row.path)-1]
+		}
+		m.otherFrom = ModeOtherTree
+		m.otherAdding = true
+		m.otherNewKey = ""
+		m.treeOpen[pathKey(parent)] = true
+		switch c := getPath(m.treeRoot, parent).(type) {
+		case []any:
+			m.otherPath = appendPath(parent, len(c))
+			return m.focusOtherInput("", "JSON value or plain text")
+		default:
+			m.otherPath = parent
+			return m.focusOtherInput("", "member name")
+		}
+	case key.Matches(msg, m.keys.Delete):
+		m.otherFrom = ModeOtherTree
+		m.otherPath = row.path
+		m.mode = ModeOtherConfirm
+	case key.Matches(msg, m.keys.Back):
+		if !m.treeDirty {
+			m.mode = ModeOther
+			return m, nil
+		}
+		return m.saveRawKey(m.treeKey, m.treeRoot)
+	}
+	m.treeOffset = scrollWindow(m.treeCursor, m.treeOffset, listHeight(m.height))
+	return m, nil
+}
+
+// treeRowIndex returns the visible row of path, or 0.
+func (m Model) treeRowIndex(path []any) int {
+	k := pathKey(path)
+	for i, r := range m.treeRows() {
+		if pathKey(r.path) == k {
+			return i
+		}
+	}
+	return 0
+}
+
+// scrollWindow keeps cursor inside a window of height rows starting at offset.
+func scrollWindow(cursor, offset, height int) int {
+	if cursor < offset {
+		return cursor
+	}
+	if cursor >= offset+height {
+		return cursor - height + 1
+	}
+	return offset
+}
+
+func (m Model) focusOtherInput(value, placeholder string) (tea.Model, tea.Cmd) {
+	m.textInput.Reset()
+	m.textInput.Placeholder = placeholder
+	m.textInput.SetValue(value)
+	m.textInput.Focus()
+	m.mode = ModeOtherInput
+	return m, nil
+}
+
+func (m Model) handleOtherInputKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Select):
+		text := m.textInput.Value()
+
+		// New object member: the first enter names it.
+		if obj, ok := getPath(m.treeRoot, m.otherPath).(map[string]any); ok && m.otherAdding && m.otherNewKey == "" {
+			name := strings.TrimSpace(text)
+			if name == "" {
+				m.err = "enter a member name"
+				return m, nil
 			}
-			if m.cmdCursor > 0 {
-				m.cmdCursor--
+			if _, exists := obj[name]; exists {
+				m.err = name + " already exists"
+				return m, nil
 			}
+			m.otherNewKey = name
+			m.otherPath = appendPath(m.otherPath, name)
+			return m.focusOtherInput("", "JSON value or plain text")
 		}
-	case "esc":
-		m.settings.CommandAllowlist = m.allowCmds
-		m.settings.CommandDenylist = m.denyCmds
-		m.mode = ModeMenu
-		return m, saveSettings(m.settings, m.rawCfg)
-	}
-	return m, nil
-}
 
-func (m Model) handleCommandAddKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.String() {
-	case "enter":
-		val := strings.TrimSpace(m.cmdInput.Value())
-		if val != "" {
-			if m.cmdFocusCol == 0 {
-				m.allowCmds = append(m.allowCmds, val)
+		var val any
+		if m.otherAdding {
+			val = parseJSONValue(text)
+		} else {
+			var old any
+			if m.otherFrom == ModeOther {
+				old = m.rawCfg[m.otherList.items[m.otherList.cursor].value]
 			} else {
-				m.denyCmds = append(m.denyCmds, val)
+				old = getPath(m.treeRoot, m.otherPath)
 			}
+			v, err := parseLike(old, text)
+			if err != nil {
+				m.err = err.Error()
+				return m, nil
+			}
+			val = v
 		}
-		m.cmdInput.Blur()
-		m.mode = ModeCommandEdit
-	case "esc":
-		m.cmdInput.Blur()
-		m.mode = ModeCommandEdit
-	default:
-		var cmd tea.Cmd
-		m.cmdInput, cmd = m.cmdInput.Update(msg)
-		return m, cmd
+		m.textInput.Blur()
+
+		if m.otherFrom == ModeOther {
+			return m.saveRawKey(m.otherList.items[m.otherList.cursor].value, val)
+		}
+		m.treeRoot = setPath(m.treeRoot, m.otherPath, val)
+		m.treeDirty = true
+		m.mode = ModeOtherTree
+		m.treeCursor = m.treeRowIndex(m.otherPath)
+		m.treeOffset = scrollWindow(m.treeCursor, m.treeOffset, listHeight(m.height))
+		return m, nil
+
+	case key.Matches(msg, m.keys.Back):
+		m.textInput.Blur()
+		m.mode = m.otherFrom
+		return m, nil
 	}
-	return m, nil
+	var cmd tea.Cmd
+	m.textInput, cmd = m.textInput.Update(msg)
+	return m, cmd
 }
 
-func (m Model) activeCommandList() []string {
-	if m.cmdFocusCol == 0 {
-		return m.allowCmds
+func (m Model) handleOtherConfirmKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Yes):
+		if m.otherFrom == ModeOther {
+			return m.deleteRawKey(m.otherList.items[m.otherList.cursor].value)
+		}
+		if len(m.otherPath) == 0 {
+			return m.deleteRawKey(m.treeKey)
+		}
+		m.treeRoot = deletePath(m.treeRoot, m.otherPath)
+		m.treeDirty = true
+		m.treeCursor = min(m.treeCursor, len(m.treeRows())-1)
+		m.mode = ModeOtherTree
+	case key.Matches(msg, m.keys.No, m.keys.Back):
+		m.mode = m.otherFrom
 	}
-	return m.denyCmds
+	return m, nil
 }
 
 // ─────────────────────────────────────────────────────────────────────────────
@@ -420,14 +2596,18 @@ func (m Model) handleBYOKKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch m.byokStep {
 
 	case WizProvider:
-		switch msg.String() {
-		case "esc":
+		switch {
+		case key.Matches(msg, m.keys.Back):
 			m.mode = ModeMenu
-		case "up", "k":
+		case key.Matches(msg, m.keys.Up):
 			m.providerList.up()
-		case "down", "j":
+		case key.Matches(msg, m.keys.Down):
 			m.providerList.down()
-		case "enter":
+		case key.Matches(msg, m.keys.MoveUp):
+			return m.wizMoveGroup(-1)
+		case key.Matches(msg, m.keys.MoveDown):
+			return m.wizMoveGroup(1)
+		case key.Matches(msg, m.keys.Select):
 			if len(m.providerList.items) == 0 {
 				break
 			}
@@ -435,41 +2615,59 @@ func (m Model) handleBYOKKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 
 	case WizGroupDetail:
-		switch msg.String() {
-		case "esc":
+		switch {
+		case key.Matches(msg, m.keys.Back):
 			m.byokStep = WizProvider
-		case "up", "k":
+		case key.Matches(msg, m.keys.Up):
 			m.detailList.up()
-		case "down", "j":
+		case key.Matches(msg, m.keys.Down):
 			m.detailList.down()
-		case "enter":
+		case key.Matches(msg, m.keys.MoveUp):
+			return m.wizMoveModel(-1)
+		case key.Matches(msg, m.keys.MoveDown):
+			return m.wizMoveModel(1)
+		case key.Matches(msg, m.keys.Select):
 			return m.wizHandleGroupAction(m.detailList.items[m.detailList.cursor].value)
+		case key.Matches(msg, m.keys.Delete):
+			value := m.detailList.items[m.detailList.cursor].value
+			if !strings.HasPrefix(value, "model:") {
+				break
+			}
+			next, _ := m.wizHandleGroupAction(value)
+			return next.(Model).wizEnterModelField("delete")
 		}
 
 	case WizModelEdit:
-		switch msg.String() {
-		case "esc":
+		switch {
+		case key.Matches(msg, m.keys.Back):
 			m.byokStep = WizGroupDetail
 			g := m.providerGroups[m.currentGroupIdx]
 			m.detailList = buildGroupDetailList(g)
-		case "up", "k":
+		case key.Matches(msg, m.keys.Up):
 			m.detailList.up()
-		case "down", "j":
+		case key.Matches(msg, m.keys.Down):
 			m.detailList.down()
-		case "enter":
+		case key.Matches(msg, m.keys.Select):
 			field := m.detailList.items[m.detailList.cursor].value
 			return m.wizEnterModelField(field)
+		case key.Matches(msg, m.keys.Delete):
+			return m.wizEnterModelField("delete")
 		}
 
 	case WizModelField:
 		return m.handleModelFieldKey(msg)
 
+	case WizFetching:
+		if key.Matches(msg, m.keys.Back) {
+			m.cancelFetch()
+		}
+
 	case WizURL:
-		switch msg.String() {
-		case "esc":
+		switch {
+		case key.Matches(msg, m.keys.Back):
 			m.byokStep = WizProvider
 			m.textInput.Blur()
-		case "enter":
+		case key.Matches(msg, m.keys.Select):
 			return m.wizSubmitURL()
 		default:
 			var cmd tea.Cmd
@@ -478,11 +2676,11 @@ func (m Model) handleBYOKKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 
 	case WizTitle:
-		switch msg.String() {
-		case "esc":
+		switch {
+		case key.Matches(msg, m.keys.Back):
 			m.byokStep = WizURL
 			m.focusInput("", m.baseURL)
-		case "enter":
+		case key.Matches(msg, m.keys.Select):
 			return m.wizSubmitTitle()
 		default:
 			var cmd tea.Cmd
@@ -491,11 +2689,11 @@ func (m Model) handleBYOKKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 
 	case WizKey:
-		switch msg.String() {
-		case "esc":
+		switch {
+		case key.Matches(msg, m.keys.Back):
 			m.byokStep = WizTitle
 			m.focusInput("", m.displayTitle)
-		case "enter":
+		case key.Matches(msg, m.keys.Select):
 			return m.wizSubmitKey()
 		default:
 			var cmd tea.Cmd
@@ -504,17 +2702,22 @@ func (m Model) handleBYOKKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 
 	case WizModels:
-		switch msg.String() {
-		case "esc":
-			m.byokStep = WizKey
-			m.focusInput("sk-... or ${ENV_VAR}", "")
-		case "up", "k":
+		// Without a fetched list the model ID is typed, so list keys type.
+		listed := len(m.availableModels) > 0
+		switch {
+		case key.Matches(msg, m.keys.Back):
+			m.cancelFetch()
+		case listed && key.Matches(msg, m.keys.Up):
 			m.modelList.up()
-		case "down", "j":
+		case listed && key.Matches(msg, m.keys.Down):
 			m.modelList.down()
-		case " ":
+		case listed && key.Matches(msg, m.keys.Toggle):
 			m.modelList.toggleCurrent()
-		case "enter":
+		case key.Matches(msg, m.keys.Retry):
+			if (len(m.availableModels) == 0 || m.fetchErr != nil) && m.modelsEndpoint != "" {
+				return m, m.startFetch()
+			}
+		case key.Matches(msg, m.keys.Select):
 			if m.modelList.multi {
 				sel := m.modelList.selectedValues()
 				if len(sel) == 0 {
@@ -536,7 +2739,7 @@ func (m Model) handleBYOKKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				m.focusInput("16384", strconv.Itoa(m.maxOutputTokens))
 			}
 		default:
-			if len(m.availableModels) == 0 {
+			if !listed {
 				var cmd tea.Cmd
 				m.textInput, cmd = m.textInput.Update(msg)
 				return m, cmd
@@ -544,10 +2747,10 @@ func (m Model) handleBYOKKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 
 	case WizSettingsTokens:
-		switch msg.String() {
-		case "esc":
+		switch {
+		case key.Matches(msg, m.keys.Back):
 			m.byokStep = WizModels
-		case "enter":
+		case key.Matches(msg, m.keys.Select):
 			val := strings.TrimSpace(m.textInput.Value())
 			if val == "" {
 				val = "16384"
@@ -567,30 +2770,30 @@ func (m Model) handleBYOKKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 
 	case WizSettingsImages:
-		switch msg.String() {
-		case "esc":
+		switch {
+		case key.Matches(msg, m.keys.Back):
 			m.byokStep = WizSettingsTokens
 			m.focusInput("16384", strconv.Itoa(m.maxOutputTokens))
-		case "up", "k":
+		case key.Matches(msg, m.keys.Up):
 			m.detailList.up()
-		case "down", "j":
+		case key.Matches(msg, m.keys.Down):
 			m.detailList.down()
-		case "enter":
+		case key.Matches(msg, m.keys.Select):
 			m.supportsImages = m.detailList.items[m.detailList.cursor].value == "yes"
 			m.byokStep = WizConfirm
 			m.detailList = buildConfirmList()
 		}
 
 	case WizConfirm:
-		switch msg.String() {
-		case "esc":
+		switch {
+		case key.Matches(msg, m.keys.Back):
 			m.byokStep = WizSettingsImages
 			m.detailList = buildImagesList()
-		case "up", "k":
+		case key.Matches(msg, m.keys.Up):
 			m.detailList.up()
-		case "down", "j":
+		case key.Matches(msg, m.keys.Down):
 			m.detailList.down()
-		case "enter":
+		case key.Matches(msg, m.keys.Select):
 			if m.detailList.items[m.detailList.cursor].value == "yes" {
 				m.byokStep = WizSaving
 				return m, wizSaveAll(m)
@@ -599,17 +2802,16 @@ func (m Model) handleBYOKKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 
 	case WizDone:
-		switch msg.String() {
-		case "up", "k":
+		switch {
+		case key.Matches(msg, m.keys.Up):
 			m.detailList.up()
-		case "down", "j":
+		case key.Matches(msg, m.keys.Down):
 			m.detailList.down()
-		case "enter":
+		case key.Matches(msg, m.keys.Select):
 			switch m.detailList.items[m.detailList.cursor].value {
 			case "same":
 				m.selectedModels = nil
-				m.byokStep = WizFetching
-				return m, cmdFetchModels(m)
+				return m, m.startFetch()
 			case "other":
 				m.byokStep = WizProvider
 				return m, loadProviderGroups()
@@ -627,6 +2829,10 @@ func (m Model) handleBYOKKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 // ─────────────────────────────────────────────────────────────────────────────
 
 func (m Model) wizHandleProviderSelect(value string) (tea.Model, tea.Cmd) {
+	if value == "dedupe" {
+		return m, wizMergeDuplicates()
+	}
+
 	// Existing provider group
 	if strings.HasPrefix(value, "group:") {
 		prefix := strings.TrimPrefix(value, "group:")
@@ -644,9 +2850,11 @@ func (m Model) wizHandleProviderSelect(value string) (tea.Model, tea.Cmd) {
 				// Look up models endpoint from known providers
 				if p := providers.Get(g.Prefix); p != nil {
 					m.modelsEndpoint = p.ModelsEndpoint
+					m.modelsFormat = p.Format()
 					m.noAuth = p.NoAuth
 				} else {
 					m.modelsEndpoint = "/models"
+					m.modelsFormat = m.providerType
 					m.noAuth = false
 				}
 				m.byokStep = WizGroupDetail
@@ -667,6 +2875,7 @@ func (m Model) wizHandleProviderSelect(value string) (tea.Model, tea.Cmd) {
 	m.providerType = p.Type
 	m.noAuth = p.NoAuth
 	m.modelsEndpoint = p.ModelsEndpoint
+	m.modelsFormat = p.Format()
 
 	if p.RequiresBaseURL {
 		m.byokStep = WizURL
@@ -677,8 +2886,7 @@ func (m Model) wizHandleProviderSelect(value string) (tea.Model, tea.Cmd) {
 	m.displayTitle = p.Name
 	if p.NoAuth {
 		m.apiKey = "not-needed"
-		m.byokStep = WizFetching
-		return m, cmdFetchModels(m)
+		return m, m.startFetch()
 	}
 	m.byokStep = WizKey
 	m.focusInput("sk-... or ${ENV_VAR}", "")
@@ -700,14 +2908,65 @@ func (m Model) wizHandleGroupAction(action string) (tea.Model, tea.Cmd) {
 		}
 	case action == "add-models":
 		m.selectedModels = nil
-		m.byokStep = WizFetching
-		return m, cmdFetchModels(m)
+		return m, m.startFetch()
 	case action == "back":
 		m.byokStep = WizProvider
 	}
 	return m, nil
 }
 
+// refreshGroups replaces the provider groups after a write and rebuilds the
+// visible list, keeping the cursor on the item with value focus.
+func (m *Model) refreshGroups(groups []config.ProviderGroup, focus string) {
+	m.providerGroups = groups
+	m.providerList = buildProviderList(groups)
+	m.providerList.height = listHeight(m.height)
+	if m.byokStep == WizProvider {
+		m.providerList.focusValue(focus)
+	}
+	if m.byokStep == WizGroupDetail {
+		for i, g := range groups {
+			if g.Prefix == m.providerKey {
+				m.currentGroupIdx = i
+				m.detailList = buildGroupDetailList(g)
+				m.detailList.focusValue(focus)
+			}
+		}
+	}
+}
+
+// wizMoveModel moves the model under the cursor up (-1) or down (+1) within its group.
+func (m Model) wizMoveModel(delta int) (tea.Model, tea.Cmd) {
+	g := m.providerGroups[m.currentGroupIdx]
+	cur := m.detailList.cursor
+	pos := cur + delta
+	if cur >= len(g.Models) || pos < 0 || pos >= len(g.Models) {
+		return m, nil
+	}
+	return m, cmdMoveModel(g.Models[cur].ID, pos)
+}
+
+// wizMoveGroup moves the provider group under the cursor up (-1) or down (+1).
+func (m Model) wizMoveGroup(delta int) (tea.Model, tea.Cmd) {
+	if len(m.providerList.items) == 0 {
+		return m, nil
+	}
+	prefix, ok := strings.CutPrefix(m.providerList.items[m.providerList.cursor].value, "group:")
+	if !ok {
+		return m, nil
+	}
+	for i, g := range m.providerGroups {
+		if g.Prefix == prefix {
+			pos := i + delta
+			if pos < 0 || pos >= len(m.providerGroups) {
+				return m, nil
+			}
+			return m, cmdMoveGroup(prefix, pos)
+		}
+	}
+	return m, nil
+}
+
 func (m Model) wizEnterModelField(field string) (tea.Model, tea.Cmd) {
 	m.editFieldKey = field
 
@@ -745,6 +3004,11 @@ func (m Model) wizEnterModelField(field string) (tea.Model, tea.Cmd) {
 		if m.editingModel.SupportsImages {
 			m.detailList.cursor = 1
 		}
+	case "variant":
+		m.byokStep = WizModelField
+		m.editFieldKey = "variantSuffix"
+		m.variant = config.VariantSpec{}
+		m.focusInput("e.g. fast, high-reasoning", "")
 	case "delete":
 		m.byokStep = WizModelField
 		m.detailList = newList([]listItem{
@@ -762,28 +3026,41 @@ func (m Model) wizEnterModelField(field string) (tea.Model, tea.Cmd) {
 func (m Model) handleModelFieldKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch m.editFieldKey {
 	case "displayName", "model", "baseUrl", "apiKey", "maxOutputTokens":
-		switch msg.String() {
-		case "esc":
+		switch {
+		case key.Matches(msg, m.keys.Back):
 			m.textInput.Blur()
 			m.byokStep = WizModelEdit
 			m.detailList = buildModelEditList(m.editingModel)
-		case "enter":
+		case key.Matches(msg, m.keys.Select):
 			return m.wizSaveModelField()
 		default:
 			var cmd tea.Cmd
 			m.textInput, cmd = m.textInput.Update(msg)
 			return m, cmd
 		}
+	case "variantSuffix", "variantName", "variantTokens", "variantArgs":
+		switch {
+		case key.Matches(msg, m.keys.Back):
+			m.textInput.Blur()
+			m.byokStep = WizModelEdit
+			m.detailList = buildModelEditList(m.editingModel)
+		case key.Matches(msg, m.keys.Select):
+			return m.wizVariantNext()
+		default:
+			var cmd tea.Cmd
+			m.textInput, cmd = m.textInput.Update(msg)
+			return m, cmd
+		}
 	case "provider", "supportsImages", "delete":
-		switch msg.String() {
-		case "esc":
+		switch {
+		case key.Matches(msg, m.keys.Back):
 			m.byokStep = WizModelEdit
 			m.detailList = buildModelEditList(m.editingModel)
-		case "up", "k":
+		case key.Matches(msg, m.keys.Up):
 			m.detailList.up()
-		case "down", "j":
+		case key.Matches(msg, m.keys.Down):
 			m.detailList.down()
-		case "enter":
+		case key.Matches(msg, m.keys.Select):
 			return m.wizSaveModelField()
 		}
 	}
@@ -843,6 +3120,50 @@ func (m Model) wizSaveModelField() (tea.Model, tea.Cmd) {
 	return m, wizPersistModel(m.editingModel)
 }
 
+// wizVariantNext records the current variant prompt and advances to the next:
+// suffix → display name → max tokens → extraArgs overrides → save.
+func (m Model) wizVariantNext() (tea.Model, tea.Cmd) {
+	base := m.editingModel
+	val := strings.TrimSpace(m.textInput.Value())
+	switch m.editFieldKey {
+	case "variantSuffix":
+		if val == "" {
+			m.err = "enter a suffix for the variant"
+			return m, nil
+		}
+		m.variant.Suffix = val
+		m.editFieldKey = "variantName"
+		m.focusInput("Display name", base.DisplayName+" ("+val+")")
+	case "variantName":
+		m.variant.DisplayName = val
+		m.editFieldKey = "variantTokens"
+		m.focusInput("Max tokens", strconv.Itoa(base.MaxOutputTokens))
+	case "variantTokens":
+		if val != "" {
+			n, err := strconv.Atoi(val)
+			if err != nil || n < 1 {
+				m.err = "enter a valid number"
+				return m, nil
+			}
+			m.variant.MaxOutputTokens = n
+		}
+		m.editFieldKey = "variantArgs"
+		m.focusInput(`{"reasoning": {"effort": "high"}}`, "")
+	case "variantArgs":
+		if val != "" {
+			var args map[string]any
+			if err := json.Unmarshal([]byte(val), &args); err != nil || args == nil {
+				m.err = "extraArgs must be a JSON object"
+				return m, nil
+			}
+			m.variant.ExtraArgs = args
+		}
+		m.textInput.Blur()
+		return m, wizSaveVariant(base, m.variant)
+	}
+	return m, nil
+}
+
 func (m Model) wizSubmitURL() (tea.Model, tea.Cmd) {
 	raw := strings.TrimSpace(m.textInput.Value())
 	normalized, err := api.NormalizeURL(raw)
@@ -876,8 +3197,44 @@ func (m Model) wizSubmitKey() (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 	m.apiKey = key
+	return m, m.startFetch()
+}
+
+// startFetch moves the wizard to WizFetching and returns the fetch command.
+// The current step is remembered so esc can cancel back to it.
+func (m *Model) startFetch() tea.Cmd {
+	if m.fetchCancel != nil {
+		m.fetchCancel()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	m.fetchSeq++
+	m.fetchCancel = cancel
+	m.fetchErr = nil
+	if m.byokStep != WizFetching && m.byokStep != WizModels {
+		m.fetchFrom = m.byokStep
+	}
 	m.byokStep = WizFetching
-	return m, cmdFetchModels(m)
+	m.textInput.Blur()
+	return cmdFetchModels(ctx, *m)
+}
+
+// cancelFetch aborts any in-flight fetch and returns to the step it started from.
+func (m *Model) cancelFetch() {
+	if m.fetchCancel != nil {
+		m.fetchCancel()
+		m.fetchCancel = nil
+	}
+	m.byokStep = m.fetchFrom
+	switch m.fetchFrom {
+	case WizKey:
+		m.focusInput("sk-... or ${ENV_VAR}", m.apiKey)
+	case WizGroupDetail:
+		m.detailList = buildGroupDetailList(m.providerGroups[m.currentGroupIdx])
+	case WizDone:
+		m.detailList = buildDoneList()
+	default:
+		m.byokStep = WizProvider
+	}
 }
 
 func (m *Model) focusInput(placeholder, defaultVal string) {
@@ -901,6 +3258,15 @@ func buildProviderList(groups []config.ProviderGroup) customList {
 		items = append(items, listItem{label: name, value: "group:" + g.Prefix, sub: sub})
 	}
 
+	// Offer a cleanup when the same model is configured more than once
+	var all []config.ModelConfig
+	for _, g := range groups {
+		all = append(all, g.Models...)
+	}
+	if n := countDuplicates(all); n > 0 {
+		items = append(items, listItem{label: "⚠ Merge duplicates", value: "dedupe", sub: fmt.Sprintf("%d extra copy(ies)", n)})
+	}
+
 	// Then known provider templates (skip ones that already have a group)
 	existing := map[string]bool{}
 	for _, g := range groups {
@@ -956,6 +3322,7 @@ func buildModelEditList(model config.ModelConfig) customList {
 		{label: "API Type", value: "provider", sub: apiTypeLabel},
 		{label: "Max Tokens", value: "maxOutputTokens", sub: strconv.Itoa(model.MaxOutputTokens)},
 		{label: "Image Support", value: "supportsImages", sub: images},
+		{label: "Duplicate as variant", value: "variant"},
 		{label: "Delete Model", value: "delete"},
 		{label: "← Back", value: "back"},
 	}
@@ -969,14 +3336,44 @@ func maskKey(key string) string {
 	return key[:4] + "..." + key[len(key)-4:]
 }
 
-func buildModelList(models []api.ModelInfo) customList {
+func buildModelList(models []api.ModelInfo, configured map[string]bool) customList {
 	items := make([]listItem, len(models))
 	for i, m := range models {
-		items[i] = listItem{label: m.Name, value: m.ID}
+		sub := modelMeta(m)
+		if configured[m.ID] {
+			sub = strings.TrimSuffix("✓ configured  "+sub, "  ")
+		}
+		items[i] = listItem{label: m.Name, value: m.ID, sub: sub}
 	}
 	return newList(items, true, 12)
 }
 
+func countDuplicates(models []config.ModelConfig) int {
+	n := 0
+	for _, set := range config.FindDuplicates(models) {
+		n += len(set.Drop)
+	}
+	return n
+}
+
+// modelMeta summarises the optional metadata a provider returned for a model.
+func modelMeta(m api.ModelInfo) string {
+	var parts []string
+	switch {
+	case m.ContextLength >= 1000:
+		parts = append(parts, fmt.Sprintf("%dk ctx", m.ContextLength/1000))
+	case m.ContextLength > 0:
+		parts = append(parts, fmt.Sprintf("%d ctx", m.ContextLength))
+	}
+	if m.OwnedBy != "" {
+		parts = append(parts, m.OwnedBy)
+	}
+	if !m.Created.IsZero() {
+		parts = append(parts, m.Created.Format("2006-01"))
+	}
+	return strings.Join(parts, " · ")
+}
+
 func buildImagesList() customList {
 	return newList([]listItem{
 		{label: "No", value: "no"},
@@ -999,6 +3396,36 @@ func buildDoneList() customList {
 	}, false, 5)
 }
 
+func buildPresetList(presets []policy.Preset) customList {
+	items := make([]listItem, len(presets))
+	for i, p := range presets {
+		sub := fmt.Sprintf("+%d allow  +%d deny", len(p.Allow), len(p.Deny))
+		if p.Source != "" {
+			sub += "  · " + filepath.Base(p.Source)
+		}
+		items[i] = listItem{label: p.Name, value: p.Name, sub: sub}
+	}
+	return newList(items, false, 10)
+}
+
+// customModelItem lists a BYOK model in the Default Model picker. Its value
+// is the customModels id, which is how Droid references a custom model.
+func customModelItem(cm config.ModelConfig) listItem {
+	label := cm.DisplayName
+	if label == "" {
+		label = cm.Model
+	}
+	return listItem{label: label, value: cm.ID, sub: "BYOK · " + config.IDPrefix(cm.ID) + " · " + cm.ID}
+}
+
+func buildSuggestionList(suggestions []policy.Suggestion) customList {
+	items := make([]listItem, len(suggestions))
+	for i, s := range suggestions {
+		items[i] = listItem{label: s.Entry, value: s.Entry, sub: fmt.Sprintf("×%d", s.Count)}
+	}
+	return newList(items, true, 10)
+}
+
 func listHeight(h int) int {
 	// Reserve lines for header (~4), footer (2), flash/error (2), padding.
 	usable := h - 8
@@ -1019,6 +3446,24 @@ func loadAllSettings() tea.Cmd {
 	}
 }
 
+func loadPresets() tea.Cmd {
+	return func() tea.Msg {
+		presets, err := policy.LoadPresets(policy.PresetDir(config.WrenchDir()))
+		return presetsLoadedMsg{presets: presets, err: err}
+	}
+}
+
+// suggestionLimit caps how many history suggestions are offered.
+const suggestionLimit = 50
+
+func loadSuggestions(allow, deny []string) tea.Cmd {
+	return func() tea.Msg {
+		home, _ := os.UserHomeDir()
+		s, files, err := policy.SuggestFromHistory(home, allow, deny, suggestionLimit)
+		return suggestionsLoadedMsg{suggestions: s, files: files, err: err}
+	}
+}
+
 func loadProviderGroups() tea.Cmd {
 	return func() tea.Msg {
 		groups, _ := config.ReadProviderGroups()
@@ -1035,35 +3480,203 @@ func saveSettings(s config.Settings, raw map[string]any) tea.Cmd {
 	}
 }
 
-func cmdFetchModels(m Model) tea.Cmd {
+func loadHooks() tea.Cmd {
+	return func() tea.Msg {
+		section, disabled, err := config.ReadHooks()
+		if err != nil {
+			return hooksLoadedMsg{err: err}
+		}
+		list, err := hooks.Parse(section, disabled)
+		return hooksLoadedMsg{list: list, err: err}
+	}
+}
+
+// loadDroids reads the droid files; focus is the path to put the cursor on.
+func (m Model) loadDroids(focus string) tea.Cmd {
+	models := m.knownModels()
+	return func() tea.Msg {
+		home, cwd := homeAndCwd()
+		list, err := droids.Load(home, cwd, models)
+		return droidsLoadedMsg{list: list, focus: focus, err: err}
+	}
+}
+
+// homeAndCwd returns the directories droid locations are resolved from.
+func homeAndCwd() (home, cwd string) {
+	home, _ = os.UserHomeDir()
+	cwd, _ = os.Getwd()
+	return home, cwd
+}
+
+func createDroid(d droids.Droid) tea.Cmd {
+	return func() tea.Msg {
+		home, cwd := homeAndCwd()
+		path, err := droids.Create(droids.Dir(d.Scope, home, cwd), d)
+		if err != nil {
+			return errMsg{err: err}
+		}
+		return droidCreatedMsg{path: path}
+	}
+}
+
+// loadSlash reads the command files; focus is the path to put the cursor on.
+func loadSlash(focus string) tea.Cmd {
+	return func() tea.Msg {
+		home, cwd := homeAndCwd()
+		list, err := slash.Load(home, cwd)
+		return slashLoadedMsg{list: list, focus: focus, err: err}
+	}
+}
+
+func createSlash(dir, name string, t slash.Template) tea.Cmd {
+	return func() tea.Msg {
+		path, err := slash.Create(dir, name, t)
+		if err != nil {
+			return errMsg{err: err}
+		}
+		return slashChangedMsg{focus: path, flash: "  ✓ Created " + path + " · enter · edit"}
+	}
+}
+
+func renameSlash(c slash.Command, name string) tea.Cmd {
+	return func() tea.Msg {
+		path, err := slash.Rename(c, name)
+		if err != nil {
+			return errMsg{err: err}
+		}
+		return slashChangedMsg{focus: path, flash: "  ✓ Renamed /" + c.Name + " to /" + name}
+	}
+}
+
+func deleteSlash(c slash.Command) tea.Cmd {
+	return func() tea.Msg {
+		if err := os.Remove(c.Path); err != nil {
+			return errMsg{err: err}
+		}
+		return slashChangedMsg{flash: "  ✓ Deleted " + c.Path}
+	}
+}
+
+func chmodSlash(c slash.Command) tea.Cmd {
+	return func() tea.Msg {
+		if err := slash.MakeExecutable(c); err != nil {
+			return errMsg{err: err}
+		}
+		return slashChangedMsg{focus: c.Path, flash: "  ✓ /" + c.Name + " is now executable"}
+	}
+}
+
+// openEditor suspends the TUI and opens path in $VISUAL or $EDITOR, vi when
+// neither is set. The variable may carry flags, e.g. "code --wait".
+func openEditor(path string) tea.Cmd {
+	args := strings.Fields(os.Getenv("VISUAL"))
+	if len(args) == 0 {
+		args = strings.Fields(os.Getenv("EDITOR"))
+	}
+	if len(args) == 0 {
+		args = []string{"vi"}
+	}
+	c := exec.Command(args[0], append(args[1:], path)...)
+	return tea.ExecProcess(c, func(err error) tea.Msg {
+		return editorClosedMsg{path: path, err: err}
+	})
+}
+
+func loadMCP() tea.Cmd {
+	return func() tea.Msg {
+		section, err := config.ReadMCP()
+		if err != nil {
+			return mcpLoadedMsg{err: err}
+		}
+		list, err := mcp.Parse(section)
+		return mcpLoadedMsg{list: list, err: err}
+	}
+}
+
+func writeMCP(list []mcp.Server) tea.Cmd {
+	return func() tea.Msg {
+		if err := config.SaveMCP(mcp.Build(list)); err != nil {
+			return errMsg{err: err}
+		}
+		return settingsSavedMsg{}
+	}
+}
+
+func testMCP(s mcp.Server) tea.Cmd {
+	return func() tea.Msg {
+		dir, _ := os.Getwd()
+		return mcpTestedMsg{report: mcp.Test(context.Background(), s, dir)}
+	}
+}
+
+func writeHooks(list []hooks.Hook) tea.Cmd {
+	return func() tea.Msg {
+		section, disabled := hooks.Build(list)
+		if err := config.SaveHooks(section, disabled); err != nil {
+			return errMsg{err: err}
+		}
+		return settingsSavedMsg{}
+	}
+}
+
+func runHook(h hooks.Hook, input []byte) tea.Cmd {
+	return func() tea.Msg {
+		dir, _ := os.Getwd()
+		return hookRanMsg{result: hooks.Run(context.Background(), h, input, dir)}
+	}
+}
+
+func cmdFetchModels(ctx context.Context, m Model) tea.Cmd {
+	seq := m.fetchSeq
 	baseURL := m.baseURL
 	apiKey := m.apiKey
 	modelsEndpoint := m.modelsEndpoint
-	providerType := m.providerType
+	format := m.modelsFormat
 	noAuth := m.noAuth
 
 	return func() tea.Msg {
-		models, _ := api.FetchModels(baseURL, apiKey, modelsEndpoint, providerType, noAuth)
+		models, err := api.FetchModels(ctx, baseURL, apiKey, modelsEndpoint, format, noAuth)
+		if len(models) == 0 {
+			return modelsLoadedMsg{seq: seq, err: err}
+		}
+		existing, _ := config.ReadCustomModels()
+		ids := make([]string, len(models))
 		displayNames := make(map[string]string, len(models))
 		for i, model := range models {
-			dn := api.GetDisplayName(model.ID)
+			ids[i] = model.ID
+			dn := model.Name
+			if dn == "" || dn == model.ID {
+				dn = api.GetDisplayName(model.ID)
+			}
 			displayNames[model.ID] = dn
-			models[i].Name = fmt.Sprintf("%s  \x1b[38;5;241m%s\x1b[0m", dn, model.ID)
+			models[i].Name = dn + "  " + theme.Muted.Render(model.ID)
 		}
-		return modelsLoadedMsg{models: models, displayNames: displayNames}
+		configured := config.FindConfigured(existing, baseURL, ids)
+		// err is a *api.PartialError here: the list is shown with a warning.
+		return modelsLoadedMsg{seq: seq, models: models, displayNames: displayNames, configured: configured, err: err}
 	}
 }
 
 func wizSaveAll(m Model) tea.Cmd {
 	providerKey := m.providerKey
 	return func() tea.Msg {
+		existing, err := config.ReadCustomModels()
+		if err != nil {
+			return errMsg{err: err}
+		}
+		configured := config.FindConfigured(existing, m.baseURL, m.selectedModels)
 		nextIdx, _ := config.GetNextModelIndex(providerKey)
-		for i, modelID := range m.selectedModels {
+		var added, skipped []string
+		for _, modelID := range m.selectedModels {
+			if configured[modelID] {
+				skipped = append(skipped, modelID)
+				continue
+			}
 			dn := m.modelDisplayNames[modelID]
 			if dn == "" {
 				dn = modelID
 			}
-			idx := nextIdx + i
+			idx := nextIdx + len(added)
 			cfg := config.ModelConfig{
 				ID:              config.GenerateModelID(providerKey, idx),
 				Index:           idx,
@@ -1078,12 +3691,65 @@ func wizSaveAll(m Model) tea.Cmd {
 			if err := config.AddModelToSettings(cfg); err != nil {
 				return errMsg{err: err}
 			}
+			added = append(added, modelID)
+		}
+		return byokSavedMsg{path: config.SettingsPath(), added: added, skipped: skipped}
+	}
+}
+
+func cmdMoveModel(id string, pos int) tea.Cmd {
+	return func() tea.Msg {
+		newID, renames, err := config.MoveModel(id, pos)
+		if err != nil {
+			return errMsg{err: err}
+		}
+		var stale []string
+		if len(renames) > 0 {
+			home, cwd := homeAndCwd()
+			list, _ := droids.UsingModels(home, cwd, slices.Collect(maps.Keys(renames)))
+			for _, d := range list {
+				stale = append(stale, fmt.Sprintf("%s uses %s, now %s", d.Name, d.Model, renames[d.Model]))
+			}
+		}
+		groups, _ := config.ReadProviderGroups()
+		return modelsReorderedMsg{groups: groups, focus: "model:" + newID, stale: stale}
+	}
+}
+
+func cmdMoveGroup(prefix string, pos int) tea.Cmd {
+	return func() tea.Msg {
+		if err := config.MoveGroup(prefix, pos); err != nil {
+			return errMsg{err: err}
+		}
+		groups, _ := config.ReadProviderGroups()
+		return modelsReorderedMsg{groups: groups, focus: "group:" + prefix}
+	}
+}
+
+func wizSaveVariant(base config.ModelConfig, spec config.VariantSpec) tea.Cmd {
+	return func() tea.Msg {
+		created, err := config.AddVariants(base, []config.VariantSpec{spec})
+		if err != nil {
+			return errMsg{err: err}
 		}
-		return byokSavedMsg{path: config.SettingsPath()}
+		groups, _ := config.ReadProviderGroups()
+		return variantsAddedMsg{groups: groups, created: created}
+	}
+}
+
+func wizMergeDuplicates() tea.Cmd {
+	return func() tea.Msg {
+		n, err := config.MergeDuplicates()
+		if err != nil {
+			return errMsg{err: err}
+		}
+		return duplicatesMergedMsg{removed: n}
 	}
 }
 
-type modelDeletedMsg struct{}
+type modelDeletedMsg struct {
+	clearedDefault bool // the model was the default and the key was removed
+}
 
 func wizPersistModel(model config.ModelConfig) tea.Cmd {
 	return func() tea.Msg {
@@ -1096,10 +3762,11 @@ func wizPersistModel(model config.ModelConfig) tea.Cmd {
 
 func wizDeleteModel(id string) tea.Cmd {
 	return func() tea.Msg {
-		if err := config.DeleteModelFromSettings(id); err != nil {
+		cleared, err := config.DeleteModelFromSettings(id)
+		if err != nil {
 			return errMsg{err: err}
 		}
-		return modelDeletedMsg{}
+		return modelDeletedMsg{clearedDefault: cleared}
 	}
 }
 
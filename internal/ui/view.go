@@ -1,17 +1,45 @@
 package ui
 
 import (
+	"slices"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
 
+	"github.com/kaan-escober/wrench/internal/keymap"
 	"github.com/kaan-escober/wrench/internal/theme"
 )
 
 func (m Model) View() string {
+	out, _ := scanZones(m.frame())
+	return out
+}
+
+// frame renders the screen with its zone marks; see mouse.go.
+func (m Model) frame() string {
 	if m.width == 0 {
 		return ""
 	}
+	footer := m.viewFooter()
+	room := m.height - lipgloss.Height(footer)
+	body := m.viewBody()
+	if m.split() {
+		body = m.splitBody(body, room-(lipgloss.Height(m.content(""))-1))
+	}
+	content := m.clip(strings.TrimSuffix(m.content(body), "\n"), room)
+
+	// Pad body so footer is pinned to the bottom of the screen.
+	if gap := room - lipgloss.Height(content); gap > 0 {
+		content += strings.Repeat("\n", gap)
+	}
+	return content + "\n" + footer
+}
+
+// viewBody renders the current screen, without the messages and footer.
+func (m Model) viewBody() string {
+	if m.showHelp {
+		return m.viewHelp()
+	}
 	var body string
 	switch m.mode {
 	case ModeMenu:
@@ -24,34 +52,183 @@ func (m Model) View() string {
 		body = m.viewBoolPick()
 	case ModeTextInput:
 		body = m.viewTextInput()
-	case ModeCommandEdit, ModeCommandAdd:
+	case ModeCommandEdit, ModeCommandAdd, ModeCommandTest, ModeCommandFilter:
 		body = m.viewCommandEdit()
+	case ModeCommandPaste:
+		body = m.viewCommandPaste()
+	case ModeCommandPreset:
+		body = m.viewCommandPreset()
+	case ModeCommandSuggest:
+		body = m.viewCommandSuggest()
+	case ModeHooks:
+		body = m.viewHooks()
+	case ModeHookForm:
+		body = m.viewHookForm()
+	case ModeHookInput:
+		body = m.viewHookInput()
+	case ModeHookPick:
+		body = m.viewHookPick()
+	case ModeHookRun:
+		body = m.viewHookRun()
+	case ModeHookConfirm:
+		body = m.viewHookConfirm()
+	case ModeSlash:
+		body = m.viewSlash()
+	case ModeSlashPick:
+		body = m.viewSlashPick()
+	case ModeSlashInput:
+		body = m.viewSlashInput()
+	case ModeSlashConfirm:
+		body = m.viewSlashConfirm()
+	case ModeMCP:
+		body = m.viewMCP()
+	case ModeMCPForm:
+		body = m.viewMCPForm()
+	case ModeMCPInput:
+		body = m.viewMCPInput()
+	case ModeMCPLines:
+		body = m.viewMCPLines()
+	case ModeMCPTest:
+		body = m.viewMCPTest()
+	case ModeMCPConfirm:
+		body = m.viewMCPConfirm()
+	case ModeDroids:
+		body = m.viewDroids()
+	case ModeDroidWizard:
+		body = m.viewDroidWizard()
+	case ModeOther:
+		body = m.viewOther()
+	case ModeOtherTree:
+		body = m.viewOtherTree()
+	case ModeOtherInput:
+		body = m.viewOtherInput()
+	case ModeOtherConfirm:
+		body = m.viewOtherConfirm()
+	case ModePalette:
+		body = m.viewPalette()
 	case ModeBYOK:
 		body = m.viewBYOK()
 	}
+	return body
+}
+
+// rowsFit returns how many of n rows fit on the screen next to fixed other
+// lines, keeping two lines for ↑ more and ↓ more when not all of them do.
+func (m Model) rowsFit(n, fixed int) int {
+	free := m.height - lipgloss.Height(m.viewFooter()) - fixed
+	if m.err != "" {
+		free--
+	}
+	if m.flash != "" {
+		free--
+	}
+	if free >= n {
+		return n
+	}
+	return max(free-2, 3)
+}
 
-	parts := []string{body}
+// menuRows is how many menu rows fit below the logo and above the total.
+func (m Model) menuRows() int {
+	return m.rowsFit(len(menuEntries), 5)
+}
+
+// catRows is how many settings of the category fit below its header.
+func (m Model) catRows() int {
+	header := lipgloss.Height(viewHeader(m.catBadge(), m.catSubtitle())) - 1
+	return m.rowsFit(len(settingsIn(m.currentCat)), header)
+}
+
+// compactWidth is the width below which screens drop their badge columns
+// and list details.
+const compactWidth = 60
+
+// compact reports whether the screen uses the compact layout: on narrow
+// terminals, or everywhere with --compact.
+func (m Model) compact() bool {
+	return m.forceCompact || m.width < compactWidth
+}
+
+// content stacks the body, the error and the flash message, each cut to the
+// screen width.
+func (m Model) content(body string) string {
+	parts := []string{m.clip(body, 0)}
 	if e := m.viewErr(); e != "" {
-		parts = append(parts, e)
+		parts = append(parts, m.clip(e, 0))
 	}
 	if f := m.viewFlash(); f != "" {
-		parts = append(parts, f)
+		parts = append(parts, m.clip(f, 0))
 	}
-	footer := m.viewFooter()
+	return lipgloss.JoinVertical(lipgloss.Left, parts...)
+}
 
-	// Pad body so footer is pinned to the bottom of the screen.
-	content := lipgloss.JoinVertical(lipgloss.Left, parts...)
-	contentH := lipgloss.Height(content)
-	footerH := lipgloss.Height(footer)
-	gap := m.height - contentH - footerH
-	if gap > 0 {
-		content += strings.Repeat("\n", gap)
+// clip cuts lines wider than the screen with an ellipsis, so the terminal
+// never wraps them, and keeps at most height lines (0 keeps them all). A
+// screen too short for everything loses its bottom lines; the header and
+// the footer stay.
+func (m Model) clip(s string, height int) string {
+	lines := strings.Split(s, "\n")
+	if height > 0 && len(lines) > height {
+		lines = lines[:height]
+	}
+	for i, line := range lines {
+		if lipgloss.Width(line) > m.width {
+			lines[i] = truncate(line, m.width)
+		}
 	}
-	return content + footer
+	return strings.Join(lines, "\n")
 }
 
 // ─── Shared helpers ───────────────────────────────────────────────────────────
 
+// lists returns every customList of the model.
+func (m *Model) lists() []*customList {
+	return []*customList{
+		&m.optionList, &m.presetList, &m.suggestList, &m.otherList, &m.hookForm, &m.hookPick,
+		&m.droidPick, &m.slashPick, &m.mcpForm, &m.providerList, &m.detailList, &m.modelList,
+	}
+}
+
+// fitLists gives every list the width of the screen or its left pane, and the list on screen the
+// rows the rest of the screen leaves free, so a long list scrolls instead of
+// pushing the footer off. It runs after every update: the free rows change
+// with the window size and with the error and flash lines.
+func (m *Model) fitLists() {
+	if m.width == 0 {
+		return
+	}
+	for _, l := range m.lists() {
+		l.width, l.compact = m.navWidth(), m.compact()
+	}
+	n := len(menuEntries)
+	rows := m.menuRows()
+	m.menuOffset = scrollWindow(m.menuCursor, max(min(m.menuOffset, n-rows), 0), rows)
+	n = len(settingsIn(m.currentCat))
+	rows = m.catRows()
+	m.catOffset = scrollWindow(m.catCursor, max(min(m.catOffset, n-rows), 0), rows)
+
+	l := m.activeList()
+	if l == nil || len(l.items) == 0 {
+		return
+	}
+
+	// Draw the screen with every item; the other lines are what the list
+	// has to leave room for.
+	offset, height := l.offset, l.height
+	l.offset, l.height = 0, len(l.items)
+	body, zones := scanZones(m.content(m.viewBody()))
+	l.offset, l.height = offset, height
+	if !slices.ContainsFunc(zones, func(z zone) bool { return z.kind == zoneList }) {
+		return // the step shows no list
+	}
+	free := m.height - lipgloss.Height(m.viewFooter()) - (lipgloss.Height(body) - len(l.items))
+	if free < len(l.items) {
+		free -= 2 // the ↑ more and ↓ more lines
+	}
+	l.height = max(free, 3)
+	l.offset = scrollWindow(l.cursor, max(min(l.offset, len(l.items)-l.height), 0), l.height)
+}
+
 func (m Model) viewErr() string {
 	if m.err == "" {
 		return ""
@@ -67,33 +244,26 @@ func (m Model) viewFlash() string {
 }
 
 func (m Model) viewFooter() string {
-	var hints string
-	switch m.mode {
-	case ModeMenu:
-		hints = "↑↓ navigate  enter · open  ctrl+c quit"
-	case ModeCategory:
-		hints = "↑↓ navigate  enter · edit  esc · back"
-	case ModeOptionPick, ModeBoolPick:
-		hints = "↑↓ navigate  enter · select  esc · back"
-	case ModeTextInput:
-		hints = "enter · confirm  esc · back"
-	case ModeCommandEdit:
-		hints = "↑↓ navigate  tab · switch  a · add  d · delete  esc · save & back"
-	case ModeCommandAdd:
-		hints = "enter · add command  esc · cancel"
-	case ModeBYOK:
-		hints = m.byokFooterHints()
+	hints := m.keys.Footer(m.screen())
+	if m.showHelp {
+		hints = "any key · close"
 	}
 
 	right := theme.Muted.Render(m.viewModeLabel())
 	rightW := lipgloss.Width(right)
-	// Truncate hints so the footer never exceeds terminal width.
+	// Drop the hints that do not fit so the footer never exceeds terminal
+	// width; a single hint too wide for the line is cut with an ellipsis.
 	maxHints := m.width - rightW - 3 // 3 = minimum gap + space
 	if maxHints < 0 {
 		maxHints = 0
 	}
-	if len(hints) > maxHints {
-		hints = hints[:maxHints]
+	for lipgloss.Width(hints) > maxHints {
+		i := strings.LastIndex(hints, "  ")
+		if i < 0 {
+			hints = truncate(hints, max(maxHints, 1))
+			break
+		}
+		hints = hints[:i]
 	}
 	left := theme.Muted.Render(hints)
 	gap := m.width - lipgloss.Width(left) - rightW
@@ -109,12 +279,25 @@ func (m Model) viewModeLabel() string {
 	switch m.mode {
 	case ModeMenu:
 		return "DROID CONFIG"
+	case ModePalette:
+		return "SEARCH"
 	case ModeBYOK:
 		return "BYOK"
-	case ModeCommandEdit, ModeCommandAdd:
+	case ModeCommandEdit, ModeCommandAdd, ModeCommandTest, ModeCommandPreset, ModeCommandSuggest,
+		ModeCommandPaste, ModeCommandFilter:
 		return "CMD"
+	case ModeHooks, ModeHookForm, ModeHookInput, ModeHookPick, ModeHookRun, ModeHookConfirm:
+		return "HOOK"
+	case ModeDroids, ModeDroidWizard:
+		return "DRD"
+	case ModeSlash, ModeSlashPick, ModeSlashInput, ModeSlashConfirm:
+		return "SLSH"
+	case ModeMCP, ModeMCPForm, ModeMCPInput, ModeMCPLines, ModeMCPTest, ModeMCPConfirm:
+		return "MCP"
+	case ModeOther, ModeOtherTree, ModeOtherInput, ModeOtherConfirm:
+		return "ADV"
 	default:
-		defs := categorySettings[m.currentCat]
+		defs := settingsIn(m.currentCat)
 		if m.catCursor >= 0 && m.catCursor < len(defs) {
 			return defs[m.catCursor].Key
 		}
@@ -127,24 +310,10 @@ func (m Model) viewModeLabel() string {
 	return ""
 }
 
-func (m Model) byokFooterHints() string {
-	switch m.byokStep {
-	case WizProvider, WizGroupDetail:
-		return "↑↓ navigate  enter · select  esc · back"
-	case WizModelEdit:
-		return "↑↓ navigate  enter · edit  esc · back"
-	case WizModelField:
-		if m.editFieldKey == "provider" || m.editFieldKey == "supportsImages" || m.editFieldKey == "delete" {
-			return "↑↓ navigate  enter · select  esc · cancel"
-		}
-		return "enter · save  esc · cancel"
-	case WizModels:
-		return "space · toggle  ↑↓ navigate  enter · confirm  esc · back"
-	case WizSettingsImages, WizConfirm, WizDone:
-		return "↑↓ navigate  enter · select  esc · back"
-	default:
-		return "enter · confirm  esc · back"
-	}
+// confirmButtons renders the delete and keep choices of a confirm screen.
+func (m Model) confirmButtons() string {
+	return "  " + zoneMark(zoneYes, 0) + theme.Accent.Render(m.keys.First(keymap.Yes)) + theme.Primary.Render(" · delete") + "   " +
+		zoneMark(zoneNo, 0) + theme.Accent.Render(m.keys.First(keymap.No)) + theme.Primary.Render(" · keep")
 }
 
 // viewHeader renders the orange badge + optional subtitle line for a screen.
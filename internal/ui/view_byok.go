@@ -1,10 +1,13 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
+	"github.com/kaan-escober/wrench/internal/api"
 	"github.com/kaan-escober/wrench/internal/config"
+	"github.com/kaan-escober/wrench/internal/keymap"
 	"github.com/kaan-escober/wrench/internal/theme"
 )
 
@@ -53,16 +56,19 @@ func (m Model) viewBYOK() string {
 
 	case WizModels:
 		if len(m.availableModels) == 0 {
-			return viewHeader("MODEL ID", "Could not auto-fetch — enter a model ID manually") +
-				theme.Muted.Render("  e.g. gpt-4o, claude-opus-4-5, qwen3:4b") + "\n\n" +
-				theme.PromptStr() + m.textInput.View()
+			return m.viewManualModel()
 		}
 		count := len(m.modelList.selectedValues())
 		sel := ""
 		if count > 0 {
 			sel = "  " + theme.BadgeSuccess.Render(fmt.Sprintf(" %d selected ", count))
 		}
-		return viewHeader("SELECT MODELS", "space · toggle   enter · confirm"+sel) +
+		warn := ""
+		if m.fetchErr != nil {
+			warn = renderFetchErr(m.fetchErr) + "\n" +
+				theme.Muted.Render("  "+m.keys.First(keymap.Retry)+" · fetch again") + "\n\n"
+		}
+		return viewHeader("SELECT MODELS", "space · toggle   enter · confirm"+sel) + warn +
 			m.modelList.render(true)
 
 	case WizSettingsTokens:
@@ -83,17 +89,16 @@ func (m Model) viewBYOK() string {
 			theme.Muted.Render(m.spinner.View()+" Writing configuration...")
 
 	case WizDone:
-		lines := make([]string, len(m.selectedModels))
-		for i, id := range m.selectedModels {
-			dn := m.modelDisplayNames[id]
-			if dn == "" {
-				dn = id
-			}
-			lines[i] = theme.Success.Render("  ● ") + theme.Primary.Render(dn)
+		var lines []string
+		for _, id := range m.addedModels {
+			lines = append(lines, theme.Success.Render("  ● ")+theme.Primary.Render(m.modelLabel(id)))
+		}
+		for _, id := range m.skippedModels {
+			lines = append(lines, theme.Muted.Render("  ○ "+m.modelLabel(id)+"  already configured — skipped"))
 		}
 		return viewHeader("DONE", "") +
 			theme.BadgeSuccess.Render(" SAVED ") + "\n\n" +
-			theme.Primary.Render(fmt.Sprintf("%d model(s) added to Factory", len(m.selectedModels))) + "\n" +
+			theme.Primary.Render(fmt.Sprintf("%d model(s) added to Factory", len(m.addedModels))) + "\n" +
 			strings.Join(lines, "\n") + "\n" +
 			theme.Muted.Render("  → "+config.SettingsPath()) + "\n\n" +
 			m.detailList.render(true)
@@ -102,6 +107,58 @@ func (m Model) viewBYOK() string {
 	return ""
 }
 
+// viewManualModel is the manual model-ID fallback shown when no models were fetched.
+func (m Model) viewManualModel() string {
+	var subtitle, detail string
+	switch {
+	case m.modelsEndpoint == "":
+		subtitle = "This provider has no model list endpoint — enter a model ID manually"
+	case m.fetchErr != nil:
+		subtitle = "Could not fetch models — enter a model ID manually or retry"
+		detail = renderFetchErr(m.fetchErr) + "\n\n"
+	default:
+		subtitle = "The provider returned no models — enter a model ID manually"
+	}
+	return viewHeader("MODEL ID", subtitle) + detail +
+		theme.Muted.Render("  e.g. gpt-4o, claude-opus-4-5, qwen3:4b") + "\n\n" +
+		theme.PromptStr() + m.textInput.View()
+}
+
+// renderFetchErr shows a categorised fetch failure with its response snippet.
+func renderFetchErr(err error) string {
+	var pe *api.PartialError
+	if errors.As(err, &pe) {
+		out := theme.BadgeError.Render("PARTIAL") + "  " +
+			theme.Error.Render(fmt.Sprintf("Only %d page(s) of the models list were read", pe.Pages))
+		if pe.Err != nil {
+			out += "\n" + renderFetchErr(pe.Err)
+		} else {
+			out += "\n" + theme.Muted.Render("  The provider lists more pages than wrench follows")
+		}
+		return out
+	}
+	var fe *api.FetchError
+	if !errors.As(err, &fe) {
+		return theme.BadgeError.Render("ERROR") + "  " + theme.Error.Render(err.Error())
+	}
+	status := ""
+	if fe.Status != 0 {
+		status = fmt.Sprintf("HTTP %d  ", fe.Status)
+	}
+	out := theme.BadgeError.Render(strings.ToUpper(fe.Kind.String())) + "  " +
+		theme.Error.Render(status+fe.URL)
+	if fe.Err != nil {
+		out += "\n" + theme.Muted.Render("  "+fe.Err.Error())
+	}
+	if h := fe.Hint(); h != "" {
+		out += "\n" + theme.Primary.Render("  "+h)
+	}
+	if fe.Snippet != "" {
+		out += "\n" + theme.Muted.Render("  ↳ "+fe.Snippet)
+	}
+	return out
+}
+
 func (m Model) viewModelField() string {
 	switch m.editFieldKey {
 	case "displayName":
@@ -120,6 +177,18 @@ func (m Model) viewModelField() string {
 		return viewHeader("IMAGE SUPPORT", "") + m.detailList.render(true)
 	case "delete":
 		return viewHeader("DELETE MODEL", "Are you sure?") + m.detailList.render(true)
+	case "variantSuffix":
+		return viewHeader("VARIANT SUFFIX", "New variant of "+m.editingModel.DisplayName) +
+			theme.PromptStr() + m.textInput.View()
+	case "variantName":
+		return viewHeader("VARIANT NAME", "Shown in Droid's model selector") +
+			theme.PromptStr() + m.textInput.View()
+	case "variantTokens":
+		return viewHeader("VARIANT MAX TOKENS", "Leave as is to keep the original") +
+			theme.PromptStr() + m.textInput.View()
+	case "variantArgs":
+		return viewHeader("VARIANT EXTRA ARGS", "JSON merged over the original's extraArgs · empty keeps them") +
+			theme.PromptStr() + m.textInput.View()
 	}
 	return ""
 }
@@ -130,14 +199,13 @@ func (m Model) renderBYOKSummary() string {
 		return l + theme.Primary.Render(value)
 	}
 
-	models := m.selectedModels
-	modelNames := make([]string, len(models))
-	for i, id := range models {
-		dn := m.modelDisplayNames[id]
-		if dn == "" {
-			dn = id
+	var modelNames, dupNames []string
+	for _, id := range m.selectedModels {
+		if m.configuredModels[id] {
+			dupNames = append(dupNames, m.modelLabel(id))
+			continue
 		}
-		modelNames[i] = dn
+		modelNames = append(modelNames, m.modelLabel(id))
 	}
 
 	images := "No"
@@ -145,12 +213,24 @@ func (m Model) renderBYOKSummary() string {
 		images = "Yes"
 	}
 
-	return strings.Join([]string{
+	rows := []string{
 		row("Provider", m.displayTitle),
 		row("Base URL", m.baseURL),
 		row("Type", m.providerType),
 		row("Models", strings.Join(modelNames, ", ")),
 		row("Max tokens", fmt.Sprintf("%d", m.maxOutputTokens)),
 		row("Images", images),
-	}, "\n")
+	}
+	if len(dupNames) > 0 {
+		rows = append(rows, theme.Error.Render("  △  Already configured, will be skipped: "+strings.Join(dupNames, ", ")))
+	}
+	return strings.Join(rows, "\n")
+}
+
+// modelLabel returns the display name for a fetched model ID.
+func (m Model) modelLabel(id string) string {
+	if dn := m.modelDisplayNames[id]; dn != "" {
+		return dn
+	}
+	return id
 }
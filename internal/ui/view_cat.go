@@ -5,20 +5,27 @@ import (
 
 	"github.com/charmbracelet/lipgloss"
 
+	"github.com/kaan-escober/wrench/internal/config"
 	"github.com/kaan-escober/wrench/internal/theme"
 )
 
 // ─── Category list ─────────────────────────────────────────────────────────────
 
 func (m Model) viewCategory() string {
-	defs := categorySettings[m.currentCat]
+	defs := settingsIn(m.currentCat)
 	badge := m.catBadge()
 
 	var sb strings.Builder
 	sb.WriteString(viewHeader(badge, m.catSubtitle()))
 
 	nameStyle := lipgloss.NewStyle().Width(24)
-	for i, def := range defs {
+	start := m.catOffset
+	end := min(start+m.catRows(), len(defs))
+	if start > 0 {
+		sb.WriteString(theme.Muted.Render("  ↑ more") + "\n")
+	}
+	for i := start; i < end; i++ {
+		def := defs[i]
 		isCursor := i == m.catCursor
 
 		current := m.settingValueDisplay(def)
@@ -36,12 +43,25 @@ func (m Model) viewCategory() string {
 			cursor = theme.Accent.Render("> ")
 		}
 
-		valStr := theme.Teal.Render(current)
-		if desc != "" && isCursor {
-			valStr = theme.Teal.Render(current) + "  " + theme.Muted.Render(desc)
+		// Explicit values are teal, inherited defaults muted; values that
+		// differ from Droid's default carry a marker.
+		var valStr string
+		switch {
+		case !m.isExplicit(def):
+			valStr = theme.Muted.Render(current)
+		case m.isChanged(def):
+			valStr = theme.Teal.Render(current) + theme.Accent.Render(" ●")
+		default:
+			valStr = theme.Teal.Render(current) + theme.Muted.Render(" (set)")
+		}
+		if desc != "" && isCursor && !m.split() { // the detail pane has it
+			valStr += "  " + theme.Muted.Render(desc)
 		}
 
-		sb.WriteString(cursor + nameStr + "  " + valStr + "\n")
+		sb.WriteString(zoneMark(zoneSetting, i) + cursor + nameStr + "  " + valStr + "\n")
+	}
+	if end < len(defs) {
+		sb.WriteString(theme.Muted.Render("  ↓ more") + "\n")
 	}
 	return sb.String()
 }
@@ -56,58 +76,74 @@ func (m Model) catBadge() string {
 }
 
 func (m Model) catSubtitle() string {
-	switch m.currentCat {
-	case CatModel:
-		return "Choose the default AI model and reasoning behaviour"
-	case CatAutonomy:
-		return "How proactively Droid executes commands"
-	case CatDisplay:
-		return "Control how Droid presents information in the TUI"
-	case CatSound:
-		return "Audio feedback for Droid events"
-	case CatSecurity:
-		return "Shield, commit attribution, and process controls"
-	case CatBehavior:
-		return "Session sync, IDE, spec saving, and experimental features"
+	sc, _ := config.CurrentSchema()
+	if c, ok := sc.Category(string(m.currentCat)); ok {
+		return c.Description
 	}
 	return ""
 }
 
-func (m Model) settingValueDisplay(def SettingDef) string {
-	switch def.Kind {
-	case KindEnum:
-		v := m.settings.GetField(def.Key)
-		if v == "" {
-			return def.Default + " (default)"
-		}
-		return v
-	case KindBool:
-		b := m.settings.GetBool(def.Key)
-		if b == nil {
-			return def.Default + " (default)"
+func (m Model) settingValueDisplay(def config.SettingSpec) string {
+	if !m.isExplicit(def) {
+		if def.Default == nil {
+			return "(unset)"
 		}
-		if *b {
-			return "enabled"
-		}
-		return "disabled"
-	case KindText:
-		v := m.settings.GetField(def.Key)
-		if v == "" {
-			return def.Default + " (default)"
+		return def.DefaultText() + " (default)"
+	}
+	v := m.settings.GetField(def.Key)
+	if def.Key == "model" {
+		v, _ = m.modelRefLabel(v)
+	}
+	return v
+}
+
+// modelRefLabel describes the value of the "model" setting: built-in names as
+// they are, custom model ids by display name. ok is false when the value
+// matches neither, e.g. a custom model that was deleted or re-keyed.
+func (m Model) modelRefLabel(ref string) (label string, ok bool) {
+	if cm, found := config.FindCustomModel(m.customModels, ref); found {
+		return customModelItem(cm).label + " (" + ref + ")", true
+	}
+	if def, ok := lookupSetting("model"); ok {
+		if _, found := def.Option(ref); found {
+			return ref, true
 		}
-		return v
 	}
-	return ""
+	return ref + " ⚠ no such model", false
+}
+
+// isExplicit reports whether settings.json sets the key at all.
+func (m Model) isExplicit(def config.SettingSpec) bool {
+	_, ok := m.settings.Value(def.Key)
+	return ok
 }
 
-func (m Model) settingDesc(def SettingDef) string {
-	if len(def.Options) > 0 && def.Options[0].Desc != "" {
-		// Only show desc for bool kinds (the single-option desc field)
-		if def.Kind == KindBool {
-			return def.Options[0].Desc
+// isChanged reports whether a setting is explicitly set to something other
+// than Droid's default.
+func (m Model) isChanged(def config.SettingSpec) bool {
+	if !m.isExplicit(def) {
+		return false
+	}
+	return m.settings.GetField(def.Key) != def.DefaultText()
+}
+
+// changedCount counts the settings of a category that differ from defaults.
+func (m Model) changedCount(cat Category) int {
+	n := 0
+	for _, def := range settingsIn(cat) {
+		if m.isChanged(def) {
+			n++
 		}
 	}
-	return ""
+	return n
+}
+
+func (m Model) settingDesc(def config.SettingSpec) string {
+	// Enum pickers describe each option instead.
+	if def.Type == config.TypeEnum {
+		return ""
+	}
+	return def.Description
 }
 
 // ─── Option picker ─────────────────────────────────────────────────────────────
@@ -116,16 +152,20 @@ func (m Model) viewOptionPick() string {
 	def := m.currentSettingDef()
 	current := m.settings.GetField(def.Key)
 	if current == "" {
-		current = def.Default
+		current = def.DefaultText()
 	}
 
 	header := viewHeader(def.Label, "Currently: "+theme.Teal.Render(current))
 	list := m.optionList.render(true)
 
-	// For custom path sound: show a note
 	note := ""
-	if def.Key == "completionSound" || def.Key == "awaitingInputSound" {
-		note = "\n" + theme.Muted.Render("  Select \"Custom file path...\" to enter your own audio file")
+	if def.Custom != nil {
+		note = "\n" + theme.Muted.Render("  Select \""+def.Custom.Label+"\" to enter your own value")
+	}
+	if def.Key == "model" && m.isExplicit(def) {
+		if _, ok := m.modelRefLabel(current); !ok {
+			note = "\n" + theme.Error.Render("  △  "+current+" is neither a built-in nor a configured custom model · pick one to fix it")
+		}
 	}
 
 	return header + list + note
@@ -135,21 +175,12 @@ func (m Model) viewOptionPick() string {
 
 func (m Model) viewBoolPick() string {
 	def := m.currentSettingDef()
-	b := m.settings.GetBool(def.Key)
-
-	current := def.Default + " (default)"
-	if b != nil {
-		if *b {
-			current = "enabled"
-		} else {
-			current = "disabled"
-		}
+	current := "inherit · Droid default " + def.DefaultText()
+	if t := m.settings.GetTri(def.Key); t != config.Inherit {
+		current = t.String() + " (set explicitly)"
 	}
 
-	desc := ""
-	if len(def.Options) > 0 {
-		desc = def.Options[0].Desc
-	}
+	desc := def.Description
 
 	header := viewHeader(def.Label, "Currently: "+theme.Teal.Render(current))
 
@@ -168,12 +199,11 @@ func (m Model) viewTextInput() string {
 
 	var subtitle string
 	if m.customInput {
-		// Sound custom path
-		subtitle = "Enter a file path to your audio file (.wav, .mp3, .ogg)"
+		subtitle = def.Custom.Label + "  " + theme.Muted.Render(def.Custom.Desc)
 	} else {
 		current := m.settings.GetField(def.Key)
 		if current == "" {
-			current = def.Default
+			current = def.DefaultText()
 		}
 		subtitle = "Currently: " + theme.Teal.Render(current)
 	}
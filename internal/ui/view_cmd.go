@@ -6,6 +6,8 @@ import (
 
 	"github.com/charmbracelet/lipgloss"
 
+	"github.com/kaan-escober/wrench/internal/config"
+	"github.com/kaan-escober/wrench/internal/policy"
 	"github.com/kaan-escober/wrench/internal/theme"
 )
 
@@ -18,11 +20,24 @@ func (m Model) viewCommandEdit() string {
 		colW = 20
 	}
 
-	allowHeader := m.colHeader("ALLOWLIST", m.cmdFocusCol == 0)
-	denyHeader := m.colHeader("DENYLIST", m.cmdFocusCol == 1)
+	allowHeader := zoneMark(zoneAllow, -1) + m.colHeader("ALLOWLIST "+listCount(m.allowCmds), m.cmdFocusCol == 0)
+	denyHeader := zoneMark(zoneDeny, -1) + m.colHeader("DENYLIST "+listCount(m.denyCmds), m.cmdFocusCol == 1)
 
-	allowBody := m.renderCmdList(m.allowCmds, m.cmdFocusCol == 0, colW)
-	denyBody := m.renderCmdList(m.denyCmds, m.cmdFocusCol == 1, colW)
+	// While testing, mark the entries that decided the verdict.
+	var res policy.Result
+	matched := map[string]bool{}
+	if m.mode == ModeCommandTest {
+		res = policy.Check(m.testInput.Value(), m.allowCmds, m.denyCmds)
+		for _, sr := range res.Segments {
+			if sr.Entry != "" {
+				matched[sr.Entry] = true
+			}
+		}
+	}
+
+	report := policy.Lint(m.allowCmds, m.denyCmds)
+	allowBody := m.renderCmdList(policy.AllowColumn, colW, matched, report)
+	denyBody := m.renderCmdList(policy.DenyColumn, colW, matched, report)
 
 	// Pad columns to same height
 	allowLines := strings.Split(allowBody, "\n")
@@ -40,6 +55,13 @@ func (m Model) viewCommandEdit() string {
 
 	// Render side-by-side
 	var sb strings.Builder
+	switch {
+	case m.mode == ModeCommandFilter:
+		sb.WriteString("  " + m.cmdFilter.View() + "\n\n")
+	case m.cmdFilter.Value() != "":
+		sb.WriteString(theme.Muted.Render("  filter: ") + theme.Teal.Render(m.cmdFilter.Value()) +
+			theme.Muted.Render("  (/ to change, esc to clear)") + "\n\n")
+	}
 	sb.WriteString(lipgloss.JoinHorizontal(lipgloss.Top,
 		allowHeader,
 		strings.Repeat(" ", colW+2-lipgloss.Width(allowHeader)+2),
@@ -59,50 +81,326 @@ func (m Model) viewCommandEdit() string {
 		) + "\n")
 	}
 
-	// Adding mode
+	// Adding / editing mode
 	if m.mode == ModeCommandAdd {
 		col := "ALLOWLIST"
 		if m.cmdFocusCol == 1 {
 			col = "DENYLIST"
 		}
-		sb.WriteString("\n" + theme.Muted.Render(fmt.Sprintf("  Adding to %s:", col)) + "\n")
+		label := fmt.Sprintf("  Adding to %s:", col)
+		if m.cmdEditIdx >= 0 {
+			label = fmt.Sprintf("  Editing %s entry:", col)
+		}
+		sb.WriteString("\n" + theme.Muted.Render(label) + "\n")
 		sb.WriteString("  " + theme.PromptStr() + m.cmdInput.View())
 	}
 
+	if m.mode == ModeCommandEdit {
+		sb.WriteString(m.viewLintDetail(report))
+	}
+
+	if m.mode == ModeCommandTest {
+		sb.WriteString("\n" + theme.Muted.Render("  Test a command against these lists:") + "\n")
+		sb.WriteString("  " + m.testInput.View() + "\n")
+		sb.WriteString(m.viewPolicyResult(res))
+	}
+
 	return header + sb.String()
 }
 
+// viewPolicyResult renders the tester verdict, one line per simple command
+// and any warnings about chains.
+func (m Model) viewPolicyResult(res policy.Result) string {
+	if strings.TrimSpace(res.Command) == "" {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("\n  " + verdictBadge(res.Verdict))
+	switch {
+	case res.Entry != "":
+		sb.WriteString(theme.Muted.Render("  by ") + theme.Teal.Render(res.Entry))
+	case res.Verdict == policy.Ask:
+		sb.WriteString(theme.Muted.Render("  no entry matches — the autonomy level decides"))
+	}
+	sb.WriteString("\n")
+
+	if len(res.Segments) > 1 {
+		sb.WriteString("\n")
+		for _, sr := range res.Segments {
+			var mark string
+			switch sr.Verdict {
+			case policy.Allow:
+				mark = theme.Success.Render("✓")
+			case policy.Deny:
+				mark = theme.Error.Render("✗")
+			default:
+				mark = theme.Accent.Render("?")
+			}
+			line := "  " + mark + " "
+			if sr.Op != "" {
+				line += theme.Muted.Render(strings.ReplaceAll(sr.Op, "\n", "⏎") + " ")
+			}
+			if sr.Nested {
+				line += theme.Muted.Render("↳ ")
+			}
+			line += theme.Primary.Render(sr.Text)
+			if sr.Entry != "" {
+				line += theme.Muted.Render("  ← " + sr.Entry)
+			}
+			sb.WriteString(line + "\n")
+		}
+	}
+
+	for _, w := range res.Warnings {
+		style := theme.Accent
+		if res.Hidden {
+			style = theme.Error
+		}
+		sb.WriteString(style.Render("  △  "+w) + "\n")
+	}
+	return sb.String()
+}
+
+func verdictBadge(v policy.Verdict) string {
+	label := " " + strings.ToUpper(v.String()) + " "
+	switch v {
+	case policy.Allow:
+		return theme.BadgeSuccess.Render(label)
+	case policy.Deny:
+		return theme.BadgeError.Render(label)
+	}
+	return theme.Badge.Render(label)
+}
+
+// listCount labels a column's size, distinguishing an unset list from an
+// empty one.
+func listCount(list []string) string {
+	if list == nil {
+		return "unset"
+	}
+	return fmt.Sprint(len(list))
+}
+
 func (m Model) colHeader(label string, active bool) string {
 	if active {
 		return theme.Badge.Render(" "+label+" ") + "\n"
 	}
-	return lipgloss.NewStyle().
-		Foreground(theme.ColorMuted).
-		Padding(0, 1).
-		Render(label) + "\n"
+	return theme.Muted.Padding(0, 1).Render(label) + "\n"
+}
+
+// viewLintDetail explains the lint findings and risk score of the entry under
+// the cursor, and counts the findings across both lists.
+func (m Model) viewLintDetail(report policy.Report) string {
+	var sb strings.Builder
+	if i := m.cmdIndex(); i >= 0 {
+		entry := m.activeCommandList()[i]
+		col := policy.Column(m.cmdFocusCol)
+		var lines []string
+		for _, f := range report.For(col, entry) {
+			if f.Kind == policy.KindRisky {
+				continue // covered by the risk line below
+			}
+			lines = append(lines, lintBadge(f)+" "+theme.Muted.Render(f.Message))
+		}
+		if rk, ok := report.RiskOf(entry); ok && col == policy.AllowColumn {
+			style := theme.Muted
+			if rk.Score >= 7 {
+				style = theme.Error
+			} else if rk.Score >= 4 {
+				style = theme.Accent
+			}
+			line := style.Render(fmt.Sprintf("risk %d/10", rk.Score))
+			if len(rk.Reasons) > 0 {
+				line += theme.Muted.Render("  " + strings.Join(rk.Reasons, "; "))
+			}
+			lines = append(lines, line)
+		}
+		if len(lines) > 0 {
+			sb.WriteString("\n")
+			for _, l := range lines {
+				sb.WriteString("  " + l + "\n")
+			}
+		}
+	}
+	if n := len(report.Findings); n > 0 {
+		sb.WriteString("\n" + theme.Accent.Render(fmt.Sprintf("  △  %d policy warning(s)", n)) +
+			theme.Muted.Render(" — wrench policy lint for the full report") + "\n")
+	}
+	return sb.String()
+}
+
+func lintBadge(f policy.Finding) string {
+	if f.IsError() {
+		return theme.BadgeError.Render(f.Kind.Label())
+	}
+	return theme.Badge.Render(f.Kind.Label())
 }
 
-func (m Model) renderCmdList(cmds []string, focused bool, colW int) string {
-	if len(cmds) == 0 {
-		empty := theme.Muted.Render("  (none)")
-		return empty
+// renderCmdList draws the visible window of one column: filtered entries,
+// multi-select marks, lint badges and, while testing, the matched entries.
+func (m Model) renderCmdList(col policy.Column, colW int, matched map[string]bool, report policy.Report) string {
+	list := m.allowCmds
+	if col == policy.DenyColumn {
+		list = m.denyCmds
+	}
+	vis := m.visibleCmds(int(col))
+	if len(vis) == 0 {
+		switch {
+		case len(list) > 0:
+			return theme.Muted.Render("  (no match)")
+		case list == nil:
+			return theme.Muted.Render("  (unset — key not written)")
+		}
+		return theme.Muted.Render("  (empty — written as [])")
 	}
+
+	focused := m.cmdFocusCol == int(col)
+	zone := zoneAllow
+	if col == policy.DenyColumn {
+		zone = zoneDeny
+	}
+	start, end := m.cmdWindow(int(col))
 	var sb strings.Builder
-	for i, cmd := range cmds {
-		isCursor := focused && i == m.cmdCursor
+	if start > 0 {
+		sb.WriteString(theme.Muted.Render(fmt.Sprintf("  ↑ %d more", start)) + "\n")
+	}
+	for row := start; row < end; row++ {
+		i := vis[row]
+		cmd := list[i]
+		isCursor := focused && row == m.cmdCursor
+
+		var badges string
+		seen := map[policy.FindingKind]bool{}
+		for _, f := range report.For(col, cmd) {
+			if !seen[f.Kind] {
+				seen[f.Kind] = true
+				badges += " " + lintBadge(f)
+			}
+		}
+
+		mark := ""
+		if focused && len(m.cmdMarked) > 0 {
+			mark = theme.Muted.Render("○ ")
+			if m.cmdMarked[i] {
+				mark = theme.Accent.Render("● ")
+			}
+		}
 
 		display := cmd
-		if len(display) > colW-4 {
-			display = display[:colW-7] + "..."
+		room := colW - 4 - lipgloss.Width(mark) - lipgloss.Width(badges)
+		if len(display) > room && room > 3 {
+			display = display[:room-3] + "..."
 		}
 
 		var line string
 		if isCursor {
-			line = theme.Accent.Render("> ") + theme.Primary.Render(display)
+			line = theme.Accent.Render("> ") + mark + theme.Primary.Render(display)
 		} else {
-			line = "  " + theme.Primary.Render(display)
+			line = "  " + mark + theme.Primary.Render(display)
+		}
+		line += badges
+		if matched[cmd] {
+			line += theme.Accent.Render(" ◂")
 		}
-		sb.WriteString(line + "\n")
+		sb.WriteString(zoneMark(zone, row) + line + "\n")
+	}
+	if end < len(vis) {
+		sb.WriteString(theme.Muted.Render(fmt.Sprintf("  ↓ %d more", len(vis)-end)) + "\n")
 	}
 	return sb.String()
 }
+
+// viewCommandPaste shows the multi-line entry box.
+func (m Model) viewCommandPaste() string {
+	col := "allowlist"
+	if m.cmdFocusCol == 1 {
+		col = "denylist"
+	}
+	header := viewHeader("CMD", "Paste or type entries for the "+col+", one per line")
+	note := theme.Muted.Render("  Blank lines, # comments and entries already in the list are skipped.")
+	return header + m.cmdPaste.View() + "\n\n" + note + "\n"
+}
+
+// ─── Presets ──────────────────────────────────────────────────────────────────
+
+func (m Model) viewCommandPreset() string {
+	if m.presetPlan != nil {
+		return m.viewPresetPlan()
+	}
+	header := viewHeader("CMD", "Merge a preset into the allow and deny lists")
+	if m.presets == nil {
+		return header + "  " + m.spinner.View() + theme.Muted.Render(" Loading presets…")
+	}
+	var sb strings.Builder
+	sb.WriteString(m.presetList.render(true) + "\n")
+	if len(m.presets) > 0 {
+		p := m.presets[m.presetList.cursor]
+		sb.WriteString("\n  " + theme.Muted.Render(p.Description) + "\n")
+	}
+	sb.WriteString("\n" + theme.Muted.Render("  User presets: "+policy.PresetDir(config.WrenchDir())+"/*.json") + "\n")
+	return header + sb.String()
+}
+
+func (m Model) viewPresetPlan() string {
+	p := m.presets[m.presetList.cursor]
+	plan := m.presetPlan
+	header := viewHeader("CMD", "Preview: "+p.Name)
+
+	var lines []string
+	section := func(title string, entries []policy.MergeEntry, mark string) {
+		if len(entries) == 0 {
+			return
+		}
+		lines = append(lines, theme.Bold.Render(fmt.Sprintf("  %s (%d)", title, len(entries))))
+		for _, e := range entries {
+			line := "  " + mark + " " + theme.Muted.Render(fmt.Sprintf("%-6s", e.Column)) + " " + theme.Primary.Render(e.Entry)
+			if e.Reason != "" {
+				line += theme.Muted.Render("  " + e.Reason)
+			}
+			lines = append(lines, line)
+		}
+		lines = append(lines, "")
+	}
+	section("Added", plan.Added, theme.Success.Render("+"))
+	section("Overlapping — added, deny wins where they meet", plan.Overlaps, theme.Accent.Render("~"))
+	section("Conflicting — skipped", plan.Conflicts, theme.Error.Render("!"))
+	section("Already present", plan.Present, theme.Muted.Render("="))
+	if len(plan.Added)+len(plan.Overlaps) == 0 {
+		lines = append(lines, theme.Muted.Render("  Nothing to add — the lists already cover this preset."))
+	}
+
+	// Scroll the preview when it is taller than the screen.
+	h := listHeight(m.height)
+	start := min(m.presetScroll, max(len(lines)-h, 0))
+	end := min(start+h, len(lines))
+	var sb strings.Builder
+	if start > 0 {
+		sb.WriteString(theme.Muted.Render("  ↑ more") + "\n")
+	}
+	sb.WriteString(strings.Join(lines[start:end], "\n") + "\n")
+	if end < len(lines) {
+		sb.WriteString(theme.Muted.Render("  ↓ more") + "\n")
+	}
+	return header + sb.String()
+}
+
+// ─── History suggestions ──────────────────────────────────────────────────────
+
+func (m Model) viewCommandSuggest() string {
+	header := viewHeader("CMD", "Allowlist suggestions from your shell history — read locally, never sent anywhere")
+	if m.historyFiles == nil {
+		return header + "  " + m.spinner.View() + theme.Muted.Render(" Reading shell history…")
+	}
+	if len(m.historyFiles) == 0 {
+		return header + theme.Muted.Render("  No bash, zsh or fish history file found.") + "\n"
+	}
+	var sb strings.Builder
+	if len(m.suggestList.items) == 0 {
+		sb.WriteString(theme.Muted.Render("  Nothing to suggest — your allowlist already covers your history.") + "\n")
+	} else {
+		sb.WriteString(m.suggestList.render(true) + "\n")
+	}
+	sb.WriteString("\n" + theme.Muted.Render("  From "+strings.Join(m.historyFiles, ", ")) + "\n")
+	sb.WriteString(theme.Muted.Render("  Denylisted and dangerous commands are hidden.") + "\n")
+	return header + sb.String()
+}
@@ -0,0 +1,341 @@
+package ui
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/kaan-escober/wrench/docs"
+	"github.com/kaan-escober/wrench/internal/config"
+	"github.com/kaan-escober/wrench/internal/theme"
+)
+
+// ─── Detail pane ──────────────────────────────────────────────────────────────
+//
+// On wide terminals the settings screens draw in two panes: the usual screen
+// on the left, and on the right everything known about the highlighted item,
+// including its part of docs/settings.md, which is embedded in the binary.
+
+// splitWidth is the width from which the settings screens show the detail
+// pane.
+const splitWidth = 110
+
+// split reports whether the screen is drawn with the detail pane.
+func (m Model) split() bool {
+	if m.width < splitWidth || m.forceCompact || m.showHelp {
+		return false
+	}
+	switch m.mode {
+	case ModeMenu, ModeCategory, ModeOptionPick, ModeBoolPick, ModeTextInput:
+		return true
+	}
+	return false
+}
+
+// paneWidth is the width of the detail pane.
+func (m Model) paneWidth() int {
+	return min(max(m.width*2/5, 40), 72)
+}
+
+// navWidth is the width left for the screen itself: all of it, or what the
+// detail pane and its rule leave.
+func (m Model) navWidth() int {
+	if !m.split() {
+		return m.width
+	}
+	return m.width - m.paneWidth() - 3
+}
+
+// splitBody puts the detail pane right of the screen body. The pane is cut
+// to height lines, the last one saying so; a longer body is cut by the
+// frame.
+func (m Model) splitBody(body string, height int) string {
+	navW, paneW := m.navWidth(), m.paneWidth()
+	left := strings.Split(strings.TrimSuffix(body, "\n"), "\n")
+	right := strings.Split(strings.TrimSuffix(m.viewDetail(paneW), "\n"), "\n")
+	if len(right) > height && height > 0 {
+		right = right[:height]
+		right[height-1] = theme.Muted.Render("… more in docs/settings.md")
+	}
+	rule := theme.Muted.Render(" │ ")
+	var sb strings.Builder
+	for i := range max(len(left), len(right)) {
+		l, r := "", ""
+		if i < len(left) {
+			l = truncate(left[i], navW)
+		}
+		if i < len(right) {
+			r = truncate(right[i], paneW)
+		}
+		sb.WriteString(l + strings.Repeat(" ", max(navW-lipgloss.Width(l), 0)) + rule + r + "\n")
+	}
+	return sb.String()
+}
+
+// viewDetail renders the detail pane of the current screen.
+func (m Model) viewDetail(width int) string {
+	if m.mode == ModeMenu {
+		if m.menuCursor < len(menuEntries) {
+			return m.categoryDetail(menuEntries[m.menuCursor], width)
+		}
+		return ""
+	}
+	def := m.currentSettingDef()
+	if def.Key == "" {
+		return ""
+	}
+	return m.settingDetail(def, width)
+}
+
+// categoryDetail describes a main menu entry: its description, the values of
+// its settings and the introduction from the settings reference.
+func (m Model) categoryDetail(e menuEntry, width int) string {
+	var sb strings.Builder
+	sb.WriteString(theme.Bold.Render(e.label) + "\n")
+	sb.WriteString(theme.Muted.Render(m.menuSummary(e.cat)) + "\n\n")
+
+	if e.cat == CatBYOK {
+		sb.WriteString(wrapText(theme.Primary.Render("Models you bring with your own API key, kept in the customModels key of settings.json. Droid lists them next to its built-in models."), width) + "\n\n")
+	}
+	sc, _ := config.CurrentSchema()
+	if c, ok := sc.Category(string(e.cat)); ok && c.Description != "" {
+		sb.WriteString(wrapText(theme.Primary.Render(c.Description), width) + "\n\n")
+	}
+	if defs := settingsIn(e.cat); len(defs) > 0 {
+		nameStyle := lipgloss.NewStyle().Width(22)
+		for _, def := range defs {
+			value := theme.Muted.Render(m.settingValueDisplay(def))
+			if m.isExplicit(def) {
+				value = theme.Teal.Render(m.settingValueDisplay(def))
+			}
+			sb.WriteString(nameStyle.Inherit(theme.Primary).Render(def.Label) + value + "\n")
+		}
+		sb.WriteString("\n")
+	}
+	if s, ok := docs.Category(e.badge); ok && s.Body != "" {
+		sb.WriteString(renderMarkdown(s.Body, width))
+	}
+	return sb.String()
+}
+
+// settingDetail describes a setting: what it does, the values it takes, its
+// default, key and current value, and its section of the settings reference.
+func (m Model) settingDetail(def config.SettingSpec, width int) string {
+	var sb strings.Builder
+	sb.WriteString(theme.Bold.Render(def.Label) + "\n")
+	sb.WriteString(theme.Teal.Render(def.Key) + theme.Muted.Render("  ·  "+string(def.Type)) + "\n\n")
+	if def.Description != "" {
+		sb.WriteString(wrapText(theme.Primary.Render(def.Description), width) + "\n\n")
+	}
+
+	if values := m.valueRows(def); len(values) > 0 {
+		sb.WriteString(theme.Accent.Render("Values") + "\n")
+		valueStyle := lipgloss.NewStyle().Width(detailValueCol)
+		for _, v := range values {
+			row := valueStyle.Inherit(theme.Teal).Render(v[0]) + theme.Muted.Render(v[1])
+			if v[0] == m.highlightedValue(def) {
+				row = valueStyle.Inherit(theme.Accent).Bold(true).Render(v[0]) + theme.Primary.Render(v[1])
+			}
+			sb.WriteString("  " + row + "\n")
+		}
+		sb.WriteString("\n")
+	}
+
+	field := func(name, value string) {
+		sb.WriteString(lipgloss.NewStyle().Width(10).Inherit(theme.Muted).Render(name) + value + "\n")
+	}
+	field("Default", theme.Primary.Render(orDef(def.DefaultText(), "none")))
+	field("JSON key", theme.Teal.Render(def.Key))
+	if v, ok := m.settings.Value(def.Key); ok {
+		raw, _ := json.Marshal(v)
+		field("Current", theme.Teal.Render(string(raw)))
+		field("File", theme.Primary.Render(config.SettingsPath()))
+	} else {
+		field("Current", theme.Muted.Render("not set · Droid's default applies"))
+		field("File", theme.Muted.Render("— (not in settings.json)"))
+	}
+
+	if s, ok := docs.Setting(def.Key); ok {
+		if text := renderMarkdown(settingDocs(s.Body, def), width); text != "" {
+			sb.WriteString("\n" + text)
+		}
+	}
+	return sb.String()
+}
+
+// detailValueCol is the width of the value column of the Values list.
+const detailValueCol = 20
+
+// valueRows lists the values a setting takes, with what each one means.
+func (m Model) valueRows(def config.SettingSpec) [][2]string {
+	var rows [][2]string
+	switch def.Type {
+	case config.TypeEnum:
+		for _, o := range def.Options {
+			rows = append(rows, [2]string{o.Value, o.Desc})
+		}
+		if def.Custom != nil {
+			rows = append(rows, [2]string{def.Custom.Label, def.Custom.Desc})
+		}
+	case config.TypeBool:
+		rows = append(rows,
+			[2]string{config.Inherit.String(), "key removed · Droid's default, " + def.DefaultText()},
+			[2]string{config.On.String(), "true"},
+			[2]string{config.Off.String(), "false"},
+		)
+	}
+	return rows
+}
+
+// highlightedValue is the value to mark in the Values list: the option under
+// the picker's cursor, otherwise the setting's current value.
+func (m Model) highlightedValue(def config.SettingSpec) string {
+	if m.mode == ModeOptionPick || m.mode == ModeBoolPick {
+		if m.optionList.cursor < len(m.optionList.items) {
+			switch v := m.optionList.items[m.optionList.cursor].value; v {
+			case unsetOption:
+				return ""
+			case customOption:
+				return def.Custom.Label
+			default:
+				return v
+			}
+		}
+	}
+	if def.Type == config.TypeBool {
+		return m.settings.GetTri(def.Key).String()
+	}
+	if !m.isExplicit(def) {
+		return ""
+	}
+	return m.settings.GetField(def.Key)
+}
+
+// settingDocs drops from a setting's section what the pane already shows:
+// the description, the table of values and the default and key lines.
+func settingDocs(body string, def config.SettingSpec) string {
+	var out []string
+	for _, para := range strings.Split(body, "\n\n") {
+		para = strings.TrimSpace(para)
+		if para == def.Description || strings.HasPrefix(para, "|") || strings.HasPrefix(para, "**Default:**") ||
+			strings.HasPrefix(para, "**JSON key:**") {
+			continue
+		}
+		out = append(out, para)
+	}
+	return strings.Join(out, "\n\n")
+}
+
+// ─── Markdown ─────────────────────────────────────────────────────────────────
+
+// renderMarkdown draws the markdown of the settings reference for the
+// terminal: paragraphs and list items wrapped to width, code blocks and
+// tables as rows, inline code in the code color.
+func renderMarkdown(md string, width int) string {
+	var sb strings.Builder
+	var para []string
+	flush := func() {
+		if len(para) > 0 {
+			sb.WriteString(wrapText(inlineMarkdown(strings.Join(para, " ")), width) + "\n")
+			para = nil
+		}
+	}
+	lines := strings.Split(md, "\n")
+	fenced := false
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "```"):
+			flush()
+			fenced = !fenced
+		case fenced:
+			sb.WriteString(theme.Teal.Render("  "+line) + "\n")
+		case strings.TrimSpace(line) == "":
+			flush()
+			sb.WriteString("\n")
+		case strings.HasPrefix(line, "|"):
+			flush()
+			cells := tableCells(line)
+			if isTableRule(cells) || i+1 < len(lines) && isTableRule(tableCells(lines[i+1])) {
+				continue // the rule and the header row above it
+			}
+			row := theme.Accent.Render(stripMarkdown(cells[0]))
+			if len(cells) > 1 {
+				row += "  " + inlineMarkdown(strings.Join(cells[1:], " · "))
+			}
+			// Wrapped rows hang below the text of the first column.
+			wrapped := strings.Split(wrapText(row, max(width-4, 10)), "\n")
+			sb.WriteString("  " + strings.Join(wrapped, "\n    ") + "\n")
+		case strings.HasPrefix(line, "- "):
+			flush()
+			para = append(para, "• "+strings.TrimPrefix(line, "- "))
+		case strings.HasPrefix(line, "#"):
+			flush()
+			sb.WriteString(theme.Accent.Render(strings.TrimLeft(line, "# ")) + "\n")
+		default:
+			para = append(para, strings.TrimSpace(line))
+			if strings.HasSuffix(line, "  ") { // a hard line break
+				flush()
+			}
+		}
+	}
+	flush()
+	if out := strings.Trim(sb.String(), "\n"); out != "" {
+		return out + "\n"
+	}
+	return ""
+}
+
+func tableCells(line string) []string {
+	cells := strings.Split(strings.Trim(strings.TrimSpace(line), "|"), "|")
+	for i := range cells {
+		cells[i] = strings.TrimSpace(cells[i])
+	}
+	return cells
+}
+
+func isTableRule(cells []string) bool {
+	return len(cells) > 0 && strings.Trim(cells[0], "-: ") == "" && cells[0] != ""
+}
+
+var inlineRe = regexp.MustCompile("`[^`]+`|\\*\\*[^*]+\\*\\*|\\*[^*]+\\*|\\[[^\\]]+\\]\\([^)]*\\)")
+
+// inlineMarkdown styles code spans, bold and italic text, and keeps the text
+// of links.
+func inlineMarkdown(s string) string {
+	var sb strings.Builder
+	last := 0
+	for _, loc := range inlineRe.FindAllStringIndex(s, -1) {
+		sb.WriteString(theme.Primary.Render(s[last:loc[0]]))
+		tok := s[loc[0]:loc[1]]
+		switch {
+		case strings.HasPrefix(tok, "`"):
+			sb.WriteString(theme.Teal.Render(strings.Trim(tok, "`")))
+		case strings.HasPrefix(tok, "**"):
+			sb.WriteString(theme.Bold.Render(strings.Trim(tok, "*")))
+		case strings.HasPrefix(tok, "*"):
+			sb.WriteString(theme.Muted.Render(strings.Trim(tok, "*")))
+		default:
+			sb.WriteString(theme.Primary.Render(tok[1:strings.Index(tok, "](")]))
+		}
+		last = loc[1]
+	}
+	sb.WriteString(theme.Primary.Render(s[last:]))
+	return sb.String()
+}
+
+// stripMarkdown removes the inline markup from s.
+func stripMarkdown(s string) string {
+	return inlineRe.ReplaceAllStringFunc(s, func(tok string) string {
+		if strings.HasPrefix(tok, "[") {
+			return tok[1:strings.Index(tok, "](")]
+		}
+		return strings.Trim(tok, "`*")
+	})
+}
+
+// wrapText wraps styled text at word boundaries to width.
+func wrapText(s string, width int) string {
+	return strings.TrimRight(lipgloss.NewStyle().Width(width).Render(s), " ")
+}
@@ -0,0 +1,137 @@
+package ui
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/kaan-escober/wrench/internal/config"
+	"github.com/kaan-escober/wrench/internal/droids"
+	"github.com/kaan-escober/wrench/internal/theme"
+)
+
+// ─── Droids ───────────────────────────────────────────────────────────────────
+
+// droidNameCol is the width of the name column in the droids list.
+const droidNameCol = 24
+
+func (m Model) viewDroids() string {
+	header := viewHeader("DRD", "Custom droids Droid can hand tasks to")
+
+	var note string
+	if m.settings.GetTri("enableCustomDroids") == config.Off {
+		note = theme.Accent.Render("  enableCustomDroids is off · Droid ignores these files (Agent Behavior)") + "\n\n"
+	}
+	if len(m.droidList) == 0 {
+		return header + note + theme.Muted.Render("  No droids yet · a · create one")
+	}
+
+	var lines []string
+	for i, d := range m.droidList {
+		lines = append(lines, zoneMark(zoneRow, i)+m.renderDroidRow(d, i == m.droidCursor))
+	}
+	details := m.viewDroidDetails(m.droidList[m.droidCursor])
+	height := max(listHeight(m.height)-lipgloss.Height(note)-lipgloss.Height(details)-1, 3)
+	start := max(m.droidCursor-height+1, 0)
+	end := min(start+height, len(lines))
+
+	var sb strings.Builder
+	if start > 0 {
+		sb.WriteString(theme.Muted.Render("  ↑ more") + "\n")
+	}
+	sb.WriteString(strings.Join(lines[start:end], "\n") + "\n")
+	if end < len(lines) {
+		sb.WriteString(theme.Muted.Render("  ↓ more") + "\n")
+	}
+	return header + note + sb.String() + "\n" + details
+}
+
+func (m Model) renderDroidRow(d droids.Droid, isCursor bool) string {
+	cursor := "  "
+	if isCursor {
+		cursor = theme.Accent.Render("> ")
+	}
+	scope := lipgloss.NewStyle().Width(10).Render(theme.Muted.Render(d.Scope.String()))
+	name := truncate(orDef(d.Name, "(no name)"), droidNameCol-2)
+	if isCursor {
+		name = theme.Accent.Bold(true).Render(name)
+	} else {
+		name = theme.Primary.Render(name)
+	}
+	nameCell := lipgloss.NewStyle().Width(droidNameCol).Render(name)
+
+	var warn string
+	if len(d.Problems) > 0 {
+		warn = theme.Error.Render("  ⚠")
+	}
+	return cursor + scope + nameCell + theme.Teal.Render(orDef(d.Model, droids.Inherit)) + warn
+}
+
+func (m Model) viewDroidDetails(d droids.Droid) string {
+	row := func(label, value string) string {
+		return theme.Muted.Render("  "+lipgloss.NewStyle().Width(13).Render(label)) + value + "\n"
+	}
+	var sb strings.Builder
+	sb.WriteString(row("description", theme.Primary.Render(truncate(orDef(d.Description, "—"), max(m.width-18, 10)))))
+	model := "inherit · the session's model"
+	if d.Model != "" && d.Model != droids.Inherit {
+		model, _ = m.modelRefLabel(d.Model)
+	}
+	sb.WriteString(row("model", theme.Teal.Render(model)))
+	sb.WriteString(row("tools", theme.Primary.Render(droidToolsLabel(d))))
+	sb.WriteString(row("file", theme.Muted.Render(d.Path)))
+	for _, p := range d.Problems {
+		sb.WriteString(theme.Error.Render("  ⚠ "+p) + "\n")
+	}
+	return sb.String()
+}
+
+// droidToolsLabel describes a droid's tools field.
+func droidToolsLabel(d droids.Droid) string {
+	switch {
+	case d.ToolsField != "":
+		return d.ToolsField + " · " + strings.Join(d.Tools, ", ")
+	case d.Tools == nil:
+		return "all tools"
+	case len(d.Tools) == 0:
+		return "none"
+	}
+	return strings.Join(d.Tools, ", ")
+}
+
+func (m Model) viewDroidWizard() string {
+	d := m.droidDraft
+	switch m.droidStep {
+	case DroidScope:
+		return viewHeader("NEW DROID", "Where to save it · personal droids work in every project") + m.droidPick.render(true)
+	case DroidName:
+		return viewHeader("NAME", "Lowercase letters, digits, - and _ · also the file name") +
+			theme.PromptStr() + m.textInput.View()
+	case DroidDescription:
+		return viewHeader("DESCRIPTION", "Droid reads this to decide when to delegate to "+d.Name) +
+			theme.PromptStr() + m.textInput.View()
+	case DroidModel:
+		return viewHeader("MODEL", "Which model "+d.Name+" runs on") + m.droidPick.render(true)
+	case DroidTools:
+		return viewHeader("TOOLS", "space · pick the tools "+d.Name+" may use · pick none to allow all") +
+			m.droidPick.render(true)
+	case DroidPrompt:
+		return viewHeader("PROMPT", "The system prompt "+d.Name+" starts every task with") + m.droidPrompt.View()
+	}
+
+	home, cwd := homeAndCwd()
+	path := filepath.Join(droids.Dir(d.Scope, home, cwd), d.Name+".md")
+	var sb strings.Builder
+	sb.WriteString(theme.Muted.Render("  "+path) + "\n\n")
+	lines := strings.Split(strings.TrimRight(string(droids.Render(d)), "\n"), "\n")
+	limit := max(listHeight(m.height)-4, 5)
+	for i, line := range lines {
+		if i == limit {
+			sb.WriteString(theme.Muted.Render("    …") + "\n")
+			break
+		}
+		sb.WriteString(theme.Primary.Render("    "+truncate(line, max(m.width-6, 10))) + "\n")
+	}
+	return viewHeader("CREATE", "Write this droid file?") + sb.String()
+}
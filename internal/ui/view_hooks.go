@@ -0,0 +1,230 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/kaan-escober/wrench/internal/config"
+	"github.com/kaan-escober/wrench/internal/hooks"
+	"github.com/kaan-escober/wrench/internal/theme"
+)
+
+// ─── Hooks ────────────────────────────────────────────────────────────────────
+
+// matcherCol is the width of the matcher column in the hooks list.
+const matcherCol = 22
+
+func (m Model) viewHooks() string {
+	header := viewHeader("HOOK", "Shell commands Droid runs on session events")
+	if m.hookLoadErr != "" {
+		return header +
+			theme.Error.Render("  △  The hooks section cannot be edited here: "+m.hookLoadErr) + "\n\n" +
+			theme.Muted.Render("  Fix it on the Advanced / Other screen or in settings.json.")
+	}
+
+	var note string
+	if m.settings.GetTri("hooksDisabled") == config.On {
+		note = theme.Accent.Render("  hooksDisabled is on · Droid runs none of these (Agent Behavior)") + "\n\n"
+	}
+	if len(m.hookList) == 0 {
+		return header + note + theme.Muted.Render("  No hooks yet · a · add one  t · start from a template")
+	}
+
+	// Lines of event headers and hooks; keep the cursor's line in view.
+	var lines []string
+	cursorLine := 0
+	event := ""
+	for i, h := range m.hookList {
+		if h.Event != event {
+			event = h.Event
+			if len(lines) > 0 {
+				lines = append(lines, "")
+			}
+			lines = append(lines, m.renderHookEvent(event))
+		}
+		if i == m.hookCursor {
+			cursorLine = len(lines)
+		}
+		lines = append(lines, zoneMark(zoneRow, i)+m.renderHookRow(h, i == m.hookCursor))
+	}
+	height := listHeight(m.height) - lipgloss.Height(note)
+	start := max(cursorLine-height+1, 0)
+	end := min(start+height, len(lines))
+
+	var sb strings.Builder
+	if start > 0 {
+		sb.WriteString(theme.Muted.Render("  ↑ more") + "\n")
+	}
+	sb.WriteString(strings.Join(lines[start:end], "\n") + "\n")
+	if end < len(lines) {
+		sb.WriteString(theme.Muted.Render("  ↓ more"))
+	}
+	return header + note + sb.String()
+}
+
+func (m Model) renderHookEvent(name string) string {
+	desc := "unknown event · kept as is"
+	if e, ok := hooks.LookupEvent(name); ok {
+		desc = e.Desc
+	}
+	return "  " + theme.Bold.Render(name) + "  " + theme.Muted.Render(desc)
+}
+
+func (m Model) renderHookRow(h hooks.Hook, isCursor bool) string {
+	cursor := "  "
+	if isCursor {
+		cursor = theme.Accent.Render("> ")
+	}
+	dot := theme.Accent.Render("●")
+	if !h.Enabled {
+		dot = theme.Muted.Render("○")
+	}
+
+	matcher := h.Matcher
+	if matcher == "" {
+		matcher = "*"
+	}
+	matcherCell := lipgloss.NewStyle().Width(matcherCol).Render(theme.Teal.Render(truncate(matcher, matcherCol-2)))
+
+	var warn string
+	if missing := m.hookMissing[h.Command]; len(missing) > 0 {
+		warn = theme.Error.Render("  ⚠ not found: " + strings.Join(missing, ", "))
+	}
+	cmdWidth := max(m.width-matcherCol-8-lipgloss.Width(warn), 10)
+	command := truncate(h.Command, cmdWidth)
+	switch {
+	case !h.Enabled:
+		command = theme.Muted.Render(command)
+	case isCursor:
+		command = theme.Accent.Bold(true).Render(command)
+	default:
+		command = theme.Primary.Render(command)
+	}
+	return cursor + "  " + dot + " " + matcherCell + command + warn
+}
+
+// truncate shortens s to width cells, ending in an ellipsis. Escape
+// sequences are kept, so styled text can be cut too.
+func truncate(s string, width int) string {
+	if width < 1 {
+		return s
+	}
+	return ansi.Truncate(s, width, "…")
+}
+
+func (m Model) viewHookForm() string {
+	subtitle := "New hook"
+	if m.hookEditIdx >= 0 {
+		subtitle = "Editing hook"
+	}
+	body := m.hookForm.render(true)
+	if e, ok := hooks.LookupEvent(m.hookDraft.Event); ok {
+		body += "\n" + theme.Muted.Render("  "+e.Name+": "+e.Desc)
+	}
+	if m.hookDraft.Command != "" {
+		if missing := hooks.Missing(m.hookDraft.Command); len(missing) > 0 {
+			body += "\n" + theme.Error.Render("  ⚠ not found on PATH: "+strings.Join(missing, ", "))
+		}
+	}
+	return viewHeader("HOOK", subtitle) + body
+}
+
+func (m Model) viewHookInput() string {
+	var title, subtitle string
+	switch m.hookField {
+	case "matcher":
+		title = "MATCHER"
+		subtitle = "Regular expression matched against the tool name · empty matches everything"
+	case "command":
+		title = "COMMAND"
+		subtitle = "Run with sh -c · the event arrives as JSON on stdin · $FACTORY_PROJECT_DIR is the project root"
+	case "timeout":
+		title = "TIMEOUT"
+		subtitle = "Seconds before Droid stops waiting for the command"
+	}
+	return viewHeader(title, subtitle) + theme.PromptStr() + m.textInput.View()
+}
+
+func (m Model) viewHookPick() string {
+	if m.hookPicking == "template" {
+		return viewHeader("TEMPLATE", "Start from a common hook · you can edit it before saving") + m.hookPick.render(true)
+	}
+	return viewHeader("EVENT", "When the hook runs") + m.hookPick.render(true)
+}
+
+func (m Model) viewHookRun() string {
+	h := m.hookDraft
+	subtitle := h.Event
+	if h.Matcher != "" {
+		subtitle += " · " + h.Matcher
+	}
+	var sb strings.Builder
+	sb.WriteString(theme.Muted.Render("  command  ") + theme.Teal.Render(h.Command) + "\n\n")
+	sb.WriteString(theme.Muted.Render("  sample input on stdin") + "\n")
+	for _, line := range strings.Split(string(m.hookInput), "\n") {
+		sb.WriteString(theme.Muted.Render("    "+line) + "\n")
+	}
+	sb.WriteString("\n")
+
+	switch {
+	case m.hookRunning:
+		sb.WriteString("  " + m.spinner.View() + theme.Primary.Render(" Running…"))
+	case m.hookResult == nil:
+		sb.WriteString(theme.Accent.Render("  enter") + theme.Primary.Render(" · run the command once") +
+			theme.Muted.Render("  (it really runs, in the current directory)"))
+	default:
+		r := m.hookResult
+		badge := theme.Badge
+		switch {
+		case r.Err == nil && !r.TimedOut && r.ExitCode == 0:
+			badge = theme.BadgeSuccess
+		case r.ExitCode == 2:
+			badge = theme.BadgeError
+		}
+		status := fmt.Sprintf("EXIT %d", r.ExitCode)
+		if r.TimedOut {
+			status = "TIMEOUT"
+		}
+		sb.WriteString("  " + badge.Render(status) + "  " + theme.Primary.Render(r.Meaning()) +
+			theme.Muted.Render(fmt.Sprintf("  · %s", r.Duration.Round(1e6))) + "\n")
+		sb.WriteString(renderOutput("stdout", r.Stdout))
+		sb.WriteString(renderOutput("stderr", r.Stderr))
+	}
+	return viewHeader("RUN", subtitle) + sb.String()
+}
+
+// hookOutputLines caps how much of each output stream the run preview shows.
+const hookOutputLines = 8
+
+func renderOutput(name, out string) string {
+	out = strings.TrimRight(out, "\n")
+	if out == "" {
+		return "\n" + theme.Muted.Render("  "+name+"  (empty)") + "\n"
+	}
+	lines := strings.Split(out, "\n")
+	var sb strings.Builder
+	sb.WriteString("\n" + theme.Muted.Render("  "+name) + "\n")
+	for i, line := range lines {
+		if i == hookOutputLines {
+			sb.WriteString(theme.Muted.Render(fmt.Sprintf("    … %d more line(s)", len(lines)-i)) + "\n")
+			break
+		}
+		sb.WriteString(theme.Primary.Render("    "+line) + "\n")
+	}
+	return sb.String()
+}
+
+func (m Model) viewHookConfirm() string {
+	h := m.hookList[m.hookCursor]
+	matcher := h.Matcher
+	if matcher == "" {
+		matcher = "*"
+	}
+	return viewHeader("DELETE", "Delete this "+h.Event+" hook?") +
+		theme.Muted.Render("  matcher  ") + theme.Teal.Render(matcher) + "\n" +
+		theme.Muted.Render("  command  ") + theme.Primary.Render(h.Command) + "\n\n" +
+		m.confirmButtons()
+}
@@ -0,0 +1,144 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/kaan-escober/wrench/internal/keymap"
+	"github.com/kaan-escober/wrench/internal/theme"
+)
+
+// ─── Key help ─────────────────────────────────────────────────────────────────
+
+// screen returns the keymap screen of the current mode: the keys it
+// answers to, for the footer, the ? overlay and the typing check.
+func (m Model) screen() keymap.Screen {
+	s, _ := keymap.ScreenNamed(m.screenName())
+	return s
+}
+
+func (m Model) screenName() string {
+	switch m.mode {
+	case ModeMenu:
+		return "menu"
+	case ModePalette:
+		return "palette"
+	case ModeCategory:
+		return "category"
+	case ModeOptionPick, ModeBoolPick, ModeHookPick, ModeSlashPick:
+		return "pick"
+	case ModeTextInput:
+		return "setting-input"
+	case ModeCommandAdd, ModeHookInput, ModeSlashInput, ModeMCPInput, ModeOtherInput:
+		return "input"
+	case ModeCommandEdit:
+		return "commands"
+	case ModeCommandPaste:
+		return "command-paste"
+	case ModeCommandFilter:
+		return "command-filter"
+	case ModeCommandTest:
+		return "command-test"
+	case ModeCommandPreset:
+		if m.presetPlan != nil {
+			return "preset-merge"
+		}
+		return "presets"
+	case ModeCommandSuggest:
+		return "suggest"
+	case ModeHooks:
+		if m.hookLoadErr != "" {
+			return "readonly"
+		}
+		return "hooks"
+	case ModeHookForm:
+		return "hook-form"
+	case ModeHookRun:
+		return "hook-run"
+	case ModeHookConfirm, ModeSlashConfirm, ModeMCPConfirm, ModeOtherConfirm:
+		return "confirm"
+	case ModeSlash:
+		return "slash"
+	case ModeMCP:
+		if m.mcpLoadErr != "" {
+			return "readonly"
+		}
+		return "mcp"
+	case ModeMCPForm:
+		return "mcp-form"
+	case ModeMCPLines:
+		return "mcp-lines"
+	case ModeMCPTest:
+		return "mcp-test"
+	case ModeDroids:
+		return "droids"
+	case ModeDroidWizard:
+		switch m.droidStep {
+		case DroidTools:
+			return "droid-tools"
+		case DroidName, DroidDescription:
+			return "droid-input"
+		case DroidPrompt:
+			return "droid-prompt"
+		case DroidConfirm:
+			return "droid-confirm"
+		}
+		return "droid-pick"
+	case ModeOther:
+		return "other"
+	case ModeOtherTree:
+		return "tree"
+	case ModeBYOK:
+		return m.byokScreenName()
+	}
+	return ""
+}
+
+func (m Model) byokScreenName() string {
+	switch m.byokStep {
+	case WizProvider:
+		return "byok-providers"
+	case WizGroupDetail:
+		return "byok-group"
+	case WizModelEdit:
+		return "byok-model"
+	case WizModelField:
+		if m.editFieldKey == "provider" || m.editFieldKey == "supportsImages" || m.editFieldKey == "delete" {
+			return "pick"
+		}
+		return "input"
+	case WizModels:
+		if len(m.availableModels) == 0 {
+			return "byok-manual"
+		}
+		return "byok-models"
+	case WizFetching:
+		return "byok-fetching"
+	case WizSettingsImages, WizConfirm, WizDone:
+		return "pick"
+	}
+	return "input"
+}
+
+// helpKeyCol is the width of the key column of the ? overlay.
+const helpKeyCol = 26
+
+func (m Model) viewHelp() string {
+	s := m.screen()
+	var sb strings.Builder
+	row := func(keys, desc string) {
+		cell := lipgloss.NewStyle().Width(helpKeyCol).Render(theme.Accent.Render("  " + keys))
+		sb.WriteString(cell + theme.Primary.Render(desc) + "\n")
+	}
+	for _, r := range m.keys.HelpRows(s) {
+		row(r[0], r[1])
+	}
+	if s.Name != "menu" { // the menu lists help and quit itself
+		sb.WriteString("\n")
+		row(keymap.Display(m.keys.Keys(keymap.Help)), "this help")
+		row(keymap.Display(m.keys.Keys(keymap.Quit)), "quit")
+	}
+	sb.WriteString("\n" + theme.Muted.Render("  Remap keys in "+keymap.Path()+" · wrench keys checks it for conflicts"))
+	return viewHeader("KEYS", s.Title) + sb.String()
+}
@@ -0,0 +1,172 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/kaan-escober/wrench/internal/config"
+	"github.com/kaan-escober/wrench/internal/mcp"
+	"github.com/kaan-escober/wrench/internal/theme"
+)
+
+// ─── MCP servers ──────────────────────────────────────────────────────────────
+
+// mcpNameCol is the width of the name column in the server list.
+const mcpNameCol = 20
+
+func (m Model) viewMCP() string {
+	header := viewHeader("MCP", "Servers that give Droid extra tools · "+config.MCPPath())
+	if m.mcpLoadErr != "" {
+		return header +
+			theme.Error.Render("  △  mcp.json cannot be edited here: "+m.mcpLoadErr) + "\n\n" +
+			theme.Muted.Render("  Fix the file by hand, then open this screen again.")
+	}
+	if len(m.mcpList) == 0 {
+		return header + theme.Muted.Render("  No servers yet · a · add one")
+	}
+
+	height := listHeight(m.height)
+	start := max(m.mcpCursor-height+1, 0)
+	end := min(start+height, len(m.mcpList))
+	var sb strings.Builder
+	if start > 0 {
+		sb.WriteString(theme.Muted.Render("  ↑ more") + "\n")
+	}
+	for i := start; i < end; i++ {
+		sb.WriteString(zoneMark(zoneRow, i) + m.renderMCPRow(m.mcpList[i], i == m.mcpCursor) + "\n")
+	}
+	if end < len(m.mcpList) {
+		sb.WriteString(theme.Muted.Render("  ↓ more"))
+	}
+	return header + sb.String()
+}
+
+func (m Model) renderMCPRow(s mcp.Server, isCursor bool) string {
+	cursor := "  "
+	if isCursor {
+		cursor = theme.Accent.Render("> ")
+	}
+	dot := theme.Accent.Render("●")
+	if s.Disabled {
+		dot = theme.Muted.Render("○")
+	}
+	name := truncate(s.Name, mcpNameCol-2)
+	switch {
+	case s.Disabled:
+		name = theme.Muted.Render(name)
+	case isCursor:
+		name = theme.Accent.Bold(true).Render(name)
+	default:
+		name = theme.Primary.Render(name)
+	}
+	nameCell := lipgloss.NewStyle().Width(mcpNameCol).Render(name)
+	typeCell := lipgloss.NewStyle().Width(7).Render(theme.Muted.Render(s.Type))
+
+	var warn string
+	if problems := m.mcpProblems[s.Name]; len(problems) > 0 {
+		warn = theme.Error.Render("  ⚠ " + strings.Join(problems, " · "))
+	}
+	target := mcpTarget(s)
+	width := max(m.width-mcpNameCol-16-lipgloss.Width(warn), 10)
+	return cursor + "  " + dot + " " + nameCell + typeCell + theme.Teal.Render(truncate(target, width)) + warn
+}
+
+// mcpTarget is what a server runs or connects to: the command line or URL.
+func mcpTarget(s mcp.Server) string {
+	if s.Remote() {
+		return s.URL
+	}
+	return strings.TrimSpace(s.Command + " " + strings.Join(s.Args, " "))
+}
+
+func (m Model) viewMCPForm() string {
+	subtitle := "New server"
+	if m.mcpEditIdx >= 0 {
+		subtitle = "Editing " + m.mcpList[m.mcpEditIdx].Name
+	}
+	body := m.mcpForm.render(true)
+	for _, p := range m.mcpDraft.Problems() {
+		body += "\n" + theme.Error.Render("  ⚠ "+p)
+	}
+	return viewHeader("MCP", subtitle) + body
+}
+
+func (m Model) viewMCPInput() string {
+	var title, subtitle string
+	switch m.mcpField {
+	case "name":
+		title = "NAME"
+		subtitle = "How Droid labels the server and its tools"
+	case "command":
+		title = "COMMAND"
+		subtitle = "The program Droid starts · arguments go in their own field"
+	case "url":
+		title = "URL"
+		subtitle = "Endpoint of the remote server · ${VAR} is expanded"
+	}
+	return viewHeader(title, subtitle) + theme.PromptStr() + m.textInput.View()
+}
+
+func (m Model) viewMCPLines() string {
+	var title, subtitle string
+	switch m.mcpField {
+	case "args":
+		title = "ARGUMENTS"
+		subtitle = "One argument per line, passed as is · ${VAR} is expanded"
+	case "env":
+		title = "ENVIRONMENT"
+		subtitle = "KEY=value per line · use ${VAR} to keep secrets out of mcp.json"
+	case "headers":
+		title = "HEADERS"
+		subtitle = "Name: value per line · use ${VAR} to keep secrets out of mcp.json"
+	}
+	return viewHeader(title, subtitle) + m.mcpLines.View()
+}
+
+// mcpToolLines caps how many tools the launch test lists.
+const mcpToolLines = 12
+
+func (m Model) viewMCPTest() string {
+	s := m.mcpDraft
+	var sb strings.Builder
+	sb.WriteString(theme.Muted.Render("  command  ") + theme.Teal.Render(mcpTarget(s)) + "\n\n")
+
+	if m.mcpTesting {
+		sb.WriteString("  " + m.spinner.View() + theme.Primary.Render(" Starting the server and listing its tools…"))
+		return viewHeader("TEST", s.Name) + sb.String()
+	}
+	r := m.mcpReport
+	if r == nil {
+		return viewHeader("TEST", s.Name) + sb.String()
+	}
+	if r.Err != nil {
+		sb.WriteString("  " + theme.BadgeError.Render("FAILED") + "  " + theme.Error.Render(r.Err.Error()) + "\n")
+	} else {
+		server := orDef(strings.TrimSpace(r.ServerName+" "+r.ServerVersion), "unnamed server")
+		sb.WriteString("  " + theme.BadgeSuccess.Render("OK") + "  " + theme.Primary.Render(server) +
+			theme.Muted.Render(fmt.Sprintf("  · protocol %s · %s", r.Protocol, r.Duration.Round(1e6))) + "\n\n")
+		sb.WriteString(theme.Muted.Render(fmt.Sprintf("  %d tool(s)", len(r.Tools))) + "\n")
+		for i, t := range r.Tools {
+			if i == mcpToolLines {
+				sb.WriteString(theme.Muted.Render(fmt.Sprintf("    … %d more", len(r.Tools)-i)) + "\n")
+				break
+			}
+			desc, _, _ := strings.Cut(t.Description, "\n")
+			sb.WriteString("    " + theme.Primary.Render(t.Name) + "  " +
+				theme.Muted.Render(truncate(desc, max(m.width-len(t.Name)-10, 10))) + "\n")
+		}
+	}
+	if !s.Remote() {
+		sb.WriteString(renderOutput("stderr", r.Stderr))
+	}
+	return viewHeader("TEST", s.Name) + sb.String()
+}
+
+func (m Model) viewMCPConfirm() string {
+	s := m.mcpList[m.mcpCursor]
+	return viewHeader("DELETE", "Delete the MCP server "+s.Name+"?") +
+		theme.Muted.Render("  "+s.Type+"  ") + theme.Teal.Render(mcpTarget(s)) + "\n\n" +
+		m.confirmButtons()
+}
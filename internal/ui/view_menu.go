@@ -7,11 +7,24 @@ import (
 	"github.com/charmbracelet/lipgloss"
 
 	"github.com/kaan-escober/wrench/internal/config"
+	"github.com/kaan-escober/wrench/internal/hooks"
 	"github.com/kaan-escober/wrench/internal/theme"
 )
 
 func (m Model) viewMenu() string {
-	return m.renderLogo() + "\n\n" + m.renderMenuRows()
+	return m.renderLogo() + "\n\n" + m.renderMenuRows() + m.renderChangedTotal()
+}
+
+// renderChangedTotal summarises how many settings differ from Droid's defaults.
+func (m Model) renderChangedTotal() string {
+	n := 0
+	for _, e := range menuEntries {
+		n += m.changedCount(e.cat)
+	}
+	if n == 0 {
+		return "\n" + theme.Muted.Render("  All settings use Droid's defaults") + "\n"
+	}
+	return "\n" + theme.Muted.Render(fmt.Sprintf("  %d setting(s) differ from Droid's defaults", n)) + "\n"
 }
 
 func (m Model) renderLogo() string {
@@ -37,12 +50,6 @@ var badgeTextWidth = func() int {
 	return w
 }()
 
-var badgeMuted = lipgloss.NewStyle().
-	Foreground(theme.ColorMuted).
-	Background(lipgloss.Color("#2A2B3A")).
-	Bold(true).
-	Padding(0, 1)
-
 // padBadge centres/pads badge text to badgeTextWidth so all blocks render identically.
 func padBadge(s string) string {
 	for len(s) < badgeTextWidth {
@@ -55,7 +62,13 @@ func (m Model) renderMenuRows() string {
 	var sb strings.Builder
 	labelStyle := lipgloss.NewStyle().Width(labelCol)
 
-	for i, entry := range menuEntries {
+	start := m.menuOffset
+	end := min(start+m.menuRows(), len(menuEntries))
+	if start > 0 {
+		sb.WriteString(theme.Muted.Render("  ↑ more") + "\n")
+	}
+	for i := start; i < end; i++ {
+		entry := menuEntries[i]
 		isCursor := i == m.menuCursor
 
 		text := padBadge(entry.badge)
@@ -63,7 +76,7 @@ func (m Model) renderMenuRows() string {
 		if isCursor {
 			badge = theme.Badge.Render(text)
 		} else {
-			badge = badgeMuted.Render(text)
+			badge = theme.BadgeMuted.Render(text)
 		}
 
 		var label string
@@ -74,15 +87,26 @@ func (m Model) renderMenuRows() string {
 		}
 
 		summary := m.menuSummary(entry.cat)
+		if n := m.changedCount(entry.cat); n > 0 {
+			summary += fmt.Sprintf("  ·  %d changed", n)
+		}
 
 		cursor := "  "
 		if isCursor {
 			cursor = theme.Accent.Render("> ")
 		}
 
-		row := cursor + badge + "  " + label + "  " + theme.Muted.Render(summary)
+		if m.compact() {
+			badge = ""
+		} else {
+			badge += "  "
+		}
+		row := zoneMark(zoneMenu, i) + cursor + badge + label + "  " + theme.Muted.Render(summary)
 		sb.WriteString(row + "\n")
 	}
+	if end < len(menuEntries) {
+		sb.WriteString(theme.Muted.Render("  ↓ more") + "\n")
+	}
 	return sb.String()
 }
 
@@ -98,45 +122,92 @@ func (m Model) menuSummary(cat Category) string {
 		}
 		return fmt.Sprintf("%d model(s) configured", n)
 
-	case CatModel:
-		model := orDef(s.Model, "opus")
-		effort := orDef(s.ReasoningEffort, "auto")
-		return model + "  ·  " + effort
+	case CatCommands:
+		a := len(s.GetList("commandAllowlist"))
+		d := len(s.GetList("commandDenylist"))
+		if a == 0 && d == 0 {
+			return "factory defaults"
+		}
+		return fmt.Sprintf("%d allowed  ·  %d denied", a, d)
 
-	case CatAutonomy:
-		return orDef(s.AutonomyLevel, "normal")
+	case CatHooks:
+		list, err := hooks.Parse(raw["hooks"], nil)
+		var summary string
+		switch {
+		case err != nil:
+			summary = "⚠ unreadable hooks section"
+		case len(list) == 0:
+			summary = "no hooks"
+		default:
+			events := map[string]bool{}
+			for _, h := range list {
+				events[h.Event] = true
+			}
+			summary = fmt.Sprintf("%d hook(s) on %d event(s)", len(list), len(events))
+		}
+		if s.GetTri("hooksDisabled") == config.On {
+			summary += "  ·  all off (hooksDisabled)"
+		}
+		return summary
 
-	case CatDisplay:
-		diff := orDef(s.DiffMode, "github")
-		todo := orDef(s.TodoDisplayMode, "pinned")
-		return diff + "  ·  " + todo
+	case CatSlash:
+		return "personal & project /commands"
 
-	case CatSound:
-		c := orDef(s.CompletionSound, "fx-ok01")
-		f := orDef(s.SoundFocusMode, "always")
-		return c + "  ·  " + f
+	case CatMCP:
+		if m.mcpLoadErr != "" {
+			return "⚠ unreadable mcp.json"
+		}
+		if len(m.mcpList) == 0 {
+			return "no servers"
+		}
+		off := 0
+		for _, s := range m.mcpList {
+			if s.Disabled {
+				off++
+			}
+		}
+		if off > 0 {
+			return fmt.Sprintf("%d server(s)  ·  %d off", len(m.mcpList), off)
+		}
+		return fmt.Sprintf("%d server(s)", len(m.mcpList))
 
-	case CatSecurity:
-		shield := dotBool(s.EnableDroidShield, true, true) + " shield"
-		coauth := dotBool(s.IncludeCoAuthoredByDroid, true, true) + " co-author"
-		bgProc := dotBool(s.AllowBackgroundProcesses, false, true) + " bg-proc"
-		return shield + "  " + coauth + "  " + bgProc
+	case CatDroids:
+		summary := "personal & project droids"
+		if m.settings.GetTri("enableCustomDroids") == config.Off {
+			summary += "  ·  off (enableCustomDroids)"
+		}
+		return summary
 
-	case CatBehavior:
-		cloud := dotBool(s.CloudSessionSync, true, true) + " cloud"
-		hooks := dotBool(s.HooksDisabled, false, false) + " hooks"
-		droids := dotBool(s.EnableCustomDroids, true, true) + " droids"
-		return cloud + "  " + hooks + "  " + droids
+	case CatOther:
+		n := len(otherKeys(raw))
+		if n == 0 {
+			return "no other keys"
+		}
+		return fmt.Sprintf("%d other key(s)", n)
+	}
 
-	case CatCommands:
-		a := len(s.CommandAllowlist)
-		d := len(s.CommandDenylist)
-		if a == 0 && d == 0 {
-			return "factory defaults"
+	// Schema categories: values of the settings marked for the summary,
+	// then one dot per summarised bool.
+	var parts, dots []string
+	for _, def := range settingsIn(cat) {
+		if def.Summary == "" {
+			continue
 		}
-		return fmt.Sprintf("%d allowed  ·  %d denied", a, d)
+		if def.Type == config.TypeBool {
+			dot := dotBool(s.GetTri(def.Key).Ptr(), def.DefaultOn(), !def.Inverted)
+			dots = append(dots, dot+" "+def.Summary)
+			continue
+		}
+		v := s.GetField(def.Key)
+		if def.Key == "model" && v != "" {
+			v, _ = m.modelRefLabel(v)
+		}
+		parts = append(parts, orDef(v, def.DefaultText()))
+	}
+	if len(dots) > 0 {
+		parts = append(parts, strings.Join(dots, "  "))
 	}
-	return ""
+	return strings.Join(parts, "  ·  ")
 }
 
 // orDef returns val if non-empty, else def.
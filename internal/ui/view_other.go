@@ -0,0 +1,128 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kaan-escober/wrench/internal/theme"
+)
+
+// ─── Advanced / other keys ─────────────────────────────────────────────────────
+
+func (m Model) viewOther() string {
+	header := viewHeader("ADV", "Top-level keys in settings.json that no other screen edits")
+	if len(m.otherList.items) == 0 {
+		return header + theme.Muted.Render("  No other keys · everything in settings.json has its own screen")
+	}
+	return header + m.otherList.render(true)
+}
+
+func (m Model) viewOtherTree() string {
+	subtitle := "Editing " + theme.Teal.Render(m.treeKey)
+	if m.treeDirty {
+		subtitle += theme.Accent.Render("  ● unsaved · esc saves")
+	}
+	header := viewHeader("ADV", subtitle)
+
+	rows := m.treeRows()
+	height := listHeight(m.height)
+	end := min(m.treeOffset+height, len(rows))
+
+	var sb strings.Builder
+	if m.treeOffset > 0 {
+		sb.WriteString(theme.Muted.Render("  ↑ more") + "\n")
+	}
+	for i := m.treeOffset; i < end; i++ {
+		sb.WriteString(zoneMark(zoneRow, i) + m.renderTreeRow(rows[i], i == m.treeCursor) + "\n")
+	}
+	if end < len(rows) {
+		sb.WriteString(theme.Muted.Render("  ↓ more"))
+	}
+	return header + sb.String()
+}
+
+func (m Model) renderTreeRow(row treeRow, isCursor bool) string {
+	cursor := "  "
+	if isCursor {
+		cursor = theme.Accent.Render("> ")
+	}
+	indent := strings.Repeat("  ", row.depth)
+
+	marker := "  "
+	if isContainer(row.value) {
+		if m.treeOpen[pathKey(row.path)] {
+			marker = theme.Muted.Render("▾ ")
+		} else {
+			marker = theme.Muted.Render("▸ ")
+		}
+	}
+
+	label := theme.Primary.Render(row.label)
+	if isCursor {
+		label = theme.Accent.Bold(true).Render(row.label)
+	}
+
+	var value string
+	if isContainer(row.value) {
+		value = theme.Muted.Render(jsonPreview(row.value))
+	} else {
+		value = theme.Teal.Render(jsonPreview(row.value)) + "  " + theme.Muted.Render(jsonKind(row.value))
+	}
+	return cursor + indent + marker + label + theme.Muted.Render(": ") + value
+}
+
+func (m Model) viewOtherInput() string {
+	var title, subtitle string
+	switch {
+	case m.otherAdding && m.otherNewKey == "" && jsonKind(getPath(m.treeRoot, m.otherPath)) == "object":
+		title = "NEW MEMBER"
+		subtitle = "Name of the new member in " + theme.Teal.Render(m.otherPathLabel(m.otherPath))
+	case m.otherAdding:
+		title = "NEW VALUE"
+		subtitle = "Value for " + theme.Teal.Render(m.otherPathLabel(m.otherPath)) +
+			theme.Muted.Render(" · JSON such as 42, true, {} or [] · anything else is stored as text")
+	default:
+		title = "EDIT"
+		subtitle = "Value of " + theme.Teal.Render(m.otherPathLabel(m.otherPath)) +
+			theme.Muted.Render(" · "+jsonKind(m.otherOldValue()))
+	}
+	return viewHeader(title, subtitle) + theme.PromptStr() + m.textInput.View()
+}
+
+// otherOldValue returns the value being edited or deleted.
+func (m Model) otherOldValue() any {
+	if m.otherFrom == ModeOther {
+		return m.rawCfg[m.otherList.items[m.otherList.cursor].value]
+	}
+	return getPath(m.treeRoot, m.otherPath)
+}
+
+// otherPathLabel names a node for prompts, e.g. hooks.PreToolUse[0].
+func (m Model) otherPathLabel(path []any) string {
+	if m.otherFrom == ModeOther && len(path) == 0 {
+		return m.otherList.items[m.otherList.cursor].value
+	}
+	var sb strings.Builder
+	sb.WriteString(m.treeKey)
+	for _, p := range path {
+		if i, ok := p.(int); ok {
+			fmt.Fprintf(&sb, "[%d]", i)
+		} else {
+			sb.WriteString("." + p.(string))
+		}
+	}
+	return sb.String()
+}
+
+func (m Model) viewOtherConfirm() string {
+	target := m.otherPathLabel(m.otherPath)
+	detail := "The key is removed from settings.json."
+	if m.otherFrom == ModeOtherTree && len(m.otherPath) > 0 {
+		detail = "The change is saved when you leave the editor."
+	}
+	v := m.otherOldValue()
+	return viewHeader("DELETE", "Delete "+theme.Teal.Render(target)+"?") +
+		theme.Muted.Render("  "+jsonKind(v)+"  "+jsonPreview(v)) + "\n\n" +
+		theme.Muted.Render("  "+detail) + "\n\n" +
+		m.confirmButtons()
+}
@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/kaan-escober/wrench/internal/theme"
+)
+
+// ─── Command palette ──────────────────────────────────────────────────────────
+
+// paletteTitleCol is the width of the title column of the results.
+const paletteTitleCol = 28
+
+func (m Model) viewPalette() string {
+	header := viewHeader("SEARCH", "Settings, custom models and actions · enter opens the edit screen")
+	input := "  " + m.paletteInput.View() + "\n\n"
+	if len(m.paletteHits) == 0 {
+		return header + input + theme.Muted.Render("  Nothing matches")
+	}
+
+	height := listHeight(m.height) - 2
+	start := m.paletteOffset
+	end := min(start+height, len(m.paletteHits))
+	var sb strings.Builder
+	if start > 0 {
+		sb.WriteString(theme.Muted.Render("  ↑ more") + "\n")
+	}
+	for i := start; i < end; i++ {
+		sb.WriteString(zoneMark(zoneRow, i) + m.renderPaletteRow(m.paletteHits[i], i == m.paletteCursor) + "\n")
+	}
+	if end < len(m.paletteHits) {
+		sb.WriteString(theme.Muted.Render(fmt.Sprintf("  ↓ %d more", len(m.paletteHits)-end)))
+	}
+	return header + input + sb.String()
+}
+
+func (m Model) renderPaletteRow(h paletteHit, isCursor bool) string {
+	e := h.entry
+	cursor := "  "
+	badge := theme.BadgeMuted.Render(padBadge(e.badge))
+	title := truncate(e.title, paletteTitleCol-2)
+	if isCursor {
+		cursor = theme.Accent.Render("> ")
+		badge = theme.Badge.Render(padBadge(e.badge))
+		title = theme.Accent.Bold(true).Render(title)
+	} else {
+		title = theme.Primary.Render(title)
+	}
+	titleCell := lipgloss.NewStyle().Width(paletteTitleCol).Render(title)
+	if m.compact() {
+		badge = ""
+	} else {
+		badge += "  "
+	}
+
+	detail := e.detail
+	if h.hint != "" && h.hint != e.detail {
+		detail += "  · " + h.hint
+	}
+	width := max(m.width-paletteTitleCol-lipgloss.Width(badge)-4, 10)
+	return cursor + badge + titleCell + theme.Muted.Render(truncate(detail, width))
+}
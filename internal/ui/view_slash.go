@@ -0,0 +1,117 @@
+package ui
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/kaan-escober/wrench/internal/scope"
+	"github.com/kaan-escober/wrench/internal/slash"
+	"github.com/kaan-escober/wrench/internal/theme"
+)
+
+// ─── Slash commands ───────────────────────────────────────────────────────────
+
+// slashNameCol is the width of the name column in the commands list.
+const slashNameCol = 22
+
+func (m Model) viewSlash() string {
+	header := viewHeader("SLSH", "Your own /commands · markdown prompts and executable scripts")
+	if len(m.slashList) == 0 {
+		home, cwd := homeAndCwd()
+		return header + theme.Muted.Render("  No commands yet · a · create one from a template") + "\n\n" +
+			theme.Muted.Render("  personal  "+slash.Dir(scope.Personal, home, cwd)) + "\n" +
+			theme.Muted.Render("  project   "+slash.Dir(scope.Project, home, cwd))
+	}
+
+	var lines []string
+	for i, c := range m.slashList {
+		lines = append(lines, zoneMark(zoneRow, i)+m.renderSlashRow(c, i == m.slashCursor))
+	}
+	details := m.viewSlashDetails(m.slashList[m.slashCursor])
+	height := max(listHeight(m.height)-lipgloss.Height(details)-1, 3)
+	start := max(m.slashCursor-height+1, 0)
+	end := min(start+height, len(lines))
+
+	var sb strings.Builder
+	if start > 0 {
+		sb.WriteString(theme.Muted.Render("  ↑ more") + "\n")
+	}
+	sb.WriteString(strings.Join(lines[start:end], "\n") + "\n")
+	if end < len(lines) {
+		sb.WriteString(theme.Muted.Render("  ↓ more") + "\n")
+	}
+	return header + sb.String() + "\n" + details
+}
+
+func (m Model) renderSlashRow(c slash.Command, isCursor bool) string {
+	cursor := "  "
+	if isCursor {
+		cursor = theme.Accent.Render("> ")
+	}
+	scopeCell := lipgloss.NewStyle().Width(10).Render(theme.Muted.Render(c.Scope.String()))
+	name := truncate("/"+c.Name, slashNameCol-2)
+	if isCursor {
+		name = theme.Accent.Bold(true).Render(name)
+	} else {
+		name = theme.Primary.Render(name)
+	}
+	nameCell := lipgloss.NewStyle().Width(slashNameCol).Render(name)
+	kindCell := lipgloss.NewStyle().Width(6).Render(theme.Teal.Render(c.Kind.String()))
+
+	var warn string
+	if len(c.Problems) > 0 {
+		warn = theme.Error.Render("  ⚠")
+	}
+	desc := truncate(c.Description, max(m.width-slashNameCol-24-lipgloss.Width(warn), 10))
+	return cursor + scopeCell + nameCell + kindCell + theme.Muted.Render(desc) + warn
+}
+
+func (m Model) viewSlashDetails(c slash.Command) string {
+	row := func(label, value string) string {
+		return theme.Muted.Render("  "+lipgloss.NewStyle().Width(13).Render(label)) + value + "\n"
+	}
+	var sb strings.Builder
+	usage := "/" + c.Name
+	if c.ArgumentHint != "" {
+		usage += " " + c.ArgumentHint
+	}
+	sb.WriteString(row("usage", theme.Teal.Render(usage)))
+	kind := "markdown prompt"
+	if c.Kind == slash.Executable {
+		kind = "executable script"
+	}
+	sb.WriteString(row("kind", theme.Primary.Render(kind)))
+	sb.WriteString(row("file", theme.Muted.Render(c.Path)))
+	for _, p := range c.Problems {
+		sb.WriteString(theme.Error.Render("  ⚠ "+p) + "\n")
+	}
+	return sb.String()
+}
+
+func (m Model) viewSlashPick() string {
+	if m.slashPicking == "template" {
+		return viewHeader("NEW COMMAND", "Start from a template · you can edit the file afterwards") + m.slashPick.render(true)
+	}
+	return viewHeader("NEW COMMAND", "Where to save it · personal commands work in every project") + m.slashPick.render(true)
+}
+
+func (m Model) viewSlashInput() string {
+	if m.slashRenaming {
+		c := m.slashList[m.slashCursor]
+		return viewHeader("RENAME", "New name for /"+c.Name+" · the file keeps its folder and extension") +
+			theme.PromptStr() + m.textInput.View()
+	}
+	home, cwd := homeAndCwd()
+	where := filepath.Join(slash.Dir(m.slashScope, home, cwd), "<name>"+m.slashTemplate.Ext)
+	return viewHeader("NAME", "Letters, digits, - and _ · saved as "+where) +
+		theme.PromptStr() + m.textInput.View()
+}
+
+func (m Model) viewSlashConfirm() string {
+	c := m.slashList[m.slashCursor]
+	return viewHeader("DELETE", "Delete /"+c.Name+"?") +
+		theme.Muted.Render("  file  ") + theme.Primary.Render(c.Path) + "\n\n" +
+		m.confirmButtons()
+}
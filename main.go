@@ -1,14 +1,29 @@
 package main
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"strings"
 
+	"github.com/kaan-escober/wrench/internal/cli"
 	"github.com/kaan-escober/wrench/internal/ui"
 )
 
 func main() {
-	if err := ui.Run(); err != nil {
+	args := os.Args[1:]
+	if len(args) > 0 && (!strings.HasPrefix(args[0], "-") || args[0] == "-h" || args[0] == "--help") {
+		if err := cli.Run(args); err != nil {
+			var exit *cli.ExitError
+			if errors.As(err, &exit) {
+				os.Exit(exit.Code)
+			}
+			fmt.Fprintln(os.Stderr, "error:", err)
+			os.Exit(1)
+		}
+		return
+	}
+	if err := ui.Run(args); err != nil {
 		fmt.Fprintln(os.Stderr, "error:", err)
 		os.Exit(1)
 	}